@@ -0,0 +1,358 @@
+// Package client is a Go client for the string analysis API. Calls take a
+// context and are retried on network errors and on 429, 502, 503 and 504
+// responses, honoring Retry-After.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+)
+
+const (
+	// apiVersion prefixes every path the client calls.
+	apiVersion        = "/v1"
+	defaultMaxRetries = 3
+	defaultRetryWait  = 200 * time.Millisecond
+	maxRetryWait      = 10 * time.Second
+)
+
+var (
+	// ErrNotFound matches an Error for a string that does not exist.
+	ErrNotFound = errors.New("string does not exist in the system")
+	// ErrExists matches an Error for a string that is already stored.
+	ErrExists = errors.New("string already exists in the system")
+)
+
+// Properties are the analyzed properties of a string.
+type Properties = analysis.Properties
+
+// String is a stored string as the API returns it.
+type String struct {
+	ID         string     `json:"id"`
+	Value      string     `json:"value"`
+	Properties Properties `json:"properties"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	Version    int64      `json:"version"`
+	Collection string     `json:"collection,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	Tenant     string     `json:"tenant,omitempty"`
+}
+
+// CreateRequest is the body of Create. Only Value is required.
+type CreateRequest struct {
+	Value      string   `json:"value"`
+	Collection string   `json:"collection,omitempty"`
+	Language   string   `json:"language,omitempty"`
+	TTLSeconds *int     `json:"ttl_seconds,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// Filter selects the strings List returns. Nil and empty fields are left
+// out; Params carries any other listing parameter, such as script or
+// min_stopword_ratio.
+type Filter struct {
+	IsPalindrome      *bool
+	MinLength         *int
+	MaxLength         *int
+	WordCount         *int
+	ContainsCharacter string
+	Collection        string
+	Tags              []string
+	SortBy            string
+	Order             string
+	// Limit, when positive, pages the listing; pass the NextCursor of a
+	// page as Cursor to fetch the one after it.
+	Limit  int
+	Cursor string
+	Params url.Values
+}
+
+func (f Filter) query() url.Values {
+	q := url.Values{}
+	for k, vs := range f.Params {
+		q[k] = append([]string(nil), vs...)
+	}
+	if f.IsPalindrome != nil {
+		q.Set("is_palindrome", strconv.FormatBool(*f.IsPalindrome))
+	}
+	for name, v := range map[string]*int{"min_length": f.MinLength, "max_length": f.MaxLength, "word_count": f.WordCount} {
+		if v != nil {
+			q.Set(name, strconv.Itoa(*v))
+		}
+	}
+	for name, v := range map[string]string{
+		"contains_character": f.ContainsCharacter,
+		"collection":         f.Collection,
+		"tag":                strings.Join(f.Tags, ","),
+		"sort_by":            f.SortBy,
+		"order":              f.Order,
+		"cursor":             f.Cursor,
+	} {
+		if v != "" {
+			q.Set(name, v)
+		}
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	return q
+}
+
+// Listing is a page of List results. Total and NextCursor are only set for
+// paged listings; NextCursor is empty on the last page.
+type Listing struct {
+	Data           []String               `json:"data"`
+	Count          int                    `json:"count"`
+	Total          int                    `json:"total,omitempty"`
+	NextCursor     string                 `json:"next_cursor,omitempty"`
+	FiltersApplied map[string]interface{} `json:"filters_applied"`
+}
+
+// NaturalLanguageResult is the answer to a NaturalLanguageQuery, with the
+// filters the query was read as.
+type NaturalLanguageResult struct {
+	Data             []String `json:"data"`
+	Count            int      `json:"count"`
+	InterpretedQuery struct {
+		Original      string                 `json:"original"`
+		ParsedFilters map[string]interface{} `json:"parsed_filters"`
+	} `json:"interpreted_query"`
+}
+
+// Error is a response with a non-2xx status. Code is the stable error code
+// of the API, such as STRING_EXISTS or INVALID_FILTER, and Details its
+// specifics, such as the invalid parameters; both are empty when the body
+// was not an API error.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    json.RawMessage
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if e.Code == "" {
+		return fmt.Sprintf("api: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is lets errors.Is match ErrNotFound and ErrExists by error code, or by
+// status code when the response carried none.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		if e.Code != "" {
+			return e.Code == "STRING_NOT_FOUND"
+		}
+		return e.StatusCode == http.StatusNotFound
+	case ErrExists:
+		if e.Code != "" {
+			return e.Code == "STRING_EXISTS"
+		}
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// Client calls the API at BaseURL. Set APIKey or Token to authenticate,
+// and Tenant to act for another tenant with an admin key. The zero values
+// of the other fields select the defaults; a negative MaxRetries disables
+// retries.
+type Client struct {
+	BaseURL    string
+	APIKey     string
+	Token      string
+	Tenant     string
+	HTTPClient *http.Client
+	MaxRetries int
+	RetryWait  time.Duration
+}
+
+// New returns a client for the API at baseURL, such as
+// "http://localhost:8080".
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// CreateString stores and analyzes value.
+func (c *Client) CreateString(ctx context.Context, value string) (String, error) {
+	return c.Create(ctx, CreateRequest{Value: value})
+}
+
+// Create stores and analyzes req.Value. The request carries an
+// Idempotency-Key, so a retry after a lost response does not fail with
+// ErrExists.
+func (c *Client) Create(ctx context.Context, req CreateRequest) (String, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return String{}, err
+	}
+	var out String
+	err = c.do(ctx, http.MethodPost, "/strings", nil, body, &out)
+	return out, err
+}
+
+// GetByValue returns the stored string equal to value.
+func (c *Client) GetByValue(ctx context.Context, value string) (String, error) {
+	var out String
+	err := c.do(ctx, http.MethodGet, "/strings/"+url.PathEscape(value), nil, nil, &out)
+	return out, err
+}
+
+// List returns the stored strings matching f.
+func (c *Client) List(ctx context.Context, f Filter) (Listing, error) {
+	var out Listing
+	err := c.do(ctx, http.MethodGet, "/strings", f.query(), nil, &out)
+	return out, err
+}
+
+// Delete deletes the stored string equal to value. If a retried attempt
+// finds it already gone, because an earlier attempt deleted it before its
+// response was lost, Delete fails with ErrNotFound.
+func (c *Client) Delete(ctx context.Context, value string) error {
+	return c.do(ctx, http.MethodDelete, "/strings/"+url.PathEscape(value), nil, nil, nil)
+}
+
+// NaturalLanguageQuery returns the strings matching a query such as "all
+// single word palindromic strings".
+func (c *Client) NaturalLanguageQuery(ctx context.Context, query string) (NaturalLanguageResult, error) {
+	var out NaturalLanguageResult
+	err := c.do(ctx, http.MethodGet, "/strings/filter-by-natural-language", url.Values{"query": {query}}, nil, &out)
+	return out, err
+}
+
+// do sends the request, retrying it up to MaxRetries times, and decodes a
+// 2xx JSON body into out.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body []byte, out interface{}) error {
+	u := c.BaseURL + apiVersion + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var idempotencyKey string
+	if method == http.MethodPost {
+		idempotencyKey = newIdempotencyKey()
+	}
+	retries := c.MaxRetries
+	if retries == 0 {
+		retries = defaultMaxRetries
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Accept", "application/json")
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+		if c.APIKey != "" {
+			req.Header.Set("X-API-Key", c.APIKey)
+		}
+		if c.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.Token)
+		}
+		if c.Tenant != "" {
+			req.Header.Set("X-Tenant-ID", c.Tenant)
+		}
+		wait, err := c.send(req, out)
+		if err == nil || wait < 0 || attempt >= retries {
+			return err
+		}
+		if wait == 0 {
+			wait = c.backoff(attempt)
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// send makes one attempt. wait is negative when the error is final, and
+// otherwise the delay the server asked for, if any.
+func (c *Client) send(req *http.Request, out interface{}) (wait time.Duration, err error) {
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		if req.Context().Err() != nil {
+			return -1, err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if out == nil || resp.StatusCode == http.StatusNoContent {
+			return 0, nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return -1, fmt.Errorf("api: decoding response: %w", err)
+		}
+		return 0, nil
+	}
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	var eb struct {
+		Error struct {
+			Code      string          `json:"code"`
+			Message   string          `json:"message"`
+			Details   json.RawMessage `json:"details"`
+			RequestID string          `json:"request_id"`
+		} `json:"error"`
+	}
+	if data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20)); json.Unmarshal(data, &eb) == nil {
+		e := eb.Error
+		apiErr.Code, apiErr.Message, apiErr.Details, apiErr.RequestID = e.Code, e.Message, e.Details, e.RequestID
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s >= 0 {
+			return min(time.Duration(s)*time.Second, maxRetryWait), apiErr
+		}
+		return 0, apiErr
+	}
+	return -1, apiErr
+}
+
+// backoff doubles RetryWait per attempt, up to maxRetryWait.
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.RetryWait
+	if wait <= 0 {
+		wait = defaultRetryWait
+	}
+	return min(wait<<attempt, maxRetryWait)
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
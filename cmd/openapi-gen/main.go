@@ -0,0 +1,25 @@
+// Command openapi-gen writes the OpenAPI document of the API, which the
+// server embeds and serves at /openapi.json.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/api"
+)
+
+func main() {
+	out := flag.String("o", "openapi.json", "output file")
+	flag.Parse()
+	doc, err := api.OpenAPISpec()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, append(doc, '\n'), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
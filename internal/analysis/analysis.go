@@ -0,0 +1,468 @@
+package analysis
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Properties struct {
+	Length                          int               `json:"length"`
+	IsPalindrome                    bool              `json:"is_palindrome"`
+	PalindromeLanguage              string            `json:"palindrome_language,omitempty"`
+	UniqueCharacters                int               `json:"unique_characters"`
+	WordCount                       int               `json:"word_count"`
+	ContentWordCount                int               `json:"content_word_count"`
+	StopwordRatio                   float64           `json:"stopword_ratio"`
+	Tokenizer                       string            `json:"tokenizer"`
+	TokenPattern                    string            `json:"token_pattern,omitempty"`
+	SHA256Hash                      string            `json:"sha256_hash"`
+	CharacterFrequencyMap           map[string]int    `json:"character_frequency_map"`
+	CaseInsensitiveUniqueCharacters int               `json:"case_insensitive_unique_characters"`
+	FrequencyMapCaseFolded          bool              `json:"frequency_map_case_folded,omitempty"`
+	LeadingWhitespace               int               `json:"leading_whitespace"`
+	TrailingWhitespace              int               `json:"trailing_whitespace"`
+	ConsecutiveSpaceRuns            int               `json:"consecutive_space_runs"`
+	TabCount                        int               `json:"tab_count"`
+	LineBreakCount                  int               `json:"line_break_count"`
+	LineCount                       int               `json:"line_count"`
+	IsMultiline                     bool              `json:"is_multiline"`
+	PunctuationCount                int               `json:"punctuation_count"`
+	LongestRunLength                int               `json:"longest_run_length"`
+	LongestRunCharacter             string            `json:"longest_run_character"`
+	MostRepeatedWord                string            `json:"most_repeated_word"`
+	MostRepeatedWordCount           int               `json:"most_repeated_word_count"`
+	HasRepeatedWords                bool              `json:"has_repeated_words"`
+	Scripts                         []string          `json:"scripts"`
+	IsMixedScript                   bool              `json:"is_mixed_script"`
+	Numbers                         []float64         `json:"numbers,omitempty"`
+	NumberSum                       float64           `json:"number_sum,omitempty"`
+	NumberCount                     int               `json:"number_count,omitempty"`
+	ContentHash                     string            `json:"content_hash,omitempty"`
+	HashAlgorithm                   string            `json:"hash_algorithm,omitempty"`
+	Analyzers                       []string          `json:"analyzers"`
+	AnalyzerStatus                  map[string]string `json:"analyzer_status,omitempty"`
+}
+
+type Options struct {
+	CaseInsensitive   bool     `json:"case_insensitive"`
+	PalindromeMode    string   `json:"palindrome_mode"`
+	Language          string   `json:"language"`
+	Normalization     []string `json:"normalization"`
+	EnabledAnalyzers  []string `json:"enabled_analyzers"`
+	HashAlgorithm     string   `json:"hash_algorithm"`
+	Tokenizer         string   `json:"tokenizer"`
+	TokenPattern      string   `json:"token_pattern,omitempty"`
+	StopwordLanguages []string `json:"stopword_languages"`
+	Stopwords         []string `json:"stopwords,omitempty"`
+}
+
+var (
+	palindromeModes   = []string{"default", "case_sensitive", "alphanumeric"}
+	normalizations    = []string{"trim", "lowercase", "collapse_whitespace"}
+	optionalAnalyzers = []string{"whitespace", "repetition", "scripts", "numbers", "stopwords"}
+	hashAlgorithms    = map[string]func() hash.Hash{
+		"sha256": sha256.New,
+		"sha512": sha512.New,
+		"sha1":   sha1.New,
+		"md5":    md5.New,
+	}
+)
+
+// normalize fills in defaults and rejects unknown settings. A nil
+// EnabledAnalyzers enables every optional analyzer.
+func (o Options) Normalize() (Options, error) {
+	if o.PalindromeMode == "" {
+		o.PalindromeMode = "default"
+	}
+	if !slices.Contains(palindromeModes, o.PalindromeMode) {
+		return o, fmt.Errorf("invalid palindrome_mode %q", o.PalindromeMode)
+	}
+	if o.Language == "" {
+		o.Language = "auto"
+	}
+	if !slices.Contains(PalindromeLanguages, o.Language) {
+		return o, fmt.Errorf("invalid language %q", o.Language)
+	}
+	for _, n := range o.Normalization {
+		if !slices.Contains(normalizations, n) {
+			return o, fmt.Errorf("invalid normalization %q", n)
+		}
+	}
+	if o.EnabledAnalyzers == nil {
+		o.EnabledAnalyzers = append([]string(nil), optionalAnalyzers...)
+	}
+	for _, a := range o.EnabledAnalyzers {
+		if !slices.Contains(optionalAnalyzers, a) {
+			return o, fmt.Errorf("invalid analyzer %q", a)
+		}
+	}
+	if o.HashAlgorithm == "" {
+		o.HashAlgorithm = "sha256"
+	}
+	if _, ok := hashAlgorithms[o.HashAlgorithm]; !ok {
+		return o, fmt.Errorf("invalid hash_algorithm %q", o.HashAlgorithm)
+	}
+	if o.Tokenizer == "" {
+		o.Tokenizer = "whitespace"
+	}
+	if !slices.Contains(tokenizers, o.Tokenizer) {
+		return o, fmt.Errorf("invalid tokenizer %q", o.Tokenizer)
+	}
+	if o.Tokenizer != "regex" {
+		o.TokenPattern = ""
+	} else if o.TokenPattern == "" {
+		return o, errors.New("token_pattern is required for the regex tokenizer")
+	} else if _, err := compileTokenPattern(o.TokenPattern); err != nil {
+		return o, fmt.Errorf("invalid token_pattern: %v", err)
+	}
+	if o.StopwordLanguages == nil {
+		o.StopwordLanguages = []string{"en"}
+	}
+	if err := ValidateStopwordLanguages(o.StopwordLanguages); err != nil {
+		return o, err
+	}
+	for i, w := range o.Stopwords {
+		o.Stopwords[i] = strings.ToLower(w)
+	}
+	return o, nil
+}
+
+func applyNormalization(s string, steps []string) string {
+	for _, step := range steps {
+		switch step {
+		case "trim":
+			s = strings.TrimSpace(s)
+		case "lowercase":
+			s = strings.ToLower(s)
+		case "collapse_whitespace":
+			s = strings.Join(strings.Fields(s), " ")
+		}
+	}
+	return s
+}
+
+func Hash(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func isPalindrome(s string) bool {
+	return isPalindromeMode(s, "default")
+}
+
+// isPalindromeMode compares runes from both ends of s without building a
+// rune slice or lowercased copy.
+func isPalindromeMode(s, mode string) bool {
+	i, j := 0, len(s)
+	for {
+		var l, r rune
+		var ls, rs int
+		for i < j {
+			l, ls = utf8.DecodeRuneInString(s[i:])
+			if mode != "alphanumeric" || unicode.IsLetter(l) || unicode.IsDigit(l) {
+				break
+			}
+			i += ls
+		}
+		for i < j {
+			r, rs = utf8.DecodeLastRuneInString(s[:j])
+			if mode != "alphanumeric" || unicode.IsLetter(r) || unicode.IsDigit(r) {
+				break
+			}
+			j -= rs
+		}
+		if i >= j || i+ls >= j {
+			return true
+		}
+		if mode != "case_sensitive" {
+			l, r = unicode.ToLower(l), unicode.ToLower(r)
+		}
+		if l != r {
+			return false
+		}
+		i += ls
+		j -= rs
+	}
+}
+
+// NormalizedWords splits s on whitespace and lowercases each word with
+// surrounding punctuation stripped, dropping words that were only punctuation.
+func NormalizedWords(s string) []string {
+	fields := strings.Fields(s)
+	words := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if w := strings.ToLower(strings.TrimFunc(f, unicode.IsPunct)); w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// mostRepeatedWord compares words case-insensitively with surrounding
+// punctuation stripped. Ties go to the alphabetically first word; a word
+// only counts as repeated when it occurs at least twice.
+func mostRepeatedWord(s string) (string, int) {
+	counts := map[string]int{}
+	for _, w := range NormalizedWords(s) {
+		counts[w]++
+	}
+	best, bestCount := "", 1
+	for w, n := range counts {
+		if n > bestCount || (n == bestCount && n > 1 && w < best) {
+			best, bestCount = w, n
+		}
+	}
+	if best == "" {
+		return "", 0
+	}
+	return best, bestCount
+}
+
+var commonScripts = []string{"Latin", "Cyrillic", "Greek", "Arabic", "Hebrew", "Han", "Hiragana", "Katakana", "Hangul", "Devanagari", "Thai"}
+
+func scriptOf(r rune) string {
+	for _, name := range commonScripts {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+	for name, table := range unicode.Scripts {
+		if name == "Common" || name == "Inherited" {
+			continue
+		}
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+	return ""
+}
+
+// detectScripts reports the Unicode scripts of the letters in s, ignoring
+// digits, punctuation and other characters shared between scripts.
+func detectScripts(s string) []string {
+	seen := map[string]bool{}
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if name := scriptOf(r); name != "" {
+			seen[name] = true
+		}
+	}
+	scripts := make([]string, 0, len(seen))
+	for name := range seen {
+		scripts = append(scripts, name)
+	}
+	sort.Strings(scripts)
+	return scripts
+}
+
+var thousandsPattern = regexp.MustCompile(`^\d{1,3}(?:,\d{3})+(?:[.eE]|$)`)
+
+var numberPattern = regexp.MustCompile(`\d+(?:,\d+)*(?:\.\d+)?(?:[eE][+-]?\d+)?`)
+
+// extractNumbers reads decimals with an optional exponent ("1e3") and
+// thousands separators ("1,000"). Commas that do not group thousands, as in
+// "1000,000" or "1,0000", separate numbers instead. A '-' directly before a
+// number is a sign unless it joins two words or numbers (as in "2-3" or
+// "covid-19").
+func extractNumbers(s string) []float64 {
+	var nums []float64
+	for _, loc := range numberPattern.FindAllStringIndex(s, -1) {
+		start := loc[0]
+		negative := start > 0 && s[start-1] == '-' && (start < 2 || !isWordByte(s[start-2]))
+		parts := []string{s[start:loc[1]]}
+		if strings.Contains(parts[0], ",") {
+			if thousandsPattern.MatchString(parts[0]) {
+				parts[0] = strings.ReplaceAll(parts[0], ",", "")
+			} else {
+				parts = strings.Split(parts[0], ",")
+			}
+		}
+		for i, part := range parts {
+			n, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				continue
+			}
+			if i == 0 && negative {
+				n = -n
+			}
+			nums = append(nums, n)
+		}
+	}
+	return nums
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func DefaultOptions() Options {
+	opts, _ := Options{}.Normalize()
+	return opts
+}
+
+// Analyze derives every property except sha256_hash from the
+// normalized text; the hash always covers the original value because it
+// doubles as the record ID. opts must already be normalized.
+func Analyze(s string, opts Options) Properties {
+	text := applyNormalization(s, opts.Normalization)
+	sa := newStreamAnalyzer()
+	_, _ = sa.WriteString(text)
+	props := Properties{SHA256Hash: hashString(sha256.New(), s)}
+	sa.apply(&props, opts)
+	analyzeWholeValue(&props, text, opts)
+	if opts.HashAlgorithm != "sha256" {
+		props.ContentHash = hashString(hashAlgorithms[opts.HashAlgorithm](), s)
+		props.HashAlgorithm = opts.HashAlgorithm
+	}
+	return props
+}
+
+// analyzeWholeValue runs the analyzers that need random access to the whole
+// text rather than a single forward pass.
+func analyzeWholeValue(props *Properties, text string, opts Options) {
+	var lang string
+	props.IsPalindrome, lang = isPalindromeLang(text, opts.PalindromeMode, opts.Language)
+	if lang != "und" {
+		props.PalindromeLanguage = lang
+	}
+	tok := tokenizerFor(opts)
+	if _, streamed := tok.(whitespaceTokenizer); !streamed {
+		props.WordCount = tok.count(text)
+	}
+	props.Tokenizer, props.TokenPattern = tok.name(), opts.TokenPattern
+	props.Analyzers = []string{"core"}
+	if slices.Contains(opts.EnabledAnalyzers, "whitespace") {
+		props.Analyzers = append(props.Analyzers, "whitespace")
+	}
+	runIsolatedAnalyzers(props, text, opts)
+}
+
+// isolatedAnalyzers compute optional properties that may fail or run long.
+// Each returns a function applying its results, so a failed or timed out
+// analyzer leaves the record's properties untouched.
+var isolatedAnalyzers = []struct {
+	name string
+	run  func(text string, opts Options) func(*Properties)
+}{
+	{"repetition", func(text string, _ Options) func(*Properties) {
+		word, count := mostRepeatedWord(text)
+		return func(p *Properties) {
+			p.MostRepeatedWord, p.MostRepeatedWordCount = word, count
+			p.HasRepeatedWords = count > 1
+		}
+	}},
+	{"scripts", func(text string, _ Options) func(*Properties) {
+		scripts := detectScripts(text)
+		return func(p *Properties) {
+			p.Scripts = scripts
+			p.IsMixedScript = len(scripts) > 1
+		}
+	}},
+	{"numbers", func(text string, _ Options) func(*Properties) {
+		nums := extractNumbers(text)
+		return func(p *Properties) {
+			p.Numbers = nums
+			p.NumberCount = len(nums)
+			for _, n := range nums {
+				p.NumberSum += n
+			}
+		}
+	}},
+	{"stopwords", func(text string, opts Options) func(*Properties) {
+		content, ratio := stopwordStats(text, opts)
+		return func(p *Properties) { p.ContentWordCount, p.StopwordRatio = content, ratio }
+	}},
+}
+
+const DefaultTimeout = time.Second
+
+// Timeout bounds the isolated analyzers of one value; zero disables
+// the limit.
+var Timeout = DefaultTimeout
+
+type analyzerResult struct {
+	apply func(*Properties)
+	err   error
+}
+
+func runAnalyzer(run func(string, Options) func(*Properties), text string, opts Options) (result analyzerResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = analyzerResult{err: fmt.Errorf("%v", r)}
+		}
+	}()
+	return analyzerResult{apply: run(text, opts)}
+}
+
+// runIsolatedAnalyzers runs the enabled isolated analyzers concurrently. One
+// that panics or misses the deadline is recorded in AnalyzerStatus instead of
+// failing the whole analysis; re-analyzing the record fills it in later.
+func runIsolatedAnalyzers(props *Properties, text string, opts Options) {
+	var deadline <-chan time.Time
+	if Timeout > 0 {
+		timer := time.NewTimer(Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	results := map[string]chan analyzerResult{}
+	for _, a := range isolatedAnalyzers {
+		if !slices.Contains(opts.EnabledAnalyzers, a.name) {
+			continue
+		}
+		ch := make(chan analyzerResult, 1)
+		results[a.name] = ch
+		go func(run func(string, Options) func(*Properties)) { ch <- runAnalyzer(run, text, opts) }(a.run)
+	}
+	timedOut := false
+	for _, a := range isolatedAnalyzers {
+		ch, ok := results[a.name]
+		if !ok {
+			continue
+		}
+		var res analyzerResult
+		if timedOut {
+			select {
+			case res = <-ch:
+			default:
+			}
+		} else {
+			select {
+			case res = <-ch:
+			case <-deadline:
+				timedOut = true
+			}
+		}
+		switch {
+		case res.err != nil:
+			setAnalyzerStatus(props, a.name, "error: "+res.err.Error())
+		case res.apply == nil:
+			setAnalyzerStatus(props, a.name, "timeout")
+		default:
+			res.apply(props)
+			props.Analyzers = append(props.Analyzers, a.name)
+		}
+	}
+}
+
+func setAnalyzerStatus(props *Properties, name, status string) {
+	if props.AnalyzerStatus == nil {
+		props.AnalyzerStatus = map[string]string{}
+	}
+	props.AnalyzerStatus[name] = status
+}
@@ -0,0 +1,35 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExtractNumbers(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want string
+	}{
+		{"no numbers", "[]"},
+		{"12 apples and 3.5 pears", "[12 3.5]"},
+		{"-4 degrees", "[-4]"},
+		{"pages 2-3", "[2 3]"},
+		{"covid-19", "[19]"},
+		{"1,000", "[1000]"},
+		{"1,234,567.89 total", "[1.23456789e+06]"},
+		{"-1,000", "[-1000]"},
+		{"1,2,3", "[1 2 3]"},
+		{"10,20", "[10 20]"},
+		{"1000,000", "[1000 0]"},
+		{"1,0000", "[1 0]"},
+		{"1e3", "[1000]"},
+		{"2.5E-2", "[0.025]"},
+		{"6e+2 and 1,000e3", "[600 1e+06]"},
+		{"3em", "[3]"},
+		{"1e999", "[]"},
+	} {
+		if got := fmt.Sprint(extractNumbers(tt.in)); got != tt.want {
+			t.Errorf("extractNumbers(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
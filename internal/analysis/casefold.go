@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// PalindromeLanguages select the case-folding rules used by palindrome
+// detection. "auto" picks one from the text, "und" is plain Unicode
+// lowercasing.
+var PalindromeLanguages = []string{"auto", "und", "tr", "az", "de"}
+
+// detectFoldLanguage guesses the folding rules for text from letters that
+// only occur in languages whose casing differs from the Unicode default.
+func detectFoldLanguage(text string) string {
+	for _, r := range text {
+		switch r {
+		case 'ı', 'İ', 'ğ', 'Ğ', 'ş', 'Ş':
+			return "tr"
+		case 'ə', 'Ə':
+			return "az"
+		case 'ß', 'ẞ':
+			return "de"
+		}
+	}
+	return "und"
+}
+
+// appendFolded lowercases r under lang's rules: Turkish and Azerbaijani
+// pair I with dotless ı and İ with i, and German folds ß to ss.
+func appendFolded(dst []rune, r rune, lang string) []rune {
+	switch lang {
+	case "tr", "az":
+		return append(dst, unicode.TurkishCase.ToLower(r))
+	case "de":
+		if r == 'ß' || r == 'ẞ' {
+			return append(dst, 's', 's')
+		}
+	}
+	return append(dst, unicode.ToLower(r))
+}
+
+// isPalindromeLang is isPalindromeMode with language-specific folding. It
+// returns the language whose rules were applied.
+func isPalindromeLang(s, mode, lang string) (bool, string) {
+	if lang == "" || lang == "auto" {
+		lang = detectFoldLanguage(s)
+	}
+	if lang == "und" || mode == "case_sensitive" {
+		return isPalindromeMode(s, mode), lang
+	}
+	folded := make([]rune, 0, utf8.RuneCountInString(s))
+	for _, r := range s {
+		if mode == "alphanumeric" && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		folded = appendFolded(folded, r, lang)
+	}
+	for i, j := 0, len(folded)-1; i < j; i, j = i+1, j-1 {
+		if folded[i] != folded[j] {
+			return false, lang
+		}
+	}
+	return true, lang
+}
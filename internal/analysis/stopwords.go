@@ -0,0 +1,82 @@
+package analysis
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var stopwordLists = map[string]map[string]bool{}
+
+func init() {
+	for lang, words := range map[string]string{
+		"en": `a about above after again against all am an and any are as at be because been before being
+			below between both but by can could did do does doing down during each few for from further had has
+			have having he her here hers herself him himself his how i if in into is it its itself just me more
+			most my myself no nor not now of off on once only or other our ours ourselves out over own same she
+			should so some such than that the their theirs them themselves then there these they this those
+			through to too under until up very was we were what when where which while who whom why will with
+			would you your yours yourself yourselves`,
+		"es": `a al algo algunas algunos ante antes como con contra cual cuando de del desde donde durante e el
+			ella ellas ellos en entre era es esa esas ese eso esos esta estaba estas este esto estos fue ha hay la
+			las le les lo los mas me mi mis mucho muy nada ni no nos nosotros o os otra otro para pero poco por
+			porque que quien se sea ser si sin sobre su sus también te tiene todo tu tus un una uno unos y ya yo`,
+		"fr": `à au aux avec ce ces cette dans de des du elle en et eux il ils je la le les leur lui ma mais me
+			même mes moi mon ne nos notre nous on ou où par pas pour qu que qui sa se ses son sur ta te tes toi
+			ton tu un une vos votre vous y été être avoir est sont était`,
+		"de": `aber alle als also am an auch auf aus bei bin bis bist da damit dann das dass dein dem den der des
+			dich die dir doch dort du durch ein eine einem einen einer es für hat hatte ich ihm ihn ihr im in ist
+			ja jetzt kann kein mich mir mit nach nicht noch nun nur ob oder ohne sehr sein sich sie sind so über
+			um und uns unter vom von vor war was weil wenn wer wie wir wird wo zu zum zur`,
+	} {
+		set := map[string]bool{}
+		for _, w := range strings.Fields(words) {
+			set[w] = true
+		}
+		stopwordLists[lang] = set
+	}
+}
+
+func stopwordLanguages() []string {
+	langs := make([]string, 0, len(stopwordLists))
+	for lang := range stopwordLists {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+func ValidateStopwordLanguages(langs []string) error {
+	for _, lang := range langs {
+		if stopwordLists[lang] == nil {
+			return fmt.Errorf("invalid stopword language %q, expected one of %s", lang, strings.Join(stopwordLanguages(), ", "))
+		}
+	}
+	return nil
+}
+
+func IsStopword(word string, langs, extra []string) bool {
+	for _, lang := range langs {
+		if stopwordLists[lang][word] {
+			return true
+		}
+	}
+	return slices.Contains(extra, word)
+}
+
+// stopwordStats counts the normalized words of text that are not stopwords
+// and the share of words that are.
+func stopwordStats(text string, opts Options) (int, float64) {
+	words := NormalizedWords(text)
+	if len(words) == 0 {
+		return 0, 0
+	}
+	content := 0
+	for _, w := range words {
+		if !IsStopword(w, opts.StopwordLanguages, opts.Stopwords) {
+			content++
+		}
+	}
+	return content, float64(len(words)-content) / float64(len(words))
+}
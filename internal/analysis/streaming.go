@@ -0,0 +1,224 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const hashChunkSize = 32 * 1024
+
+// streamAnalyzer computes the single-pass properties of a value rune by rune
+// so that no rune slice or lowercased copy of the value is ever built.
+type streamAnalyzer struct {
+	pending []byte
+
+	length int
+	freq   map[rune]int
+	folded map[rune]int
+
+	seenNonSpace  bool
+	inWord        bool
+	words         int
+	leading       int
+	trailingRun   int
+	spaceRun      int
+	spaceRuns     int
+	tabs          int
+	lineBreaks    int
+	prevCR        bool
+	punctuation   int
+	prev          rune
+	run           int
+	bestRun       int
+	bestRunRune   rune
+	assignedFirst bool
+}
+
+func newStreamAnalyzer() *streamAnalyzer {
+	return &streamAnalyzer{freq: map[rune]int{}, folded: map[rune]int{}}
+}
+
+func (a *streamAnalyzer) rune(r rune) {
+	a.length++
+	a.freq[r]++
+	a.folded[unicode.ToLower(r)]++
+
+	space := unicode.IsSpace(r)
+	if space {
+		if !a.seenNonSpace {
+			a.leading++
+		}
+		a.trailingRun++
+		a.inWord = false
+	} else {
+		a.seenNonSpace = true
+		a.trailingRun = 0
+		if !a.inWord {
+			a.words++
+			a.inWord = true
+		}
+	}
+
+	if r == ' ' {
+		a.spaceRun++
+		if a.spaceRun == 2 {
+			a.spaceRuns++
+		}
+	} else {
+		a.spaceRun = 0
+	}
+	switch {
+	case r == '\t':
+		a.tabs++
+	case r == '\n':
+		if !a.prevCR {
+			a.lineBreaks++
+		}
+	case r == '\r':
+		a.lineBreaks++
+	case unicode.IsPunct(r):
+		a.punctuation++
+	}
+	a.prevCR = r == '\r'
+
+	if a.assignedFirst && r == a.prev {
+		a.run++
+	} else {
+		a.run = 1
+	}
+	if a.run > a.bestRun {
+		a.bestRun, a.bestRunRune = a.run, r
+	}
+	a.prev = r
+	a.assignedFirst = true
+}
+
+func (a *streamAnalyzer) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(a.pending) > 0 {
+		p = append(a.pending, p...)
+		a.pending = nil
+	}
+	for len(p) > 0 {
+		if !utf8.FullRune(p) {
+			a.pending = append([]byte(nil), p...)
+			break
+		}
+		r, size := utf8.DecodeRune(p)
+		a.rune(r)
+		p = p[size:]
+	}
+	return n, nil
+}
+
+func (a *streamAnalyzer) WriteString(s string) (int, error) {
+	for _, r := range s {
+		a.rune(r)
+	}
+	return len(s), nil
+}
+
+// finish flushes a trailing incomplete UTF-8 sequence as replacement runes.
+func (a *streamAnalyzer) finish() {
+	for range a.pending {
+		a.rune(utf8.RuneError)
+	}
+	a.pending = nil
+}
+
+func runeCounts(m map[rune]int) map[string]int {
+	out := make(map[string]int, len(m))
+	for r, n := range m {
+		out[string(r)] = n
+	}
+	return out
+}
+
+// apply copies the streamed statistics into props, honoring the enabled
+// analyzer groups. The word count is the whitespace tokenizer's; other
+// tokenizers replace it in analyzeWholeValue.
+func (a *streamAnalyzer) apply(props *Properties, opts Options) {
+	props.Length = a.length
+	props.WordCount = a.words
+	props.UniqueCharacters = len(a.freq)
+	props.CaseInsensitiveUniqueCharacters = len(a.folded)
+	if opts.CaseInsensitive {
+		props.CharacterFrequencyMap = runeCounts(a.folded)
+		props.FrequencyMapCaseFolded = true
+	} else {
+		props.CharacterFrequencyMap = runeCounts(a.freq)
+	}
+	if slices.Contains(opts.EnabledAnalyzers, "whitespace") {
+		props.LeadingWhitespace = a.leading
+		if a.seenNonSpace {
+			props.TrailingWhitespace = a.trailingRun
+		}
+		props.ConsecutiveSpaceRuns = a.spaceRuns
+		props.TabCount = a.tabs
+		props.LineBreakCount = a.lineBreaks
+		if a.length > 0 {
+			props.LineCount = a.lineBreaks + 1
+		}
+		props.IsMultiline = props.LineCount > 1
+		props.PunctuationCount = a.punctuation
+	}
+	if slices.Contains(opts.EnabledAnalyzers, "repetition") && a.bestRun > 0 {
+		props.LongestRunCharacter, props.LongestRunLength = string(a.bestRunRune), a.bestRun
+	}
+}
+
+// hashString feeds s to h in fixed-size chunks to avoid converting the whole
+// value to a byte slice at once.
+func hashString(h hash.Hash, s string) string {
+	buf := make([]byte, hashChunkSize)
+	for off := 0; off < len(s); off += hashChunkSize {
+		n := copy(buf, s[off:])
+		h.Write(buf[:n])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AnalyzeReader analyzes a value read from r in a single pass: hashing and
+// the streamed statistics are computed as the bytes arrive, and the value is
+// accumulated only once so the remaining analyzers and the store can use it.
+// Normalization needs the whole value up front, so it falls back to
+// Analyze.
+func AnalyzeReader(r io.Reader, opts Options) (string, Properties, error) {
+	if len(opts.Normalization) > 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return "", Properties{}, err
+		}
+		s := string(data)
+		return s, Analyze(s, opts), nil
+	}
+	sum := sha256.New()
+	writers := []io.Writer{sum}
+	var extra hash.Hash
+	if opts.HashAlgorithm != "sha256" {
+		extra = hashAlgorithms[opts.HashAlgorithm]()
+		writers = append(writers, extra)
+	}
+	var value strings.Builder
+	sa := newStreamAnalyzer()
+	writers = append(writers, &value, sa)
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return "", Properties{}, err
+	}
+	sa.finish()
+	s := value.String()
+	props := Properties{SHA256Hash: hex.EncodeToString(sum.Sum(nil))}
+	sa.apply(&props, opts)
+	analyzeWholeValue(&props, s, opts)
+	if extra != nil {
+		props.ContentHash = hex.EncodeToString(extra.Sum(nil))
+		props.HashAlgorithm = opts.HashAlgorithm
+	}
+	return s, props, nil
+}
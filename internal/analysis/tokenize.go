@@ -0,0 +1,102 @@
+package analysis
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+)
+
+var tokenizers = []string{"whitespace", "uax29", "regex"}
+
+// tokenizer splits text into the words counted by word_count.
+type tokenizer interface {
+	name() string
+	count(s string) int
+}
+
+type whitespaceTokenizer struct{}
+
+func (whitespaceTokenizer) name() string { return "whitespace" }
+
+func (whitespaceTokenizer) count(s string) int {
+	n, inWord := 0, false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			inWord = false
+		} else if !inWord {
+			n++
+			inWord = true
+		}
+	}
+	return n
+}
+
+// uax29Tokenizer counts the word segments of Unicode Standard Annex #29 that
+// contain a letter or digit, so punctuation and spaces between words are not
+// counted and "don't" is one word while "foo-bar" is two.
+type uax29Tokenizer struct{}
+
+func (uax29Tokenizer) name() string { return "uax29" }
+
+func (uax29Tokenizer) count(s string) int {
+	n, state := 0, -1
+	var word string
+	for len(s) > 0 {
+		word, s, state = uniseg.FirstWordInString(s, state)
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// regexTokenizer counts the non-overlapping matches of a configured pattern.
+type regexTokenizer struct {
+	re *regexp.Regexp
+}
+
+func (regexTokenizer) name() string { return "regex" }
+
+func (t regexTokenizer) count(s string) int {
+	return len(t.re.FindAllStringIndex(s, -1))
+}
+
+var tokenPatterns = struct {
+	sync.Mutex
+	m map[string]*regexp.Regexp
+}{m: map[string]*regexp.Regexp{}}
+
+func compileTokenPattern(pattern string) (*regexp.Regexp, error) {
+	tokenPatterns.Lock()
+	defer tokenPatterns.Unlock()
+	if re, ok := tokenPatterns.m[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if re.MatchString("") {
+		return nil, errors.New("pattern must not match the empty string")
+	}
+	tokenPatterns.m[pattern] = re
+	return re, nil
+}
+
+// tokenizerFor returns the tokenizer selected by normalized options.
+func tokenizerFor(opts Options) tokenizer {
+	switch opts.Tokenizer {
+	case "uax29":
+		return uax29Tokenizer{}
+	case "regex":
+		re, err := compileTokenPattern(opts.TokenPattern)
+		if err == nil {
+			return regexTokenizer{re: re}
+		}
+	}
+	return whitespaceTokenizer{}
+}
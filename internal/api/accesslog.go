@@ -0,0 +1,57 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// accessLogResponse counts what was sent, compressed bytes included.
+type accessLogResponse struct {
+	http.ResponseWriter
+	code  int
+	bytes int64
+}
+
+func (a *accessLogResponse) WriteHeader(code int) {
+	if a.code == 0 {
+		a.code = code
+	}
+	a.ResponseWriter.WriteHeader(code)
+}
+
+func (a *accessLogResponse) Write(p []byte) (int, error) {
+	if a.code == 0 {
+		a.code = http.StatusOK
+	}
+	n, err := a.ResponseWriter.Write(p)
+	a.bytes += int64(n)
+	return n, err
+}
+
+// withAccessLog logs one line per request once it has been answered, at
+// ERROR level for 5xx responses. It runs outermost, so the status and size
+// are those the client received and the latency covers every middleware.
+func withAccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		aw := &accessLogResponse{ResponseWriter: w}
+		next.ServeHTTP(aw, r)
+		if aw.code == 0 {
+			aw.code = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if aw.code >= 500 {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(r.Context(), level, "request",
+			slog.String("request_id", w.Header().Get(requestIDHeader)),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", aw.code),
+			slog.Int64("bytes", aw.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("tenant", tenantOf(r)),
+			slog.String("client_ip", clientIP(r)))
+	})
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// currentAPIVersion is the version paths without one are redirected to.
+const currentAPIVersion = "v1"
+
+// apiVersions maps each API version served side by side to its handler.
+// A new version registers its own chain here; handlers shared between
+// versions can branch on apiVersionOf.
+var apiVersions = map[string]http.Handler{}
+
+var apiVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+type apiVersionKey struct{}
+
+// apiVersionOf returns the API version r was made against.
+func apiVersionOf(r *http.Request) string {
+	if v, ok := r.Context().Value(apiVersionKey{}).(string); ok {
+		return v
+	}
+	return currentAPIVersion
+}
+
+// versionedPath returns path under the API version of r, for the links and
+// Location headers of responses.
+func versionedPath(r *http.Request, path string) string {
+	return "/" + apiVersionOf(r) + path
+}
+
+// withAPIVersions serves /{version}/... with the handler of that version,
+// stripping the prefix so routes and middleware see the paths they always
+// have; RequestURI keeps the path as sent, for request signatures and the
+// audit log. Paths without a version are permanently redirected to the
+// current version, keeping their method and body, and unknown versions are
+// 404.
+func withAPIVersions() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		h, ok := apiVersions[version]
+		if !ok {
+			if apiVersionPattern.MatchString(version) {
+				writeError(w, http.StatusNotFound, "UNSUPPORTED_API_VERSION", "unsupported API version "+version+", expected one of "+strings.Join(servedAPIVersions(), ", "))
+				return
+			}
+			target := "/" + currentAPIVersion + r.URL.EscapedPath()
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusPermanentRedirect)
+			return
+		}
+		prefix := "/" + version
+		r = r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version))
+		if r.RequestURI == "" {
+			r.RequestURI = r.URL.RequestURI()
+		}
+		u := *r.URL
+		u.Path = strings.TrimPrefix(u.Path, prefix)
+		u.RawPath = strings.TrimPrefix(u.RawPath, prefix)
+		if u.Path == "" {
+			u.Path = "/"
+		}
+		r.URL = &u
+		h.ServeHTTP(w, r)
+	})
+}
+
+func servedAPIVersions() []string {
+	names := make([]string, 0, len(apiVersions))
+	for name := range apiVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	defaultAuditMaxEntries = 10000
+	defaultAuditLimit      = 100
+	maxAuditLimit          = 1000
+)
+
+// auditEntry records one mutating request for compliance review. Actor is
+// who made the request: the id of its API key, or the tenant when
+// authentication is off.
+type auditEntry struct {
+	Cursor     int64           `json:"cursor"`
+	At         store.Timestamp `json:"at"`
+	Actor      string          `json:"actor"`
+	Tenant     string          `json:"tenant"`
+	RemoteAddr string          `json:"remote_addr"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	Operation  string          `json:"operation"`
+	Status     int             `json:"status"`
+	Outcome    string          `json:"outcome"`
+}
+
+// audit keeps the newest max entries in memory and appends every entry to
+// AUDIT_PATH when that is set. last is the latest cursor handed out, so
+// cursors are not reused once entries are dropped.
+var audit = struct {
+	sync.Mutex
+	max  int
+	last int64
+	list []auditEntry
+	f    *os.File
+}{max: defaultAuditMaxEntries}
+
+func openAuditLog(path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	err = store.ReadLogLines(f, func(n int, line []byte) error {
+		var e auditEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return err
+		}
+		audit.list = append(audit.list, e)
+		audit.last = e.Cursor
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if n := len(audit.list) - audit.max; n > 0 {
+		audit.list = audit.list[n:]
+	}
+	audit.f = f
+	return nil
+}
+
+func recordAudit(e auditEntry) {
+	audit.Lock()
+	defer audit.Unlock()
+	audit.last++
+	e.Cursor = audit.last
+	audit.list = append(audit.list, e)
+	if n := len(audit.list) - audit.max; n > 0 {
+		audit.list = append(audit.list[:0], audit.list[n:]...)
+	}
+	if audit.f == nil {
+		return
+	}
+	line, err := json.Marshal(e)
+	if err == nil {
+		_, err = audit.f.Write(append(line, '\n'))
+	}
+	if err != nil {
+		logger.Error("audit log append failed", "error", err)
+	}
+}
+
+// auditOperation names what a mutating request does to the store.
+func auditOperation(r *http.Request) string {
+	route := routeOf(r)
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/reanalyze"):
+		return "reanalyze"
+	case strings.HasSuffix(r.URL.Path, "/restore"):
+		return "restore"
+	case strings.HasSuffix(r.URL.Path, "/tags"):
+		return "tag"
+	case r.Method == http.MethodDelete:
+		return "delete"
+	case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/strings/"):
+		return "upsert"
+	case r.Method == http.MethodPost && (strings.HasSuffix(r.URL.Path, "/strings") || route == "/strings/upload" || route == "/strings/from-url"):
+		return "create"
+	}
+	return strings.ToLower(r.Method)
+}
+
+type auditActorKey struct{}
+
+// setAuditActor names who made r once authentication has identified them.
+func setAuditActor(r *http.Request, actor string) {
+	if p, ok := r.Context().Value(auditActorKey{}).(*string); ok {
+		*p = actor
+	}
+}
+
+type auditResponse struct {
+	http.ResponseWriter
+	code int
+}
+
+func (a *auditResponse) WriteHeader(code int) {
+	if a.code == 0 {
+		a.code = code
+	}
+	a.ResponseWriter.WriteHeader(code)
+}
+
+func (a *auditResponse) Write(p []byte) (int, error) {
+	if a.code == 0 {
+		a.code = http.StatusOK
+	}
+	return a.ResponseWriter.Write(p)
+}
+
+// withAudit records every request other than GET, HEAD and OPTIONS once it
+// has been answered, including those refused before reaching a handler.
+func withAudit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		at := store.Now()
+		operation := auditOperation(r)
+		actor := new(string)
+		r = r.WithContext(context.WithValue(r.Context(), auditActorKey{}, actor))
+		aw := &auditResponse{ResponseWriter: w}
+		next.ServeHTTP(aw, r)
+		if aw.code == 0 {
+			aw.code = http.StatusOK
+		}
+		outcome := "success"
+		if aw.code >= 400 {
+			outcome = "failure"
+		}
+		tenant := tenantOf(r)
+		if *actor == "" {
+			*actor = tenant
+		}
+		recordAudit(auditEntry{
+			At:         at,
+			Actor:      *actor,
+			Tenant:     tenant,
+			RemoteAddr: clientIP(r),
+			Method:     r.Method,
+			Path:       r.RequestURI,
+			Operation:  operation,
+			Status:     aw.code,
+			Outcome:    outcome,
+		})
+	})
+}
+
+var auditParams = paramSchema{
+	intParam("since", 0, math.MaxInt, 0),
+	intParam("limit", 1, maxAuditLimit, defaultAuditLimit),
+	stringParam("actor", ""),
+	stringParam("operation", ""),
+	enumParam("outcome", "", "success", "failure"),
+	timeParam("from"),
+	timeParam("to"),
+}
+
+// auditHandler pages through the tenant's audit log oldest first, resuming
+// after the since cursor like GET /events.
+func auditHandler(w http.ResponseWriter, r *http.Request) {
+	var errs paramErrors
+	p := auditParams.parse(r.URL.Query(), &errs)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	since, limit := int64(p.int("since")), p.int("limit")
+	from, to := p.time("from"), p.time("to")
+	tenant := tenantOf(r)
+	match := func(e auditEntry) bool {
+		return e.Tenant == tenant &&
+			(p.str("actor") == "" || e.Actor == p.str("actor")) &&
+			(p.str("operation") == "" || e.Operation == p.str("operation")) &&
+			(p.str("outcome") == "" || e.Outcome == p.str("outcome")) &&
+			(from == nil || !e.At.Time.Before(*from)) &&
+			(to == nil || e.At.Time.Before(*to))
+	}
+	page := []auditEntry{}
+	next := since
+	hasMore := false
+	audit.Lock()
+	start := sort.Search(len(audit.list), func(i int) bool { return audit.list[i].Cursor > since })
+	for _, e := range audit.list[start:] {
+		if !match(e) {
+			continue
+		}
+		if len(page) == limit {
+			hasMore = true
+			break
+		}
+		page = append(page, e)
+		next = e.Cursor
+	}
+	audit.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":        page,
+		"count":       len(page),
+		"next_cursor": next,
+		"has_more":    hasMore,
+	})
+}
+
+// purgeAudit drops the tenant's audit entries, whose paths can hold its
+// values, and rewrites AUDIT_PATH with the entries still held.
+func purgeAudit(tenant string) (int, error) {
+	audit.Lock()
+	defer audit.Unlock()
+	kept := audit.list[:0]
+	for _, e := range audit.list {
+		if e.Tenant != tenant {
+			kept = append(kept, e)
+		}
+	}
+	removed := len(audit.list) - len(kept)
+	audit.list = kept
+	if removed == 0 || audit.f == nil {
+		return removed, nil
+	}
+	f, err := rewriteLog(audit.f, kept)
+	if err != nil {
+		return removed, err
+	}
+	audit.f = f
+	return removed, nil
+}
@@ -0,0 +1,397 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	apiKeyHeader      = "X-API-Key"
+	adminAPIKeyID     = "admin"
+	maxRotationGrace  = 7 * 24 * 60 * 60
+	apiKeySecretBytes = 24
+)
+
+type apiKey struct {
+	ID         string           `json:"id"`
+	Name       string           `json:"name,omitempty"`
+	Tenant     string           `json:"tenant"`
+	Role       string           `json:"role"`
+	Prefix     string           `json:"prefix"`
+	CreatedAt  store.Timestamp  `json:"created_at"`
+	RotatedAt  *store.Timestamp `json:"rotated_at,omitempty"`
+	RevokedAt  *store.Timestamp `json:"revoked_at,omitempty"`
+	LastUsedAt *store.Timestamp `json:"last_used_at,omitempty"`
+}
+
+// storedKey is an API key as persisted: only hashes of its secrets are
+// kept. After a rotation the previous secret stays valid until
+// PreviousUntil. Scope is read from keys saved before roles replaced scopes.
+type storedKey struct {
+	apiKey
+	Scope         string           `json:"scope,omitempty"`
+	Hash          string           `json:"hash"`
+	PreviousHash  string           `json:"previous_hash,omitempty"`
+	PreviousUntil *store.Timestamp `json:"previous_until,omitempty"`
+}
+
+// apiKeys holds the keys by id. Authentication is enabled when
+// ADMIN_API_KEY is set or API_KEYS_PATH holds keys; adminHash is the hash of
+// ADMIN_API_KEY.
+var apiKeys = struct {
+	sync.Mutex
+	enabled   bool
+	path      string
+	adminHash string
+	m         map[string]*storedKey
+}{m: map[string]*storedKey{}}
+
+var (
+	errKeyRevoked  = errors.New("API key is revoked")
+	errKeyNotFound = errors.New("API key does not exist")
+)
+
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newAPIKeySecret() string {
+	b := make([]byte, apiKeySecretBytes)
+	_, _ = rand.Read(b)
+	return "sk_" + hex.EncodeToString(b)
+}
+
+// configureAuth reads ADMIN_API_KEY and loads the keys kept in API_KEYS_PATH.
+func configureAuth(adminKey, path string) error {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err == nil {
+			var keys []*storedKey
+			if err := json.Unmarshal(data, &keys); err != nil {
+				return errors.New(path + ": " + err.Error())
+			}
+			for _, k := range keys {
+				if k.Role == "" {
+					k.Role, k.Scope = normalizeRole(k.Scope), ""
+				}
+				apiKeys.m[k.ID] = k
+			}
+		}
+		apiKeys.path = path
+	}
+	if adminKey != "" {
+		apiKeys.adminHash = hashAPIKey(adminKey)
+	}
+	apiKeys.enabled = adminKey != "" || len(apiKeys.m) > 0
+	return nil
+}
+
+// saveAPIKeys writes the keys to API_KEYS_PATH through a temporary file, so
+// a crash mid-write never loses them. The caller holds apiKeys.
+func saveAPIKeys() error {
+	if apiKeys.path == "" {
+		return nil
+	}
+	keys := make([]*storedKey, 0, len(apiKeys.m))
+	for _, k := range apiKeys.m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	tmp := apiKeys.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, apiKeys.path)
+}
+
+// authenticate resolves secret to its key, recording when it was used.
+func authenticate(secret string) (apiKey, bool) {
+	h := hashAPIKey(secret)
+	apiKeys.Lock()
+	defer apiKeys.Unlock()
+	if apiKeys.adminHash != "" && subtle.ConstantTimeCompare([]byte(h), []byte(apiKeys.adminHash)) == 1 {
+		return apiKey{ID: adminAPIKeyID, Name: "ADMIN_API_KEY", Tenant: defaultTenant, Role: "admin"}, true
+	}
+	now := store.Now()
+	for _, k := range apiKeys.m {
+		if k.RevokedAt != nil {
+			continue
+		}
+		current := subtle.ConstantTimeCompare([]byte(h), []byte(k.Hash)) == 1
+		previous := k.PreviousHash != "" && k.PreviousUntil != nil && now.Time.Before(k.PreviousUntil.Time) &&
+			subtle.ConstantTimeCompare([]byte(h), []byte(k.PreviousHash)) == 1
+		if current || previous {
+			k.LastUsedAt = &now
+			return k.apiKey, true
+		}
+	}
+	return apiKey{}, false
+}
+
+// credentialsOf authenticates r by its X-API-Key or, when JWTs are
+// configured, its bearer token.
+func credentialsOf(r *http.Request) (apiKey, error) {
+	if secret := r.Header.Get(apiKeyHeader); secret != "" {
+		key, ok := authenticate(secret)
+		if !ok {
+			return apiKey{}, errors.New("invalid API key")
+		}
+		return key, nil
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && jwtEnabled() {
+		return authenticateJWT(strings.TrimSpace(token))
+	}
+	if jwtEnabled() {
+		return apiKey{}, errors.New("missing " + apiKeyHeader + " header or bearer token")
+	}
+	return apiKey{}, errors.New("missing " + apiKeyHeader + " header")
+}
+
+// authEnabled reports whether requests must carry an API key or bearer
+// token.
+func authEnabled() bool {
+	apiKeys.Lock()
+	defer apiKeys.Unlock()
+	return apiKeys.enabled || jwtEnabled()
+}
+
+// publicRequest reports whether r is served without credentials even when
+// authentication is enabled.
+func publicRequest(r *http.Request) bool {
+	return r.Method == http.MethodOptions || strings.HasPrefix(r.URL.Path, "/shared/") || docsPaths[r.URL.Path]
+}
+
+// withAuth requires an X-API-Key or bearer token with a role allowed to make
+// the request once authentication is enabled, and attributes the request to
+// its tenant.
+// Admin keys may name another tenant in X-Tenant-ID. Shared links carry
+// their own token and stay public, as do CORS preflights and the API docs.
+func withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authEnabled() || publicRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key, err := credentialsOf(r)
+		if err != nil {
+			if jwtEnabled() {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			}
+			writeError(w, http.StatusUnauthorized, "UNAUTHENTICATED", err.Error())
+			return
+		}
+		setAuditActor(r, key.ID)
+		if t := r.Header.Get(tenantHeader); key.Role != "admin" && t != "" && t != key.Tenant {
+			writeForbidden(w, "TENANT_MISMATCH", "credentials do not belong to tenant "+t, "", "")
+			return
+		}
+		if key.Role != "admin" || r.Header.Get(tenantHeader) == "" {
+			r.Header.Set(tenantHeader, key.Tenant)
+		}
+		if required := requiredRole(r); roleRank(key.Role) < roleRank(required) {
+			writeForbidden(w, "INSUFFICIENT_ROLE", "role "+key.Role+" cannot make this request, "+required+" is required", key.Role, required)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type createKeyReq struct {
+	Name   string `json:"name"`
+	Tenant string `json:"tenant"`
+	Role   string `json:"role"`
+	Scope  string `json:"scope"`
+}
+
+// issuedKey is a key with its secret, which is shown only when the key is
+// created or rotated.
+type issuedKey struct {
+	apiKey
+	Key string `json:"key"`
+}
+
+var apiKeyListParams = paramSchema{
+	stringParam("tenant", ""),
+	boolParam("include_revoked", false),
+}
+
+// apiKeysHandler lists API keys and createAPIKeyHandler issues one.
+func apiKeysHandler(w http.ResponseWriter, r *http.Request) {
+	var errs paramErrors
+	p := apiKeyListParams.parse(r.URL.Query(), &errs)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	data := []apiKey{}
+	apiKeys.Lock()
+	for _, k := range apiKeys.m {
+		if (p.str("tenant") == "" || k.Tenant == p.str("tenant")) && (k.RevokedAt == nil || p.bool("include_revoked")) {
+			data = append(data, k.apiKey)
+		}
+	}
+	apiKeys.Unlock()
+	sort.Slice(data, func(i, j int) bool { return data[i].CreatedAt.Time.Before(data[j].CreatedAt.Time) })
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data, "count": len(data)})
+}
+
+func createAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var body createKeyReq
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if body.Tenant == "" {
+		body.Tenant = defaultTenant
+	}
+	if body.Role == "" {
+		body.Role = body.Scope
+	}
+	if body.Role == "" {
+		body.Role = "viewer"
+	}
+	if !tenantIDPattern.MatchString(body.Tenant) {
+		writeError(w, http.StatusBadRequest, "INVALID_TENANT", "invalid tenant id")
+		return
+	}
+	role := normalizeRole(body.Role)
+	if role == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_ROLE", `"role" must be one of `+strings.Join(roles, ", "))
+		return
+	}
+	secret := newAPIKeySecret()
+	k := &storedKey{
+		apiKey: apiKey{
+			ID:        newJobID(),
+			Name:      body.Name,
+			Tenant:    body.Tenant,
+			Role:      role,
+			Prefix:    secret[:11],
+			CreatedAt: store.Now(),
+		},
+		Hash: hashAPIKey(secret),
+	}
+	apiKeys.Lock()
+	apiKeys.m[k.ID] = k
+	err := saveAPIKeys()
+	apiKeys.enabled = true
+	apiKeys.Unlock()
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", versionedPath(r, "/admin/keys/"+k.ID))
+	writeJSON(w, http.StatusCreated, issuedKey{apiKey: k.apiKey, Key: secret})
+}
+
+type rotateKeyReq struct {
+	GraceSeconds int `json:"grace_seconds"`
+}
+
+// updateKey applies change to the key id under the lock and persists it.
+func updateKey(id string, change func(k *storedKey) error) (apiKey, error) {
+	apiKeys.Lock()
+	defer apiKeys.Unlock()
+	k, ok := apiKeys.m[id]
+	if !ok {
+		return apiKey{}, errKeyNotFound
+	}
+	if k.RevokedAt != nil {
+		return apiKey{}, errKeyRevoked
+	}
+	if err := change(k); err != nil {
+		return apiKey{}, err
+	}
+	return k.apiKey, saveAPIKeys()
+}
+
+func writeKeyError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errKeyNotFound):
+		writeError(w, http.StatusNotFound, "KEY_NOT_FOUND", err.Error())
+	case errors.Is(err, errKeyRevoked):
+		writeError(w, http.StatusConflict, "KEY_REVOKED", err.Error())
+	default:
+		writeStorageError(w, r, err)
+	}
+}
+
+// apiKeyHandler reads one key.
+func apiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	apiKeys.Lock()
+	k, ok := apiKeys.m[r.PathValue("id")]
+	var key apiKey
+	if ok {
+		key = k.apiKey
+	}
+	apiKeys.Unlock()
+	if !ok {
+		writeKeyError(w, r, errKeyNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, key)
+}
+
+// revokeAPIKeyHandler revokes a key.
+func revokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	key, err := updateKey(r.PathValue("id"), func(k *storedKey) error {
+		now := store.Now()
+		k.RevokedAt = &now
+		return nil
+	})
+	if err != nil {
+		writeKeyError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, key)
+}
+
+// rotateAPIKeyHandler issues a new secret for a key.
+func rotateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var body rotateKeyReq
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if body.GraceSeconds < 0 || body.GraceSeconds > maxRotationGrace {
+		writeError(w, http.StatusBadRequest, "INVALID_GRACE_PERIOD", "grace_seconds must be between 0 and 604800")
+		return
+	}
+	secret := newAPIKeySecret()
+	key, err := updateKey(r.PathValue("id"), func(k *storedKey) error {
+		now := store.Now()
+		k.PreviousHash, k.PreviousUntil = "", nil
+		if body.GraceSeconds > 0 {
+			until := store.NewTimestamp(now.Time.Add(time.Duration(body.GraceSeconds) * time.Second))
+			k.PreviousHash, k.PreviousUntil = k.Hash, &until
+		}
+		k.Hash, k.Prefix, k.RotatedAt = hashAPIKey(secret), secret[:11], &now
+		return nil
+	})
+	if err != nil {
+		writeKeyError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, issuedKey{apiKey: key, Key: secret})
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Background workers run beside the server until Shutdown cancels
+// backgroundCtx, and are waited on before the store they read and write is
+// closed.
+var (
+	backgroundCtx, stopBackgroundWorkers = context.WithCancel(context.Background())
+	backgroundWorkers                    sync.WaitGroup
+)
+
+// goBackground runs worker, which must return once its context is done.
+func goBackground(worker func(ctx context.Context)) {
+	backgroundWorkers.Add(1)
+	go func() {
+		defer backgroundWorkers.Done()
+		worker(backgroundCtx)
+	}()
+}
+
+// every calls fn every interval in the background until shutdown.
+func every(interval time.Duration, fn func()) {
+	goBackground(func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	})
+}
+
+// sleepContext waits for d, returning false early if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// stopBackground stops the background workers and waits for the ones
+// mid-task to finish until ctx is done.
+func stopBackground(ctx context.Context) error {
+	stopBackgroundWorkers()
+	done := make(chan struct{})
+	go func() {
+		backgroundWorkers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
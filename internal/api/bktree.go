@@ -0,0 +1,239 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	defaultFuzzyDistance = 2
+	maxFuzzyDistance     = 5
+	defaultFuzzyLimit    = 10
+	maxFuzzyLimit        = 100
+)
+
+type bkNode struct {
+	value    string
+	deleted  bool
+	children map[int]*bkNode
+}
+
+// bkTree indexes values by Levenshtein distance. Deletes only mark nodes as
+// tombstones; rebuild drops them.
+type bkTree struct {
+	sync.RWMutex
+	root        *bkNode
+	nodes       map[string]*bkNode
+	live        int
+	queries     int64
+	visits      int64
+	lastVisits  int
+	lastQuery   time.Time
+	lastRebuild time.Time
+}
+
+type fuzzyMatch struct {
+	Value    string `json:"value"`
+	Distance int    `json:"distance"`
+}
+
+var fuzzyIndex = newTenantShards(newBKTree)
+
+func newBKTree() *bkTree {
+	return &bkTree{nodes: map[string]*bkNode{}}
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func (t *bkTree) insert(value string) {
+	t.Lock()
+	defer t.Unlock()
+	t.insertLocked(value)
+}
+
+func (t *bkTree) insertLocked(value string) {
+	if n, ok := t.nodes[value]; ok {
+		if n.deleted {
+			n.deleted = false
+			t.live++
+		}
+		return
+	}
+	node := &bkNode{value: value, children: map[int]*bkNode{}}
+	t.nodes[value] = node
+	t.live++
+	if t.root == nil {
+		t.root = node
+		return
+	}
+	n := t.root
+	for {
+		d := levenshtein(value, n.value)
+		child, ok := n.children[d]
+		if !ok {
+			n.children[d] = node
+			return
+		}
+		n = child
+	}
+}
+
+func (t *bkTree) remove(value string) {
+	t.Lock()
+	defer t.Unlock()
+	if n, ok := t.nodes[value]; ok && !n.deleted {
+		n.deleted = true
+		t.live--
+	}
+}
+
+func (t *bkTree) rebuild(values []string) {
+	t.Lock()
+	defer t.Unlock()
+	t.root = nil
+	t.nodes = map[string]*bkNode{}
+	t.live = 0
+	for _, v := range values {
+		t.insertLocked(v)
+	}
+	t.lastRebuild = time.Now()
+}
+
+func (t *bkTree) search(query string, maxDist int) ([]fuzzyMatch, int) {
+	t.Lock()
+	defer t.Unlock()
+	matches := []fuzzyMatch{}
+	visits := 0
+	if t.root != nil {
+		stack := []*bkNode{t.root}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			visits++
+			d := levenshtein(query, n.value)
+			if d <= maxDist && !n.deleted {
+				matches = append(matches, fuzzyMatch{Value: n.value, Distance: d})
+			}
+			for cd, c := range n.children {
+				if cd >= d-maxDist && cd <= d+maxDist {
+					stack = append(stack, c)
+				}
+			}
+		}
+	}
+	t.queries++
+	t.visits += int64(visits)
+	t.lastVisits = visits
+	t.lastQuery = time.Now()
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].Value < matches[j].Value
+	})
+	return matches, visits
+}
+
+// fuzzyMetrics sums the trees of every tenant; the last query and rebuild
+// are those of whichever tree saw one most recently.
+func fuzzyMetrics() map[string]interface{} {
+	var live, nodes, lastVisits int
+	var queries, visits int64
+	var lastQuery, lastRebuild time.Time
+	for _, t := range fuzzyIndex.all() {
+		t.RLock()
+		live += t.live
+		nodes += len(t.nodes)
+		queries += t.queries
+		visits += t.visits
+		if t.lastQuery.After(lastQuery) {
+			lastQuery, lastVisits = t.lastQuery, t.lastVisits
+		}
+		if t.lastRebuild.After(lastRebuild) {
+			lastRebuild = t.lastRebuild
+		}
+		t.RUnlock()
+	}
+	avg := 0.0
+	if queries > 0 {
+		avg = float64(visits) / float64(queries)
+	}
+	m := map[string]interface{}{
+		"name":              "bktree",
+		"size":              live,
+		"nodes":             nodes,
+		"tombstones":        nodes - live,
+		"queries":           queries,
+		"total_node_visits": visits,
+		"avg_node_visits":   avg,
+		"last_query_visits": lastVisits,
+		"last_rebuild_at":   nil,
+	}
+	if !lastRebuild.IsZero() {
+		m["last_rebuild_at"] = store.NewTimestamp(lastRebuild)
+	}
+	return m
+}
+
+var fuzzyParams = paramSchema{
+	requiredParam("q"),
+	intParam("max_distance", 0, maxFuzzyDistance, defaultFuzzyDistance),
+	intParam("limit", 1, maxFuzzyLimit, defaultFuzzyLimit),
+	boolParam("explain", false),
+}
+
+func fuzzyHandler(w http.ResponseWriter, r *http.Request) {
+	if !indexEnabled("bktree") {
+		writeIndexDisabled(w, "bktree")
+		return
+	}
+	var errs paramErrors
+	p := fuzzyParams.parse(r.URL.Query(), &errs)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	query, maxDist, limit, explain := p.str("q"), p.int("max_distance"), p.int("limit"), p.bool("explain")
+	start := time.Now()
+	matches, visits := fuzzyIndex.get(tenantOf(r)).search(query, maxDist)
+	ex := newQueryExplain("index", "bk_tree")
+	ex.Scanned, ex.Matched = visits, len(matches)
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	resp := map[string]interface{}{
+		"data":          matches,
+		"count":         len(matches),
+		"query":         query,
+		"max_distance":  maxDist,
+		"nodes_visited": visits,
+	}
+	if explain {
+		ex.DurationMs = millisSince(start)
+		resp["explain"] = ex
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
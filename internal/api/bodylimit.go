@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const defaultMaxBodyBytes = 10 << 20
+
+// limitedRequestBody records whether the handler read past the limit.
+type limitedRequestBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedRequestBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// bodyLimitResponse replaces whatever the handler answers with a 413 once
+// the body it read turned out to be too large, so every handler reports
+// the limit the same way however it surfaces the read error.
+type bodyLimitResponse struct {
+	http.ResponseWriter
+	body     *limitedRequestBody
+	limit    int64
+	replaced bool
+}
+
+func writeBodyTooLarge(w http.ResponseWriter, limit int64) {
+	w.Header().Set("Connection", "close")
+	writeErrorDetails(w, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", fmt.Sprintf("request body exceeds %d bytes", limit), map[string]int64{"limit_bytes": limit})
+}
+
+func (b *bodyLimitResponse) WriteHeader(code int) {
+	if b.body.exceeded && !b.replaced {
+		b.replaced = true
+		b.Header().Del("Content-Length")
+		writeBodyTooLarge(b.ResponseWriter, b.limit)
+		return
+	}
+	if !b.replaced {
+		b.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (b *bodyLimitResponse) Write(p []byte) (int, error) {
+	if b.body.exceeded && !b.replaced {
+		b.WriteHeader(http.StatusOK)
+	}
+	if b.replaced {
+		return len(p), nil
+	}
+	return b.ResponseWriter.Write(p)
+}
+
+// withBodyLimit caps request bodies at the MaxBodyBytes in effect so a
+// single huge POST cannot exhaust memory. A limit of 0 disables it.
+func withBodyLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := settings().MaxBodyBytes
+		if limit == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.ContentLength > limit {
+			writeBodyTooLarge(w, limit)
+			return
+		}
+		body := &limitedRequestBody{ReadCloser: http.MaxBytesReader(w, r.Body, limit)}
+		r.Body = body
+		next.ServeHTTP(&bodyLimitResponse{ResponseWriter: w, body: body, limit: limit}, r)
+	})
+}
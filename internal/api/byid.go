@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	minIDPrefix       = 4
+	maxPrefixMatches  = 20
+	fullRecordIDChars = 64
+)
+
+var recordIDPattern = regexp.MustCompile(`^[0-9a-f]{4,64}$`)
+
+// stringByIDHandler looks up or deletes a record by the id returned when it
+// was created, so clients need not send a possibly huge or secret value in
+// the URL again. Lookups also accept a unique prefix of the id, git-style;
+// deletes need the whole id.
+func stringByIDHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(r.PathValue("id"))
+	if !recordIDPattern.MatchString(id) || (r.Method == http.MethodDelete && len(id) != fullRecordIDChars) {
+		writeError(w, http.StatusBadRequest, "INVALID_ID", fmt.Sprintf("invalid id, expected a hex SHA-256 digest, or for lookups a prefix of at least %d characters", minIDPrefix))
+		return
+	}
+	tenant := tenantOf(r)
+	if len(id) < fullRecordIDChars {
+		var ok bool
+		if id, ok = resolveIDPrefix(w, r, id); !ok {
+			return
+		}
+	} else if item, err := backendOf(storeFor(r.Context())).Get(id); err == nil && storedTenant(item) != tenant {
+		writeStorageError(w, r, store.ErrNotFound)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		deleteString(w, r, id, storeFor(r.Context()).(*indexedStorage).softDelete)
+		return
+	}
+	writeStoredRecord(w, r, id)
+}
+
+// resolveIDPrefix finds the one record of the request's tenant whose id
+// starts with prefix. When several do it answers 300 Multiple Choices listing their ids,
+// but not their values, which may be large or secret.
+func resolveIDPrefix(w http.ResponseWriter, r *http.Request, prefix string) (string, bool) {
+	tenant := tenantOf(r)
+	items, err := storeFor(r.Context()).Filter(func(item store.StoredString) bool {
+		return strings.HasPrefix(item.ID, prefix) && storedTenant(item) == tenant
+	})
+	if err != nil {
+		writeStorageError(w, r, err)
+		return "", false
+	}
+	switch len(items) {
+	case 0:
+		writeStorageError(w, r, store.ErrNotFound)
+		return "", false
+	case 1:
+		return items[0].ID, true
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	matches := make([]map[string]string, 0, maxPrefixMatches)
+	for _, item := range items {
+		if len(matches) == maxPrefixMatches {
+			break
+		}
+		matches = append(matches, map[string]string{"id": item.ID, "url": versionedPath(r, "/strings/id/"+item.ID)})
+	}
+	writeErrorDetails(w, http.StatusMultipleChoices, "AMBIGUOUS_ID", fmt.Sprintf("id prefix %q is ambiguous", prefix), map[string]interface{}{
+		"count":   len(items),
+		"matches": matches,
+	})
+	return "", false
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const maxCachedListings = 256
+
+// storeVersion is bumped on every write so cached listings computed against
+// an older dataset are never served.
+var storeVersion atomic.Uint64
+
+type cachedListing struct {
+	version uint64
+	results []store.StoredString
+}
+
+type listingCache struct {
+	sync.Mutex
+	disabled bool
+	entries  map[string]cachedListing
+	hits     uint64
+	misses   uint64
+}
+
+var listCache = &listingCache{entries: map[string]cachedListing{}}
+
+func listingCacheKey(filters filterSet, sortBy *sortSpec, includeDeleted bool) string {
+	key := map[string]interface{}{"filters": filters.applied(), "tenant": filters.tenant}
+	if includeDeleted {
+		key["include_deleted"] = true
+	}
+	if sortBy != nil {
+		key["sort_by"] = sortBy.key
+		key["desc"] = sortBy.desc
+	}
+	b, _ := json.Marshal(key)
+	return string(b)
+}
+
+func (c *listingCache) get(key string) ([]store.StoredString, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.entries[key]
+	if ok && !c.disabled && e.version == storeVersion.Load() {
+		c.hits++
+		return e.results, true
+	}
+	c.misses++
+	return nil, false
+}
+
+func (c *listingCache) put(key string, version uint64, results []store.StoredString) {
+	c.Lock()
+	defer c.Unlock()
+	if c.disabled || version != storeVersion.Load() {
+		return
+	}
+	for k, e := range c.entries {
+		if e.version != version {
+			delete(c.entries, k)
+		}
+	}
+	if len(c.entries) >= maxCachedListings {
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[key] = cachedListing{version: version, results: results}
+}
+
+func (c *listingCache) stats() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	ratio := 0.0
+	if total := c.hits + c.misses; total > 0 {
+		ratio = float64(c.hits) / float64(total)
+	}
+	return map[string]interface{}{
+		"enabled":         !c.disabled,
+		"entries":         len(c.entries),
+		"hits":            c.hits,
+		"misses":          c.misses,
+		"hit_ratio":       ratio,
+		"dataset_version": storeVersion.Load(),
+	}
+}
+
+func cacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, listCache.stats())
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+// cachePolicies maps routes, as routeOf names them, to the Cache-Control sent on their
+// successful GET and HEAD responses. Records change when they are replaced,
+// re-analyzed, tagged, deleted or restored, so records, their history and
+// listings are cacheable but revalidated with their ETag on every use. Routes
+// not listed use defaultCachePolicy.
+var cachePolicies = map[string]string{
+	"/strings/":                           "public, no-cache",
+	"/strings/id/":                        "public, no-cache",
+	"/shared/":                            "public, max-age=300",
+	"/strings":                            "public, no-cache",
+	"/strings/filter-by-natural-language": "public, no-cache",
+	"/strings/stats/timeseries":           "public, no-cache",
+	"/strings/stats/words":                "public, no-cache",
+	"/strings/typeahead":                  "public, no-cache",
+	"/strings/palindrome-pairs":           "public, no-cache",
+	"/strings/fuzzy":                      "public, no-cache",
+	"/strings/export":                     "public, no-cache",
+	"/strings/semantic-search":            "public, no-cache",
+	"/openapi.json":                       "public, no-cache",
+	"/docs":                               "public, no-cache",
+}
+
+const defaultCachePolicy = "no-store"
+
+// configureCachePolicies applies CACHE_POLICIES, a `;`-separated list of
+// `route=directives` overrides such as
+// `/strings/=public, max-age=31536000, immutable`. The route `*` replaces
+// the default policy.
+func configureCachePolicies(v string) error {
+	for _, entry := range strings.Split(v, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		route, policy, ok := strings.Cut(entry, "=")
+		route, policy = strings.TrimSpace(route), strings.TrimSpace(policy)
+		if !ok || route == "" || policy == "" {
+			return fmt.Errorf("invalid CACHE_POLICIES entry %q", entry)
+		}
+		cachePolicies[route] = policy
+	}
+	return nil
+}
+
+func cachePolicyFor(r *http.Request) string {
+	policy, ok := cachePolicies[routeOf(r)]
+	if !ok {
+		if policy, ok = cachePolicies["*"]; !ok {
+			policy = defaultCachePolicy
+		}
+	}
+	if authEnabled() && !publicRequest(r) {
+		return privatePolicy(policy)
+	}
+	return policy
+}
+
+// privatePolicy turns policy into one only the caller's own cache may
+// follow, for responses that depend on the credentials of the request.
+func privatePolicy(policy string) string {
+	directives := []string{"private"}
+	for _, d := range strings.Split(policy, ",") {
+		switch d = strings.TrimSpace(d); strings.ToLower(d) {
+		case "no-store":
+			return policy
+		case "public", "private", "":
+		default:
+			if !strings.HasPrefix(strings.ToLower(d), "s-maxage") {
+				directives = append(directives, d)
+			}
+		}
+	}
+	return strings.Join(directives, ", ")
+}
+
+// setLastModified sets Last-Modified to when item was last analyzed.
+func setLastModified(w http.ResponseWriter, item store.StoredString) {
+	w.Header().Set("Last-Modified", lastModified(item).UTC().Format(http.TimeFormat))
+}
+
+// notModifiedSince reports whether a response last modified at the
+// Last-Modified value lm is unchanged since the If-Modified-Since ims.
+func notModifiedSince(lm, ims string) bool {
+	modified, err := http.ParseTime(lm)
+	if err != nil {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	return err == nil && !modified.After(since)
+}
+
+type cachePolicyResponse struct {
+	http.ResponseWriter
+	policy      string
+	vary        []string
+	wroteHeader bool
+}
+
+func (c *cachePolicyResponse) WriteHeader(code int) {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		h := c.Header()
+		for _, v := range c.vary {
+			h.Add("Vary", v)
+		}
+		if h.Get("Cache-Control") == "" {
+			if code == http.StatusOK || code == http.StatusNotModified {
+				h.Set("Cache-Control", c.policy)
+			} else {
+				h.Set("Cache-Control", "no-store")
+			}
+		}
+	}
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *cachePolicyResponse) Write(p []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	return c.ResponseWriter.Write(p)
+}
+
+// withCachePolicy adds the route's Cache-Control to GET and HEAD responses
+// so the read path can sit behind a CDN. Errors are never cached, and every
+// response varies by tenant. Once authentication is enabled the tenant
+// comes from the credentials, so responses that need them are private and
+// also vary by them.
+func withCachePolicy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		vary := []string{tenantHeader}
+		if authEnabled() && !publicRequest(r) {
+			vary = append(vary, "X-API-Key", "Authorization")
+		}
+		next.ServeHTTP(&cachePolicyResponse{ResponseWriter: w, policy: cachePolicyFor(r), vary: vary}, r)
+	})
+}
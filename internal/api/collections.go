@@ -0,0 +1,327 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+var collectionNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// collectionKey names a collection of one tenant; tenants create and
+// configure collections independently, even under the same names.
+type collectionKey struct {
+	tenant, name string
+}
+
+// collectionConfigs caches the analyzer configurations the backend keeps
+// with the collections, loaded by installStorage.
+var collectionConfigs = struct {
+	sync.RWMutex
+	m map[collectionKey]analysis.Options
+}{m: map[collectionKey]analysis.Options{}}
+
+// loadCollections replaces collectionConfigs with the configurations kept
+// by s.
+func loadCollections(s store.Storage) error {
+	m := map[collectionKey]analysis.Options{}
+	if cs, ok := s.(store.CollectionStore); ok {
+		list, err := cs.Collections()
+		if err != nil {
+			return err
+		}
+		for _, c := range list {
+			m[collectionKey{c.Tenant, c.Name}] = c.Config
+		}
+	}
+	collectionConfigs.Lock()
+	collectionConfigs.m = m
+	collectionConfigs.Unlock()
+	return nil
+}
+
+// saveCollection stores the configuration with the backend and caches it.
+// Callers hold collectionConfigs.Mutex.
+func saveCollection(key collectionKey, opts analysis.Options) error {
+	if cs, ok := backend().(store.CollectionStore); ok {
+		if err := cs.PutCollection(store.Collection{Tenant: key.tenant, Name: key.name, Config: opts}); err != nil {
+			return err
+		}
+	}
+	collectionConfigs.m[key] = opts
+	return nil
+}
+
+// forgetCollection removes the configuration from the backend and the
+// cache. Callers hold collectionConfigs.Mutex.
+func forgetCollection(key collectionKey) error {
+	if cs, ok := backend().(store.CollectionStore); ok {
+		if err := cs.DeleteCollection(key.tenant, key.name); err != nil {
+			return err
+		}
+	}
+	delete(collectionConfigs.m, key)
+	return nil
+}
+
+// collectionOptions returns the analyzer configuration of the tenant's
+// collection, or the defaults when the collection has none.
+func collectionOptions(tenant, name string) analysis.Options {
+	collectionConfigs.RLock()
+	opts, ok := collectionConfigs.m[collectionKey{tenant, name}]
+	collectionConfigs.RUnlock()
+	if !ok {
+		return analysis.DefaultOptions()
+	}
+	return opts
+}
+
+// recordKeySeparators end the tenant and collection parts of a record key.
+// Tenant IDs and collection names cannot hold them and values created
+// through the API are refused with them, so each key has one reading.
+const recordKeySeparators = "\x00\x01"
+
+// recordID is the ID of value in the tenant's collection. Strings of the
+// default tenant outside any collection are identified by the hash of their
+// value alone; otherwise the collection name and tenant are hashed in too,
+// so the same value can be stored, and deleted, in each separately.
+func recordID(tenant, collection, value string) string {
+	key := value
+	if collection != "" {
+		key = collection + "\x00" + key
+	}
+	if tenant != defaultTenant {
+		key = tenant + "\x01" + key
+	}
+	return analysis.Hash(key)
+}
+
+// recordInScope reports whether item is the tenant's record in collection,
+// which a record found under recordID's ID need not be when it was stored
+// before values holding recordKeySeparators were refused.
+func recordInScope(item store.StoredString, tenant, collection string) bool {
+	return storedTenant(item) == tenant && item.Collection == collection
+}
+
+type collectionSummary struct {
+	Name   string           `json:"name"`
+	Count  int              `json:"count"`
+	Config analysis.Options `json:"config"`
+}
+
+// collectionSummaries lists every collection of the tenant that was created
+// or holds strings, by name.
+func collectionSummaries(tenant string) ([]collectionSummary, error) {
+	items, err := db.List()
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, item := range items {
+		if item.Collection != "" && storedTenant(item) == tenant {
+			counts[item.Collection]++
+		}
+	}
+	collectionConfigs.RLock()
+	for key := range collectionConfigs.m {
+		if _, ok := counts[key.name]; !ok && key.tenant == tenant {
+			counts[key.name] = 0
+		}
+	}
+	collectionConfigs.RUnlock()
+	out := make([]collectionSummary, 0, len(counts))
+	for name, n := range counts {
+		out = append(out, collectionSummary{Name: name, Count: n, Config: collectionOptions(tenant, name)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+type createCollectionReq struct {
+	Name   string            `json:"name"`
+	Config *analysis.Options `json:"config"`
+}
+
+// collectionsHandler lists collections and creates them with an optional
+// analyzer configuration.
+func collectionsHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := collectionSummaries(tenantOf(r))
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data, "count": len(data)})
+}
+
+func createCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	var body createCollectionReq
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if !collectionNamePattern.MatchString(body.Name) {
+		writeError(w, http.StatusBadRequest, "INVALID_COLLECTION", "invalid collection name")
+		return
+	}
+	opts := analysis.DefaultOptions()
+	if body.Config != nil {
+		var err error
+		if opts, err = body.Config.Normalize(); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "INVALID_CONFIG", err.Error())
+			return
+		}
+	}
+	key := collectionKey{tenantOf(r), body.Name}
+	collectionConfigs.Lock()
+	_, exists := collectionConfigs.m[key]
+	var err error
+	if !exists {
+		err = saveCollection(key, opts)
+	}
+	collectionConfigs.Unlock()
+	if exists {
+		writeError(w, http.StatusConflict, "COLLECTION_EXISTS", "collection already exists")
+		return
+	}
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", versionedPath(r, "/collections/"+body.Name))
+	writeJSON(w, http.StatusCreated, collectionSummary{Name: body.Name, Config: opts})
+}
+
+// deleteCollection hard-deletes every string of the tenant's collection,
+// soft-deleted and expired ones included, and forgets its configuration.
+func deleteCollection(tenant, name string) (int, error) {
+	items, err := backend().Filter(func(item store.StoredString) bool {
+		return item.Collection == name && storedTenant(item) == tenant
+	})
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, item := range items {
+		if _, err := db.Delete(item.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return n, err
+		}
+		n++
+	}
+	collectionConfigs.Lock()
+	defer collectionConfigs.Unlock()
+	return n, forgetCollection(collectionKey{tenant, name})
+}
+
+// pathCollection reads the collection named by the request path, answering
+// 400 when it is not a valid collection name.
+func pathCollection(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := r.PathValue("name")
+	if !collectionNamePattern.MatchString(name) {
+		writeError(w, http.StatusBadRequest, "INVALID_COLLECTION", "invalid collection name")
+		return "", false
+	}
+	return name, true
+}
+
+// collectionRoutes are the /strings routes served scoped to a collection.
+var collectionRoutes = map[string]bool{"/strings": true, "/strings/": true, "/strings/reanalyze": true}
+
+// collectionStringsHandler serves /collections/{name}/strings[/...] as the
+// matching /strings route scoped to the collection.
+func collectionStringsHandler(w http.ResponseWriter, r *http.Request) {
+	name, ok := pathCollection(w, r)
+	if !ok {
+		return
+	}
+	scoped := collectionRequest(r, name)
+	if route := routeOf(scoped); route != "" && !collectionRoutes[route] {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+		return
+	}
+	router.ServeHTTP(w, scoped)
+}
+
+// collectionRequest returns r as the /strings request it makes in the
+// collection.
+func collectionRequest(r *http.Request, name string) *http.Request {
+	scoped := r.Clone(r.Context())
+	scoped.URL.RawPath = strings.TrimPrefix(r.URL.EscapedPath(), "/collections/"+name)
+	scoped.URL.Path, _ = url.PathUnescape(scoped.URL.RawPath)
+	q := scoped.URL.Query()
+	q.Set("collection", name)
+	scoped.URL.RawQuery = q.Encode()
+	return scoped
+}
+
+func collectionHandler(w http.ResponseWriter, r *http.Request) {
+	name, ok := pathCollection(w, r)
+	if !ok {
+		return
+	}
+	data, err := collectionSummaries(tenantOf(r))
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	for _, c := range data {
+		if c.Name == name {
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "COLLECTION_NOT_FOUND", "collection not found")
+}
+
+func deleteCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	name, ok := pathCollection(w, r)
+	if !ok {
+		return
+	}
+	n, err := deleteCollection(tenantOf(r), name)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"collection": name, "strings_deleted": n})
+}
+
+func collectionConfigHandler(w http.ResponseWriter, r *http.Request) {
+	name, ok := pathCollection(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"collection": name, "config": collectionOptions(tenantOf(r), name)})
+}
+
+func putCollectionConfigHandler(w http.ResponseWriter, r *http.Request) {
+	name, ok := pathCollection(w, r)
+	if !ok {
+		return
+	}
+	var opts analysis.Options
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	opts, err := opts.Normalize()
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "INVALID_CONFIG", err.Error())
+		return
+	}
+	collectionConfigs.Lock()
+	err = saveCollection(collectionKey{tenantOf(r), name}, opts)
+	collectionConfigs.Unlock()
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"collection": name, "config": opts})
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+var (
+	errNothingToCompact  = errors.New("the storage backend keeps no local store files to compact")
+	errCompactionRunning = errors.New("a compaction is already running")
+)
+
+type compactionReport struct {
+	Trigger              string          `json:"trigger"`
+	StartedAt            store.Timestamp `json:"started_at"`
+	FinishedAt           store.Timestamp `json:"finished_at"`
+	BytesBefore          int64           `json:"bytes_before"`
+	BytesAfter           int64           `json:"bytes_after"`
+	BytesReclaimed       int64           `json:"bytes_reclaimed"`
+	LogEntries           int             `json:"log_entries"`
+	TombstonesDropped    int             `json:"tombstones_dropped"`
+	StaleVersionsDropped int             `json:"stale_versions_dropped"`
+	Error                string          `json:"error,omitempty"`
+}
+
+var compaction = struct {
+	sync.Mutex
+	running  bool
+	interval time.Duration
+	last     *compactionReport
+	total    int64
+	runs     int
+}{}
+
+// runCompaction rewrites the store files so they hold only current records,
+// dropping the log's tombstones and superseded versions.
+func runCompaction(trigger string) (compactionReport, error) {
+	if !store.Persistent() {
+		return compactionReport{}, errNothingToCompact
+	}
+	compaction.Lock()
+	if compaction.running {
+		compaction.Unlock()
+		return compactionReport{}, errCompactionRunning
+	}
+	compaction.running = true
+	compaction.Unlock()
+
+	report := compactionReport{Trigger: trigger, StartedAt: store.Now(), BytesBefore: store.FileBytes()}
+	var err error
+	report.LogEntries, report.TombstonesDropped, report.StaleVersionsDropped, err = store.LogStats()
+	if err == nil {
+		err = store.CompactLogs()
+	}
+	report.BytesAfter = store.FileBytes()
+	report.BytesReclaimed = max(report.BytesBefore-report.BytesAfter, 0)
+	report.FinishedAt = store.Now()
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	compaction.Lock()
+	compaction.running = false
+	compaction.last = &report
+	compaction.runs++
+	compaction.total += report.BytesReclaimed
+	compaction.Unlock()
+	return report, err
+}
+
+func startCompaction(interval time.Duration) {
+	compaction.Lock()
+	compaction.interval = interval
+	compaction.Unlock()
+	if interval <= 0 || (!store.Persistent()) {
+		return
+	}
+	every(interval, func() {
+		if _, err := runCompaction("scheduled"); err != nil {
+			logger.Error("compaction failed", "error", err)
+		}
+	})
+}
+
+func compactionHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := runCompaction("manual")
+	if errors.Is(err, errNothingToCompact) || errors.Is(err, errCompactionRunning) {
+		writeError(w, http.StatusConflict, "COMPACTION_RUNNING", err.Error())
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, report)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func compactionStatusHandler(w http.ResponseWriter, r *http.Request) {
+	compaction.Lock()
+	defer compaction.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"supported":             store.Persistent(),
+		"running":               compaction.running,
+		"interval_seconds":      int(compaction.interval / time.Second),
+		"runs":                  compaction.runs,
+		"total_bytes_reclaimed": compaction.total,
+		"store_bytes":           store.FileBytes(),
+		"last":                  compaction.last,
+	})
+}
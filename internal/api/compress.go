@@ -0,0 +1,128 @@
+package api
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// minCompressSize is the smallest body worth compressing; below it the
+// encoding overhead outweighs the savings.
+const minCompressSize = 1024
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// preferring the higher q-value and gzip on a tie. It returns "" when
+// neither is acceptable.
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if name == "*" {
+			name = "gzip"
+		}
+		if (name != "gzip" && name != "deflate") || q <= 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && name == "gzip") {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressResponse holds back the first minCompressSize bytes of a body to
+// decide whether to compress it, then streams the rest through the encoder.
+type compressResponse struct {
+	http.ResponseWriter
+	encoding string
+	code     int
+	buf      []byte
+	started  bool
+	enc      io.WriteCloser
+}
+
+func (c *compressResponse) WriteHeader(code int) {
+	if c.code == 0 {
+		c.code = code
+	}
+}
+
+func (c *compressResponse) Write(p []byte) (int, error) {
+	if c.code == 0 {
+		c.code = http.StatusOK
+	}
+	if c.started {
+		if c.enc != nil {
+			return c.enc.Write(p)
+		}
+		return c.ResponseWriter.Write(p)
+	}
+	c.buf = append(c.buf, p...)
+	if len(c.buf) >= minCompressSize {
+		if err := c.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (c *compressResponse) start(compress bool) error {
+	c.started = true
+	h := c.Header()
+	if compress && h.Get("Content-Encoding") == "" {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", c.encoding)
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		if c.encoding == "gzip" {
+			c.enc = gzip.NewWriter(c.ResponseWriter)
+		} else {
+			c.enc = zlib.NewWriter(c.ResponseWriter)
+		}
+		c.ResponseWriter.WriteHeader(c.code)
+		_, err := c.enc.Write(c.buf)
+		c.buf = nil
+		return err
+	}
+	c.ResponseWriter.WriteHeader(c.code)
+	_, err := c.ResponseWriter.Write(c.buf)
+	c.buf = nil
+	return err
+}
+
+func (c *compressResponse) finish() {
+	if !c.started && c.code != 0 {
+		_ = c.start(false)
+	}
+	if c.enc != nil {
+		_ = c.enc.Close()
+	}
+}
+
+// withCompression gzip- or deflate-encodes response bodies of at least
+// minCompressSize bytes for clients that accept it. The ETag of an encoded
+// body is marked weak, as its bytes differ from the identity response.
+func withCompression(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressResponse{ResponseWriter: w, encoding: encoding}
+		defer cw.finish()
+		next.ServeHTTP(cw, r)
+	})
+}
@@ -0,0 +1,402 @@
+package api
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the settings that can be given as command-line flags,
+// environment variables or keys of the YAML config file. A flag overrides
+// its variable, which overrides the file, which overrides the default.
+type Config struct {
+	ConfigPath string
+	Host       string
+	Port       int
+	Storage    string
+	DataDir    string
+	JobWorkers int
+	Demo       bool
+	PprofAddr  string
+	GRPCAddr   string
+	Tunables
+
+	TLSCertFile      string
+	TLSKeyFile       string
+	AutocertHosts    string
+	AutocertEmail    string
+	AutocertCacheDir string
+	ACMEHTTPAddr     string
+
+	ReadHeaderTimeoutSeconds int
+	ReadTimeoutSeconds       int
+	WriteTimeoutSeconds      int
+	IdleTimeoutSeconds       int
+	MaxHeaderBytes           int
+
+	// The paths of the files kept, each read from its own variable or
+	// placed under DataDir.
+	BoltPath     string
+	SnapshotPath string
+	WALPath      string
+	EventsPath   string
+	AuditPath    string
+	JobsDir      string
+	APIKeysPath  string
+}
+
+// Tunables are the settings a reload puts in effect without a restart.
+type Tunables struct {
+	MaxBodyBytes      int64
+	MinValueLength    int
+	MaxValueLength    int
+	RejectBlankValues bool
+	RejectInvalidUTF8 bool
+	// IdempotentDeletes makes deleting a missing string succeed with 204,
+	// so retried deletes need no special case. X-Idempotent-Delete
+	// overrides it per request.
+	IdempotentDeletes      bool
+	Pprof                  bool
+	RateLimitPerMinute     int
+	RateLimitBurst         int
+	ShutdownTimeoutSeconds int
+	RequestTimeoutSeconds  int
+}
+
+// configEnv names the environment variable of each flag.
+var configEnv = map[string]string{
+	"config":                      "CONFIG_FILE",
+	"host":                        "HOST",
+	"port":                        "PORT",
+	"storage":                     "STORAGE_BACKEND",
+	"data-dir":                    "DATA_DIR",
+	"job-workers":                 "JOB_WORKERS",
+	"demo":                        "DEMO",
+	"pprof-addr":                  "PPROF_ADDR",
+	"grpc-addr":                   "GRPC_ADDR",
+	"tls-cert":                    "TLS_CERT_FILE",
+	"tls-key":                     "TLS_KEY_FILE",
+	"autocert-hosts":              "AUTOCERT_HOSTS",
+	"autocert-email":              "AUTOCERT_EMAIL",
+	"autocert-cache-dir":          "AUTOCERT_CACHE_DIR",
+	"acme-http-addr":              "ACME_HTTP_ADDR",
+	"read-header-timeout-seconds": "READ_HEADER_TIMEOUT_SECONDS",
+	"read-timeout-seconds":        "READ_TIMEOUT_SECONDS",
+	"write-timeout-seconds":       "WRITE_TIMEOUT_SECONDS",
+	"idle-timeout-seconds":        "IDLE_TIMEOUT_SECONDS",
+	"max-header-bytes":            "MAX_HEADER_BYTES",
+	"max-body-bytes":              "MAX_BODY_BYTES",
+	"min-value-length":            "VALUE_MIN_LENGTH",
+	"max-value-length":            "VALUE_MAX_LENGTH",
+	"reject-blank-values":         "VALUE_REJECT_BLANK",
+	"reject-invalid-utf8":         "VALUE_REJECT_INVALID_UTF8",
+	"idempotent-deletes":          "IDEMPOTENT_DELETES",
+	"pprof":                       "PPROF_ENABLED",
+	"rate-limit-per-minute":       "RATE_LIMIT_PER_MINUTE",
+	"rate-limit-burst":            "RATE_LIMIT_BURST",
+	"shutdown-timeout-seconds":    "SHUTDOWN_TIMEOUT_SECONDS",
+	"request-timeout-seconds":     "REQUEST_TIMEOUT_SECONDS",
+}
+
+var defaultConfig = Config{
+	Port:         8080,
+	Storage:      "memory",
+	JobWorkers:   defaultJobWorkers,
+	ACMEHTTPAddr: ":80",
+
+	ReadHeaderTimeoutSeconds: 5,
+	ReadTimeoutSeconds:       60,
+	WriteTimeoutSeconds:      120,
+	IdleTimeoutSeconds:       120,
+	MaxHeaderBytes:           64 << 10,
+
+	Tunables: Tunables{
+		MaxBodyBytes:           defaultMaxBodyBytes,
+		RateLimitBurst:         defaultRateLimitBurst,
+		ShutdownTimeoutSeconds: int(defaultShutdownTimeout / time.Second),
+		RequestTimeoutSeconds:  30,
+	},
+}
+
+// liveConfig is the configuration in effect, replaced whole by a reload.
+var liveConfig atomic.Pointer[Config]
+
+// settings returns the configuration in effect. Requests read the tunables
+// through it rather than keeping a copy, so they see a reload.
+func settings() *Config {
+	if c := liveConfig.Load(); c != nil {
+		return c
+	}
+	return &defaultConfig
+}
+
+// Addr is the address the server listens on.
+func (c Config) Addr() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// configArgs are the arguments LoadConfig last read, re-read on reload.
+var configArgs []string
+
+// LoadConfig reads the settings from the config file, the environment and
+// args, and validates them, reporting every invalid one at once.
+func LoadConfig(args []string) (Config, error) {
+	configArgs = args
+	cfg := defaultConfig
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(&cfg.ConfigPath, "config", cfg.ConfigPath, "YAML file of settings, keyed by flag name, re-read on SIGHUP")
+	fs.StringVar(&cfg.Host, "host", cfg.Host, "interface to listen on; all when empty")
+	fs.IntVar(&cfg.Port, "port", cfg.Port, "port to listen on")
+	fs.StringVar(&cfg.Storage, "storage", cfg.Storage, "storage backend: memory, postgres, bolt or redis")
+	fs.StringVar(&cfg.DataDir, "data-dir", cfg.DataDir, "directory for the store, event, audit, job and API key files not given their own path")
+	fs.IntVar(&cfg.JobWorkers, "job-workers", cfg.JobWorkers, "background jobs run at once")
+	fs.BoolVar(&cfg.Demo, "demo", cfg.Demo, "run as a public playground with rate limits, a capped store, seeded data and periodic resets")
+	fs.StringVar(&cfg.PprofAddr, "pprof-addr", cfg.PprofAddr, "also serve profiles, without authentication, on this address")
+	fs.StringVar(&cfg.GRPCAddr, "grpc-addr", cfg.GRPCAddr, "also serve the gRPC StringService on this address")
+	fs.StringVar(&cfg.TLSCertFile, "tls-cert", cfg.TLSCertFile, "PEM certificate file to serve HTTPS with, re-read on SIGHUP")
+	fs.StringVar(&cfg.TLSKeyFile, "tls-key", cfg.TLSKeyFile, "PEM private key file of -tls-cert")
+	fs.StringVar(&cfg.AutocertHosts, "autocert-hosts", cfg.AutocertHosts, "comma-separated hostnames to serve HTTPS for with Let's Encrypt certificates")
+	fs.StringVar(&cfg.AutocertEmail, "autocert-email", cfg.AutocertEmail, "contact email for the Let's Encrypt account")
+	fs.StringVar(&cfg.AutocertCacheDir, "autocert-cache-dir", cfg.AutocertCacheDir, "directory where Let's Encrypt certificates are kept; autocert under -data-dir, else autocert-cache")
+	fs.StringVar(&cfg.ACMEHTTPAddr, "acme-http-addr", cfg.ACMEHTTPAddr, "address answering Let's Encrypt HTTP-01 challenges and redirecting to HTTPS; empty to disable")
+	fs.Int64Var(&cfg.MaxBodyBytes, "max-body-bytes", cfg.MaxBodyBytes, "largest request body accepted; 0 for no limit")
+	fs.IntVar(&cfg.MinValueLength, "min-value-length", cfg.MinValueLength, "shortest value accepted, in characters")
+	fs.IntVar(&cfg.MaxValueLength, "max-value-length", cfg.MaxValueLength, "longest value accepted, in characters; 0 for no limit")
+	fs.BoolVar(&cfg.RejectBlankValues, "reject-blank-values", cfg.RejectBlankValues, "reject values made only of whitespace")
+	fs.BoolVar(&cfg.RejectInvalidUTF8, "reject-invalid-utf8", cfg.RejectInvalidUTF8, "reject values that are not valid UTF-8")
+	fs.BoolVar(&cfg.IdempotentDeletes, "idempotent-deletes", cfg.IdempotentDeletes, "answer deletes of missing strings with 204 instead of 404")
+	fs.BoolVar(&cfg.Pprof, "pprof", cfg.Pprof, "serve profiles under /admin/debug/pprof/")
+	fs.IntVar(&cfg.ReadHeaderTimeoutSeconds, "read-header-timeout-seconds", cfg.ReadHeaderTimeoutSeconds, "time a client has to send the request headers; 0 for no limit")
+	fs.IntVar(&cfg.ReadTimeoutSeconds, "read-timeout-seconds", cfg.ReadTimeoutSeconds, "time a client has to send the whole request; 0 for no limit")
+	fs.IntVar(&cfg.WriteTimeoutSeconds, "write-timeout-seconds", cfg.WriteTimeoutSeconds, "time a response may take to write, from the end of the request headers; 0 for no limit")
+	fs.IntVar(&cfg.IdleTimeoutSeconds, "idle-timeout-seconds", cfg.IdleTimeoutSeconds, "time an idle keep-alive connection is kept open; 0 for the read timeout")
+	fs.IntVar(&cfg.MaxHeaderBytes, "max-header-bytes", cfg.MaxHeaderBytes, "largest request header block accepted")
+	fs.IntVar(&cfg.RequestTimeoutSeconds, "request-timeout-seconds", cfg.RequestTimeoutSeconds, "time a request's storage operations may take before it fails with 503; 0 for no limit")
+	fs.IntVar(&cfg.RateLimitPerMinute, "rate-limit-per-minute", cfg.RateLimitPerMinute, "requests each client IP may make per minute; 0 for no limit")
+	fs.IntVar(&cfg.RateLimitBurst, "rate-limit-burst", cfg.RateLimitBurst, "requests a client IP may make at once within its rate limit")
+	fs.IntVar(&cfg.ShutdownTimeoutSeconds, "shutdown-timeout-seconds", cfg.ShutdownTimeoutSeconds, "time given to in-flight requests on shutdown")
+	fs.VisitAll(func(f *flag.Flag) {
+		f.Usage += " (" + configEnv[f.Name] + ")"
+	})
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	var errs []error
+	if fs.NArg() > 0 {
+		errs = append(errs, fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+	// Flags were parsed first to find the config file; the file and then
+	// the environment fill in only what no flag set.
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	if !given["config"] {
+		cfg.ConfigPath = os.Getenv("CONFIG_FILE")
+	}
+	if cfg.ConfigPath != "" {
+		errs = append(errs, applyConfigFile(fs, cfg.ConfigPath, given)...)
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		env := configEnv[f.Name]
+		if v := os.Getenv(env); v != "" && !given[f.Name] {
+			if err := fs.Set(f.Name, v); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s: %s", env, v))
+				_ = fs.Set(f.Name, f.DefValue)
+			}
+		}
+	})
+	if cfg.Demo && cfg.RateLimitPerMinute == 0 {
+		cfg.RateLimitPerMinute = demoRequestsPerMinute
+	}
+
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid port %d: must be between 1 and 65535", cfg.Port))
+	}
+	switch cfg.Storage {
+	case "memory", "postgres", "bolt", "redis":
+	default:
+		errs = append(errs, fmt.Errorf("unknown storage backend %q", cfg.Storage))
+	}
+	if cfg.MaxBodyBytes < 0 {
+		errs = append(errs, fmt.Errorf("invalid max body bytes %d: must not be negative", cfg.MaxBodyBytes))
+	}
+	if cfg.MinValueLength < 0 || cfg.MaxValueLength < 0 {
+		errs = append(errs, errors.New("value length limits must not be negative"))
+	} else if cfg.MaxValueLength > 0 && cfg.MinValueLength > cfg.MaxValueLength {
+		errs = append(errs, errors.New("the minimum value length must not exceed the maximum"))
+	}
+	if cfg.JobWorkers < 1 {
+		errs = append(errs, fmt.Errorf("invalid job workers %d: must be at least 1", cfg.JobWorkers))
+	}
+	if cfg.RateLimitPerMinute < 0 {
+		errs = append(errs, fmt.Errorf("invalid rate limit %d: must not be negative", cfg.RateLimitPerMinute))
+	}
+	if cfg.RateLimitBurst < 1 {
+		errs = append(errs, fmt.Errorf("invalid rate limit burst %d: must be at least 1", cfg.RateLimitBurst))
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		errs = append(errs, errors.New("a TLS certificate and key must be given together"))
+	}
+	if hosts := splitList(cfg.AutocertHosts); len(hosts) > 0 {
+		if cfg.TLSCertFile != "" {
+			errs = append(errs, errors.New("autocert hosts cannot be combined with a TLS certificate"))
+		}
+		for _, h := range hosts {
+			if strings.ContainsAny(h, ":/") {
+				errs = append(errs, fmt.Errorf("invalid autocert host %q: must be a bare hostname", h))
+			}
+		}
+	}
+	for _, t := range []struct {
+		name string
+		n    int
+	}{
+		{"read header timeout", cfg.ReadHeaderTimeoutSeconds},
+		{"read timeout", cfg.ReadTimeoutSeconds},
+		{"write timeout", cfg.WriteTimeoutSeconds},
+		{"idle timeout", cfg.IdleTimeoutSeconds},
+		{"request timeout", cfg.RequestTimeoutSeconds},
+	} {
+		if t.n < 0 {
+			errs = append(errs, fmt.Errorf("invalid %s %d: must not be negative", t.name, t.n))
+		}
+	}
+	if cfg.MaxHeaderBytes < 1 {
+		errs = append(errs, fmt.Errorf("invalid max header bytes %d: must be at least 1", cfg.MaxHeaderBytes))
+	}
+	if cfg.ShutdownTimeoutSeconds < 1 {
+		errs = append(errs, fmt.Errorf("invalid shutdown timeout %d: must be at least 1 second", cfg.ShutdownTimeoutSeconds))
+	}
+
+	for _, p := range []struct {
+		dst       *string
+		env, name string
+	}{
+		{&cfg.BoltPath, "BOLT_PATH", store.DefaultBoltPath},
+		{&cfg.SnapshotPath, "STORE_SNAPSHOT_PATH", "store.json"},
+		{&cfg.WALPath, "STORE_WAL_PATH", "store.wal"},
+		{&cfg.EventsPath, "EVENTS_PATH", "events.jsonl"},
+		{&cfg.AuditPath, "AUDIT_PATH", "audit.jsonl"},
+		{&cfg.JobsDir, "JOBS_DIR", "jobs"},
+		{&cfg.APIKeysPath, "API_KEYS_PATH", "api_keys.json"},
+	} {
+		if *p.dst = os.Getenv(p.env); *p.dst == "" && cfg.DataDir != "" {
+			*p.dst = filepath.Join(cfg.DataDir, p.name)
+		}
+	}
+	if cfg.AutocertCacheDir == "" {
+		cfg.AutocertCacheDir = "autocert-cache"
+		if cfg.DataDir != "" {
+			cfg.AutocertCacheDir = filepath.Join(cfg.DataDir, "autocert")
+		}
+	}
+	if cfg.DataDir != "" {
+		if err := os.MkdirAll(cfg.DataDir, 0o755); err != nil {
+			errs = append(errs, fmt.Errorf("unable to create data directory: %w", err))
+		}
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// applyConfigFile sets the flags named by the keys of the YAML file at path,
+// except those given on the command line or in the environment.
+func applyConfigFile(fs *flag.FlagSet, path string, given map[string]bool) []error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []error{fmt.Errorf("unable to read config file: %w", err)}
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return []error{fmt.Errorf("invalid config file %s: %w", path, err)}
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, k := range keys {
+		if fs.Lookup(k) == nil || k == "config" {
+			errs = append(errs, fmt.Errorf("unknown setting %q in %s", k, path))
+			continue
+		}
+		if given[k] || os.Getenv(configEnv[k]) != "" {
+			continue
+		}
+		v := values[k]
+		switch v.(type) {
+		case string, bool, int, float64:
+		default:
+			errs = append(errs, fmt.Errorf("invalid %s in %s: %v", k, path, v))
+			continue
+		}
+		if err := fs.Set(k, fmt.Sprint(v)); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s in %s: %v", k, path, v))
+		}
+	}
+	return errs
+}
+
+// reloadConfig re-reads the configuration on SIGHUP and puts its tunables in
+// effect. A configuration that does not validate is logged and ignored, and
+// settings that only apply at startup keep their values until a restart.
+func reloadConfig() {
+	prev := settings()
+	next, err := LoadConfig(configArgs)
+	if err != nil {
+		logger.Error("config reload failed, keeping the current configuration", "error", err)
+		return
+	}
+	for _, s := range []struct {
+		name    string
+		changed bool
+	}{
+		{"host", next.Host != prev.Host},
+		{"port", next.Port != prev.Port},
+		{"storage", next.Storage != prev.Storage},
+		{"data-dir", next.DataDir != prev.DataDir},
+		{"job-workers", next.JobWorkers != prev.JobWorkers},
+		{"demo", next.Demo != prev.Demo},
+		{"pprof-addr", next.PprofAddr != prev.PprofAddr},
+		{"grpc-addr", next.GRPCAddr != prev.GRPCAddr},
+		{"tls-cert", next.TLSCertFile != prev.TLSCertFile},
+		{"tls-key", next.TLSKeyFile != prev.TLSKeyFile},
+		{"autocert-hosts", next.AutocertHosts != prev.AutocertHosts},
+		{"autocert-email", next.AutocertEmail != prev.AutocertEmail},
+		{"autocert-cache-dir", next.AutocertCacheDir != prev.AutocertCacheDir},
+		{"acme-http-addr", next.ACMEHTTPAddr != prev.ACMEHTTPAddr},
+		{"read-header-timeout-seconds", next.ReadHeaderTimeoutSeconds != prev.ReadHeaderTimeoutSeconds},
+		{"read-timeout-seconds", next.ReadTimeoutSeconds != prev.ReadTimeoutSeconds},
+		{"write-timeout-seconds", next.WriteTimeoutSeconds != prev.WriteTimeoutSeconds},
+		{"idle-timeout-seconds", next.IdleTimeoutSeconds != prev.IdleTimeoutSeconds},
+		{"max-header-bytes", next.MaxHeaderBytes != prev.MaxHeaderBytes},
+	} {
+		if s.changed {
+			logger.Warn("setting changed but applies only after a restart", "setting", s.name)
+		}
+	}
+	if prev.TLSCertFile != "" {
+		if err := loadTLSCertificate(prev.TLSCertFile, prev.TLSKeyFile); err != nil {
+			logger.Error("TLS certificate reload failed, keeping the current certificate", "error", err)
+		}
+	}
+	cfg := *prev
+	cfg.Tunables = next.Tunables
+	applyConfig(&cfg)
+	logger.Info("configuration reloaded", "path", next.ConfigPath)
+}
+
+// applyConfig puts cfg in effect.
+func applyConfig(cfg *Config) {
+	liveConfig.Store(cfg)
+	rateLimit.set(cfg.RateLimitPerMinute, cfg.RateLimitBurst)
+}
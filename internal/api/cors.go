@@ -0,0 +1,139 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCORSMethods = "GET, HEAD, POST, PUT, PATCH, DELETE"
+	defaultCORSMaxAge  = 10 * time.Minute
+)
+
+var defaultCORSHeaders = []string{
+	"Content-Type", "Authorization", apiKeyHeader, tenantHeader, "Idempotency-Key",
+	"If-Match", "If-None-Match", "If-Modified-Since", "X-Idempotent-Delete",
+	signatureHeader, signatureTimestampHeader, requestIDHeader,
+}
+
+// corsExposedHeaders are the response headers browsers may read besides
+// the CORS-safelisted ones.
+var corsExposedHeaders = []string{
+	"ETag", "Location", "Retry-After", "Content-Disposition", "Idempotent-Replayed",
+	"X-Cache", "X-Precomputed-At", requestIDHeader, quotaRemainingHeader, storageRemainingHeader, quotaWarningHeader,
+}
+
+// cors holds the CORS_* settings; no origins disables CORS handling.
+var cors = struct {
+	origins map[string]bool
+	any     bool
+	methods string
+	headers string
+	maxAge  time.Duration
+}{methods: defaultCORSMethods, headers: strings.Join(defaultCORSHeaders, ", "), maxAge: defaultCORSMaxAge}
+
+// splitList splits a comma-separated setting, dropping empty entries.
+func splitList(v string) []string {
+	out := []string{}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// configureCORS applies the CORS_* settings.
+func configureCORS() error {
+	if v := os.Getenv("CORS_ALLOWED_ORIGINS"); v != "" {
+		cors.origins = map[string]bool{}
+		for _, origin := range splitList(v) {
+			if origin == "*" {
+				cors.any = true
+				continue
+			}
+			if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+				return fmt.Errorf("invalid CORS_ALLOWED_ORIGINS entry %q", origin)
+			}
+			cors.origins[strings.TrimSuffix(origin, "/")] = true
+		}
+	}
+	if v := os.Getenv("CORS_ALLOWED_METHODS"); v != "" {
+		methods := splitList(v)
+		for i, m := range methods {
+			methods[i] = strings.ToUpper(m)
+		}
+		cors.methods = strings.Join(methods, ", ")
+	}
+	if v := os.Getenv("CORS_ALLOWED_HEADERS"); v != "" {
+		cors.headers = strings.Join(splitList(v), ", ")
+	}
+	if v := os.Getenv("CORS_MAX_AGE_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid CORS_MAX_AGE_SECONDS: %s", v)
+		}
+		cors.maxAge = time.Duration(n) * time.Second
+	}
+	return nil
+}
+
+func corsEnabled() bool {
+	return cors.any || len(cors.origins) > 0
+}
+
+// withCORS lets browser apps on the allowed origins call the API. Preflight
+// requests are answered here, before authentication, since browsers send
+// them without credentials.
+func withCORS(next http.Handler) http.Handler {
+	if !corsEnabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		h := w.Header()
+		if !cors.any {
+			h.Add("Vary", "Origin")
+		}
+		allowed := cors.any || cors.origins[origin]
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if !allowed {
+				writeForbidden(w, "ORIGIN_NOT_ALLOWED", "origin "+origin+" is not allowed", "", "")
+				return
+			}
+			h.Set("Access-Control-Allow-Origin", allowOrigin(origin))
+			h.Set("Access-Control-Allow-Methods", cors.methods)
+			if cors.headers == "*" {
+				h.Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+			} else {
+				h.Set("Access-Control-Allow-Headers", cors.headers)
+			}
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(cors.maxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if allowed {
+			h.Set("Access-Control-Allow-Origin", allowOrigin(origin))
+			h.Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func allowOrigin(origin string) string {
+	if cors.any {
+		return "*"
+	}
+	return origin
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	demoRequestsPerMinute = 60
+	demoMaxStrings        = 500
+	demoResetInterval     = time.Hour
+)
+
+var demoSeedValues = []string{
+	"racecar",
+	"hello world",
+	"A man a plan a canal Panama",
+	"Was it a car or a cat I saw",
+	"level",
+	"HNG internship",
+	"The quick brown fox jumps over the lazy dog",
+	"madam",
+	"Go is fun",
+	"noon",
+}
+
+func seedDemoData() {
+	for _, v := range demoSeedValues {
+		_, _ = insertString(v, "", defaultTenant, analysis.DefaultOptions(), 0, nil)
+	}
+}
+
+func resetStore() {
+	if err := db.Reset(); err != nil {
+		logger.Error("demo store reset failed", "error", err)
+	}
+}
+
+func startDemoMode() {
+	store.MaxRecords = demoMaxStrings
+	seedDemoData()
+	every(demoResetInterval, func() {
+		resetStore()
+		seedDemoData()
+		logger.Info("demo store reset")
+	})
+}
@@ -0,0 +1,347 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	hashingDimensions    = 256
+	embeddingBatchSize   = 64
+	defaultSemanticLimit = 10
+	maxSemanticLimit     = 100
+)
+
+// embedder turns texts into vectors. remote embedders are called off the
+// write path, since they may be slow or unavailable.
+type embedder interface {
+	embed(texts []string) ([][]float32, error)
+	remote() bool
+}
+
+// hashingEmbedder is the built-in local model: word and character trigram
+// features hashed into a fixed number of dimensions. It finds strings that
+// share words and word fragments; an external model captures meaning.
+type hashingEmbedder struct{}
+
+func (hashingEmbedder) remote() bool { return false }
+
+func (hashingEmbedder) embed(texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, hashingDimensions)
+		add := func(feature string, weight float32) {
+			h := fnv.New32a()
+			h.Write([]byte(feature))
+			sum := h.Sum32()
+			if sum&1 == 0 {
+				weight = -weight
+			}
+			v[(sum>>1)%hashingDimensions] += weight
+		}
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		}) {
+			add("w:"+word, 1)
+			runes := []rune("^" + word + "$")
+			for j := 0; j+3 <= len(runes); j++ {
+				add("c:"+string(runes[j:j+3]), 0.5)
+			}
+		}
+		out[i] = normalizeVector(v)
+	}
+	return out, nil
+}
+
+// httpEmbedder calls an OpenAI-compatible embeddings endpoint.
+type httpEmbedder struct {
+	url    string
+	model  string
+	apiKey string
+	client *http.Client
+}
+
+func (httpEmbedder) remote() bool { return true }
+
+func (e httpEmbedder) embed(texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": e.model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("embedding service returned %s", resp.Status)
+	}
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, errors.New("embedding service returned an out of range index")
+		}
+		vectors[d.Index] = normalizeVector(d.Embedding)
+	}
+	for _, v := range vectors {
+		if v == nil {
+			return nil, errors.New("embedding service returned too few embeddings")
+		}
+	}
+	return vectors, nil
+}
+
+func normalizeVector(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+// dot is the cosine similarity of two normalized vectors; vectors of
+// different models or sizes score 0.
+func dot(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var s float64
+	for i := range a {
+		s += float64(a[i]) * float64(b[i])
+	}
+	return s
+}
+
+// vectorIndex holds one normalized embedding per record and searches them
+// exhaustively. Records waiting for a remote embedder are kept in pending;
+// tenants maps every record to its tenant so searches stay within one.
+type vectorIndex struct {
+	sync.RWMutex
+	embedder  embedder
+	vectors   map[string][]float32
+	values    map[string]string
+	pending   map[string]string
+	tenants   map[string]string
+	wake      chan struct{}
+	failures  int
+	lastError string
+}
+
+var vectors = &vectorIndex{vectors: map[string][]float32{}, values: map[string]string{}, pending: map[string]string{}, tenants: map[string]string{}, wake: make(chan struct{}, 1)}
+
+type semanticMatch struct {
+	ID    string  `json:"id"`
+	Value string  `json:"value"`
+	Score float64 `json:"score"`
+}
+
+func (x *vectorIndex) add(item store.StoredString) {
+	if !x.embedder.remote() {
+		vs, _ := x.embedder.embed([]string{item.Value})
+		x.Lock()
+		x.vectors[item.ID], x.values[item.ID] = vs[0], item.Value
+		x.tenants[item.ID] = storedTenant(item)
+		x.Unlock()
+		return
+	}
+	x.Lock()
+	x.pending[item.ID] = item.Value
+	x.tenants[item.ID] = storedTenant(item)
+	x.Unlock()
+	select {
+	case x.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (x *vectorIndex) remove(item store.StoredString) {
+	x.Lock()
+	defer x.Unlock()
+	delete(x.vectors, item.ID)
+	delete(x.values, item.ID)
+	delete(x.pending, item.ID)
+	delete(x.tenants, item.ID)
+}
+
+func (x *vectorIndex) rebuild(items []store.StoredString) {
+	x.Lock()
+	x.vectors, x.values, x.pending, x.tenants = map[string][]float32{}, map[string]string{}, map[string]string{}, map[string]string{}
+	x.Unlock()
+	for _, item := range items {
+		x.add(item)
+	}
+}
+
+// runEmbedder embeds pending records in batches for a remote embedder,
+// retrying after a failure.
+func (x *vectorIndex) runEmbedder() {
+	for {
+		x.Lock()
+		ids := make([]string, 0, embeddingBatchSize)
+		texts := make([]string, 0, embeddingBatchSize)
+		for id, value := range x.pending {
+			if len(ids) == embeddingBatchSize {
+				break
+			}
+			ids, texts = append(ids, id), append(texts, value)
+		}
+		x.Unlock()
+		if len(ids) == 0 {
+			<-x.wake
+			continue
+		}
+		vs, err := x.embedder.embed(texts)
+		x.Lock()
+		if err != nil {
+			x.failures++
+			x.lastError = err.Error()
+			x.Unlock()
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		for i, id := range ids {
+			if value, ok := x.pending[id]; ok && value == texts[i] {
+				delete(x.pending, id)
+				x.vectors[id], x.values[id] = vs[i], value
+			}
+		}
+		x.Unlock()
+	}
+}
+
+func (x *vectorIndex) search(tenant string, query []float32, limit int) []semanticMatch {
+	x.RLock()
+	defer x.RUnlock()
+	matches := make([]semanticMatch, 0, len(x.vectors))
+	for id, v := range x.vectors {
+		if x.tenants[id] != tenant {
+			continue
+		}
+		matches = append(matches, semanticMatch{ID: id, Value: x.values[id], Score: dot(query, v)})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Value < matches[j].Value
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func (x *vectorIndex) details() map[string]interface{} {
+	x.RLock()
+	defer x.RUnlock()
+	return map[string]interface{}{"pending": len(x.pending), "embedding_failures": x.failures, "last_error": x.lastError}
+}
+
+// configureEmbeddings sets up the embedder named by EMBEDDING_PROVIDER and
+// registers the vectors index. It must run before the store is opened so
+// the index is built from existing records.
+func configureEmbeddings() error {
+	switch provider := os.Getenv("EMBEDDING_PROVIDER"); provider {
+	case "":
+		return nil
+	case "hashing":
+		vectors.embedder = hashingEmbedder{}
+	case "http":
+		e := httpEmbedder{url: os.Getenv("EMBEDDING_URL"), model: os.Getenv("EMBEDDING_MODEL"), apiKey: os.Getenv("EMBEDDING_API_KEY"), client: &http.Client{Timeout: 30 * time.Second}}
+		if e.url == "" {
+			return errors.New("EMBEDDING_PROVIDER=http requires EMBEDDING_URL")
+		}
+		vectors.embedder = e
+		go vectors.runEmbedder()
+	default:
+		return fmt.Errorf("invalid EMBEDDING_PROVIDER: %s", provider)
+	}
+	indexRegistry.Lock()
+	defer indexRegistry.Unlock()
+	indexRegistry.list = append(indexRegistry.list, &managedIndex{
+		name:        "vectors",
+		description: "Embeddings behind GET /strings/semantic-search.",
+		add:         vectors.add,
+		remove:      vectors.remove,
+		rebuild:     vectors.rebuild,
+		size: func() int {
+			vectors.RLock()
+			defer vectors.RUnlock()
+			return len(vectors.vectors)
+		},
+		details: vectors.details,
+		enabled: true,
+	})
+	return nil
+}
+
+var semanticParams = paramSchema{
+	requiredParam("q"),
+	intParam("limit", 1, maxSemanticLimit, defaultSemanticLimit),
+}
+
+func semanticSearchHandler(w http.ResponseWriter, r *http.Request) {
+	if vectors.embedder == nil {
+		writeError(w, http.StatusServiceUnavailable, "NOT_CONFIGURED", "semantic search is not configured, set EMBEDDING_PROVIDER")
+		return
+	}
+	if !indexEnabled("vectors") {
+		writeIndexDisabled(w, "vectors")
+		return
+	}
+	var errs paramErrors
+	p := semanticParams.parse(r.URL.Query(), &errs)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	query, limit := p.str("q"), p.int("limit")
+	qv, err := vectors.embedder.embed([]string{query})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "UPSTREAM_ERROR", "unable to embed query: "+err.Error())
+		return
+	}
+	matches := vectors.search(tenantOf(r), qv[0], limit)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  matches,
+		"count": len(matches),
+		"query": query,
+	})
+}
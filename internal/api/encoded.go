@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+// encodedRecords caches the JSON encoding of each record, so listings can
+// splice the stored bytes together instead of marshalling every record on
+// every request. Entries are dropped by the index hooks whenever a record
+// changes and share the listing cache's disabled flag.
+var encodedRecords = struct {
+	sync.RWMutex
+	m map[string][]byte
+}{m: map[string][]byte{}}
+
+// encodeRecord returns the cached encoding of item, marshalling and caching
+// it on a miss. version is the storeVersion observed before item was read;
+// if the store has changed since, the encoding is returned but not cached,
+// as item may already be stale.
+func encodeRecord(item store.StoredString, version uint64) ([]byte, error) {
+	encodedRecords.RLock()
+	data, ok := encodedRecords.m[item.ID]
+	encodedRecords.RUnlock()
+	if ok {
+		return data, nil
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	encodedRecords.Lock()
+	if !listCache.disabled && version == storeVersion.Load() {
+		encodedRecords.m[item.ID] = data
+	}
+	encodedRecords.Unlock()
+	return data, nil
+}
+
+// forgetEncoded runs after storeVersion is bumped, so an encoding of the old
+// record cached concurrently is either rejected by encodeRecord's version
+// check or removed here.
+func forgetEncoded(id string) {
+	encodedRecords.Lock()
+	delete(encodedRecords.m, id)
+	encodedRecords.Unlock()
+}
+
+func forgetAllEncoded() {
+	encodedRecords.Lock()
+	encodedRecords.m = map[string][]byte{}
+	encodedRecords.Unlock()
+}
+
+// writeRecordListing writes resp with records, reduced to fields when that is
+// set, under "data". It produces the same document as writeJSON with records
+// added to resp, but copies each record's cached encoding into a single
+// preallocated buffer.
+func writeRecordListing(w http.ResponseWriter, code int, records []store.StoredString, fields fieldSet, version uint64, resp map[string]interface{}) {
+	rest, err := json.Marshal(resp)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "unable to encode response")
+		return
+	}
+	encoded := make([][]byte, len(records))
+	size := len(rest) + len(records) + 16
+	for i, item := range records {
+		encoded[i], err = encodeRecord(item, version)
+		if err == nil && fields != nil {
+			encoded[i], err = fields.project(encoded[i])
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "unable to encode response")
+			return
+		}
+		size += len(encoded[i])
+	}
+	buf := make([]byte, 0, size)
+	buf = append(buf, `{"data":[`...)
+	for i, data := range encoded {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, data...)
+	}
+	buf = append(buf, ']')
+	if len(rest) > 2 {
+		buf = append(buf, ',')
+	}
+	buf = append(buf, rest[1:]...)
+	buf = append(buf, '\n')
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(buf)
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	enrichmentQueueSize  = 1024
+	enrichmentMaxBackoff = time.Minute
+	maxEnrichmentBody    = 1 << 20
+)
+
+const (
+	enrichmentPending   = "pending"
+	enrichmentSucceeded = "succeeded"
+	enrichmentFailed    = "failed"
+)
+
+var enrichment = struct {
+	sync.Mutex
+	url         string
+	client      *http.Client
+	maxAttempts int
+	queue       chan string
+	succeeded   int
+	failed      int
+	retries     int
+	lastError   string
+}{client: &http.Client{Timeout: 10 * time.Second}, maxAttempts: 5}
+
+func enrichmentEnabled() bool {
+	return enrichment.url != ""
+}
+
+// startEnrichment posts every new record to ENRICHMENT_URL when it is set.
+// Records still pending from before a restart are queued again.
+func startEnrichment() error {
+	url := os.Getenv("ENRICHMENT_URL")
+	if url == "" {
+		return nil
+	}
+	if v := os.Getenv("ENRICHMENT_TIMEOUT_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid ENRICHMENT_TIMEOUT_SECONDS: %s", v)
+		}
+		enrichment.client.Timeout = time.Duration(n) * time.Second
+	}
+	if v := os.Getenv("ENRICHMENT_MAX_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid ENRICHMENT_MAX_ATTEMPTS: %s", v)
+		}
+		enrichment.maxAttempts = n
+	}
+	enrichment.url = url
+	enrichment.queue = make(chan string, enrichmentQueueSize)
+	pending, err := db.Filter(func(item store.StoredString) bool {
+		return item.Enrichment != nil && item.Enrichment.Status == enrichmentPending
+	})
+	if err != nil {
+		return err
+	}
+	queue := enrichment.queue
+	goBackground(func(ctx context.Context) { runEnrichment(ctx, queue) })
+	goBackground(func(ctx context.Context) {
+		for _, item := range pending {
+			select {
+			case queue <- item.ID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// queueEnrichment hands a newly stored record to the enrichment worker. A
+// full queue fails the record's enrichment rather than blocking ingest.
+func queueEnrichment(item store.StoredString) {
+	select {
+	case enrichment.queue <- item.ID:
+	default:
+		_ = updateEnrichment(item.ID, func(e *store.Enrichment) {
+			e.Status = enrichmentFailed
+			e.LastError = "enrichment queue full"
+		})
+		enrichment.Lock()
+		enrichment.failed++
+		enrichment.Unlock()
+	}
+}
+
+// updateEnrichment applies change to the current enrichment of the record
+// id, so fields updated meanwhile by other writers are kept.
+func updateEnrichment(id string, change func(*store.Enrichment)) error {
+	_, err := updateRecord(id, nil, func(item *store.StoredString) error {
+		e := store.Enrichment{Status: enrichmentPending}
+		if item.Enrichment != nil {
+			e = *item.Enrichment
+		}
+		change(&e)
+		e.UpdatedAt = store.Now()
+		item.Enrichment = &e
+		return nil
+	})
+	return err
+}
+
+// requestEnrichment posts item and returns the extra properties the service
+// answers with, a JSON object.
+func requestEnrichment(item store.StoredString) (map[string]json.RawMessage, error) {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := enrichment.client.Post(enrichment.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("enrichment service returned %s", resp.Status)
+	}
+	var props map[string]json.RawMessage
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxEnrichmentBody)).Decode(&props); err != nil || props == nil {
+		return nil, errors.New("enrichment service must answer with a JSON object")
+	}
+	return props, nil
+}
+
+// runEnrichment enriches queued records one at a time, retrying failed
+// deliveries with exponential backoff up to maxAttempts. Records still
+// pending at shutdown are queued again on the next start.
+func runEnrichment(ctx context.Context, queue <-chan string) {
+	for {
+		var id string
+		select {
+		case <-ctx.Done():
+			return
+		case id = <-queue:
+		}
+		var backoff time.Duration
+		for {
+			item, err := db.Get(id)
+			if err != nil || item.Enrichment == nil || item.Enrichment.Status != enrichmentPending {
+				break
+			}
+			props, err := requestEnrichment(item)
+			attempts := item.Enrichment.Attempts + 1
+			if err == nil {
+				_ = updateEnrichment(id, func(e *store.Enrichment) {
+					e.Status, e.Attempts, e.LastError, e.Properties = enrichmentSucceeded, attempts, "", props
+				})
+				enrichment.Lock()
+				enrichment.succeeded++
+				enrichment.Unlock()
+				break
+			}
+			status := enrichmentPending
+			if attempts >= enrichment.maxAttempts {
+				status = enrichmentFailed
+			}
+			_ = updateEnrichment(id, func(e *store.Enrichment) {
+				e.Status, e.Attempts, e.LastError = status, attempts, err.Error()
+			})
+			enrichment.Lock()
+			enrichment.lastError = err.Error()
+			if status == enrichmentFailed {
+				enrichment.failed++
+			} else {
+				enrichment.retries++
+			}
+			enrichment.Unlock()
+			if status == enrichmentFailed {
+				break
+			}
+			backoff = min(max(2*backoff, time.Second), enrichmentMaxBackoff)
+			if !sleepContext(ctx, backoff) {
+				return
+			}
+		}
+	}
+}
+
+func enrichmentStatusHandler(w http.ResponseWriter, r *http.Request) {
+	enrichment.Lock()
+	defer enrichment.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"enabled":      enrichmentEnabled(),
+		"queued":       len(enrichment.queue),
+		"succeeded":    enrichment.succeeded,
+		"failed":       enrichment.failed,
+		"retries":      enrichment.retries,
+		"max_attempts": enrichment.maxAttempts,
+		"last_error":   enrichment.lastError,
+	})
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+)
+
+// apiError is the body of every error response, under "error". Code is
+// stable, so clients can branch on it; Message is meant for people and may
+// change. Details, when set, carries the specifics of the code, such as the
+// invalid parameters of INVALID_PARAMETER. RequestID names the request.
+type apiError struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+type errorEnvelope struct {
+	Error apiError `json:"error"`
+}
+
+// writeError answers status with the error envelope.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeErrorDetails(w, status, code, message, nil)
+}
+
+// writeErrorDetails answers status with the error envelope, naming the
+// request by the X-Request-ID withRequestID set on the response.
+func writeErrorDetails(w http.ResponseWriter, status int, code, message string, details interface{}) {
+	writeJSON(w, status, errorEnvelope{apiError{Code: code, Message: message, Details: details, RequestID: w.Header().Get(requestIDHeader)}})
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etagMatches reports whether an If-None-Match header lists etag, using the
+// weak comparison the header calls for.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// withETag tags successful GET responses with the SHA-256 of their body and
+// answers 304 Not Modified when If-None-Match already names it, so polling
+// clients skip unchanged analyses. If-Modified-Since is honoured, when
+// If-None-Match is absent, for responses that set Last-Modified. Hashing the final body rather than the
+// record keeps the tag distinct per fields or project selection. HEAD is
+// served as a GET whose body is dropped, keeping Content-Length and ETag.
+func withETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		head := r.Method == http.MethodHead
+		if r.Method != http.MethodGet && !head {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if head {
+			r = r.Clone(r.Context())
+			r.Method = http.MethodGet
+		}
+		buf := newBufferedResponse(w)
+		next.ServeHTTP(buf, r)
+		if buf.code == 0 || buf.code == http.StatusOK {
+			sum := sha256.Sum256(buf.body.Bytes())
+			etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+			buf.header.Set("ETag", etag)
+			inm := r.Header.Get("If-None-Match")
+			notModified := inm != "" && etagMatches(inm, etag)
+			if ims := r.Header.Get("If-Modified-Since"); inm == "" && ims != "" {
+				notModified = notModifiedSince(buf.header.Get("Last-Modified"), ims)
+			}
+			if notModified {
+				buf.header.Del("Content-Type")
+				buf.header.Del("Content-Length")
+				copyHeader(w.Header(), buf.header)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		buf.header.Set("Content-Length", strconv.Itoa(buf.body.Len()))
+		if !head {
+			buf.flushTo(w)
+			return
+		}
+		copyHeader(w.Header(), buf.header)
+		if buf.code == 0 {
+			buf.code = http.StatusOK
+		}
+		w.WriteHeader(buf.code)
+	})
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	defaultEventLimit      = 100
+	maxEventLimit          = 1000
+	defaultEventMaxEntries = 100000
+)
+
+// eventVisibleTo reports whether the tenant may read e. Resets empty every
+// tenant's strings, so all of them see those.
+func eventVisibleTo(e store.Event, tenant string) bool {
+	if e.Type == "reset" {
+		return true
+	}
+	if e.Tenant == "" {
+		return tenant == defaultTenant
+	}
+	return e.Tenant == tenant
+}
+
+// events is the change stream, appended to EVENTS_PATH when that is set so
+// it outlives restarts. Only the newest max events are kept, except those
+// after held, which the webhook dispatcher has yet to deliver; the log is
+// rewritten with the kept events once it holds twice as many lines.
+var events = struct {
+	sync.Mutex
+	list    []store.Event
+	f       *os.File
+	max     int   // 0 keeps every event
+	logged  int   // lines in f
+	last    int64 // cursor of the newest event, even once purged
+	dropped int64 // cursor of the newest event dropped for max
+	held    int64
+}{max: defaultEventMaxEntries, held: math.MaxInt64}
+
+func openEventLog(path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	err = store.ReadLogLines(f, func(n int, line []byte) error {
+		var e store.Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return err
+		}
+		events.list = append(events.list, e)
+		events.last = e.Cursor
+		events.logged = n
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	events.f = f
+	// The log starts after the events an earlier process dropped.
+	if len(events.list) > 0 {
+		events.dropped = events.list[0].Cursor - 1
+	}
+	return nil
+}
+
+// trimEvents drops the oldest events beyond events.max that are not held
+// for delivery, and compacts the log once it has grown to twice that.
+// Callers hold events.Mutex.
+func trimEvents() {
+	if events.max <= 0 || len(events.list) <= events.max {
+		return
+	}
+	drop := len(events.list) - events.max
+	drop = min(drop, sort.Search(len(events.list), func(i int) bool { return events.list[i].Cursor > events.held }))
+	if drop == 0 {
+		return
+	}
+	events.dropped = events.list[drop-1].Cursor
+	events.list = events.list[drop:]
+	if events.f == nil || events.logged < 2*events.max {
+		return
+	}
+	f, err := rewriteLog(events.f, events.list)
+	if err != nil {
+		logger.Error("event log compaction failed", "error", err)
+		return
+	}
+	events.f, events.logged = f, len(events.list)
+}
+
+// recordEvent is called by indexedStorage under its write lock, so events
+// are numbered in the order the backend applied the changes.
+func recordEvent(typ, tenant string, item *store.StoredString, id string) {
+	events.Lock()
+	defer events.Unlock()
+	events.last++
+	e := store.Event{Cursor: events.last, Type: typ, ID: id, Tenant: tenant, Record: item, At: store.Now()}
+	events.list = append(events.list, e)
+	notifyWebhooks()
+	defer trimEvents()
+	if events.f == nil {
+		return
+	}
+	line, err := json.Marshal(e)
+	if err == nil {
+		_, err = events.f.Write(append(line, '\n'))
+	}
+	if err != nil {
+		logger.Error("event log append failed", "error", err)
+		return
+	}
+	events.logged++
+}
+
+var eventParams = paramSchema{
+	intParam("since", 0, math.MaxInt, 0),
+	intParam("limit", 1, maxEventLimit, defaultEventLimit),
+}
+
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	var errs paramErrors
+	p := eventParams.parse(r.URL.Query(), &errs)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	since, limit := int64(p.int("since")), p.int("limit")
+	tenant := tenantOf(r)
+	page := []store.Event{}
+	next := since
+	hasMore := false
+	events.Lock()
+	if since > 0 && since < events.dropped {
+		events.Unlock()
+		writeError(w, http.StatusGone, "EVENTS_EXPIRED", "events after this cursor are no longer kept; resync from an export")
+		return
+	}
+	start := sort.Search(len(events.list), func(i int) bool { return events.list[i].Cursor > since })
+	for _, e := range events.list[start:] {
+		if !eventVisibleTo(e, tenant) {
+			continue
+		}
+		if len(page) == limit {
+			hasMore = true
+			break
+		}
+		page = append(page, e)
+		next = e.Cursor
+	}
+	events.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":        page,
+		"count":       len(page),
+		"next_cursor": next,
+		"has_more":    hasMore,
+	})
+}
+
+// purgeEvents drops the create and update events of ids, which carry their
+// contents, and rewrites EVENTS_PATH without them.
+func purgeEvents(ids map[string]bool) (int, error) {
+	events.Lock()
+	defer events.Unlock()
+	kept := events.list[:0]
+	for _, e := range events.list {
+		if e.Record != nil && ids[e.ID] {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	removed := len(events.list) - len(kept)
+	events.list = kept
+	if removed == 0 || events.f == nil {
+		return removed, nil
+	}
+	f, err := rewriteLog(events.f, kept)
+	if err != nil {
+		return removed, err
+	}
+	events.f, events.logged = f, len(kept)
+	return removed, nil
+}
+
+// rewriteLog replaces the JSON lines log f with entries and returns the
+// reopened log, closing f.
+func rewriteLog[T any](f *os.File, entries []T) (*os.File, error) {
+	path := f.Name()
+	tmp := path + ".tmp"
+	var buf []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	if err := os.WriteFile(tmp, buf, 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+	nf, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return nf, nil
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+// useEventLog gives the test an empty change stream logged to a temporary
+// file and keeping the newest keep events, restoring the previous stream after it.
+func useEventLog(t *testing.T, keep int) string {
+	t.Helper()
+	events.Lock()
+	list, f, maxEntries, logged := events.list, events.f, events.max, events.logged
+	last, dropped, held := events.last, events.dropped, events.held
+	events.list, events.f, events.max, events.logged, events.last, events.dropped, events.held = nil, nil, keep, 0, 0, 0, math.MaxInt64
+	events.Unlock()
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := openEventLog(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		events.Lock()
+		defer events.Unlock()
+		store.SyncLog(&events.f)
+		events.list, events.f, events.max, events.logged = list, f, maxEntries, logged
+		events.last, events.dropped, events.held = last, dropped, held
+	})
+	return path
+}
+
+func logLines(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "\n")
+}
+
+func TestEventRetention(t *testing.T) {
+	path := useEventLog(t, 3)
+	for i := 0; i < 5; i++ {
+		recordEvent("delete", "", nil, "x")
+	}
+	if len(events.list) != 3 || events.list[0].Cursor != 3 || events.dropped != 2 {
+		t.Fatalf("kept cursors from %d, %d events, dropped through %d", events.list[0].Cursor, len(events.list), events.dropped)
+	}
+	if n := logLines(t, path); n != 5 {
+		t.Fatalf("log has %d lines before compaction, want 5", n)
+	}
+	recordEvent("delete", "", nil, "x")
+	if n := logLines(t, path); n != 3 {
+		t.Fatalf("log has %d lines after compaction, want 3", n)
+	}
+	for since, want := range map[string]int{"0": http.StatusOK, "2": http.StatusGone, "3": http.StatusOK} {
+		w := httptest.NewRecorder()
+		eventsHandler(w, httptest.NewRequest(http.MethodGet, "/events?since="+since, nil))
+		if w.Code != want {
+			t.Errorf("since=%s: status %d, want %d", since, w.Code, want)
+		}
+	}
+}
+
+func TestEventRetentionKeepsUndelivered(t *testing.T) {
+	useEventLog(t, 2)
+	events.held = 1
+	for i := 0; i < 5; i++ {
+		recordEvent("delete", "", nil, "x")
+	}
+	if len(events.list) != 4 || events.list[0].Cursor != 2 {
+		t.Fatalf("kept %d events from cursor %d, want the 4 after the delivered one", len(events.list), events.list[0].Cursor)
+	}
+	events.Lock()
+	events.held = 5
+	events.Unlock()
+	recordEvent("delete", "", nil, "x")
+	if len(events.list) != 2 || events.list[0].Cursor != 5 {
+		t.Fatalf("kept %d events from cursor %d after delivery, want 2 from 5", len(events.list), events.list[0].Cursor)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const defaultExpirySweepInterval = time.Minute
+
+// errStringExpired is returned for a string whose ttl_seconds has passed but
+// that the sweeper has not removed yet; it answers 410 Gone. It also matches
+// store.ErrNotFound, so callers that treat a missing string specially treat
+// an expired one the same way.
+var errStringExpired error = expiredError{}
+
+type expiredError struct{}
+
+func (expiredError) Error() string        { return "string has expired" }
+func (expiredError) Is(target error) bool { return target == store.ErrNotFound }
+
+// parseTTL validates a ttl_seconds value. Without one records never expire.
+func parseTTL(seconds *int) (time.Duration, error) {
+	if seconds == nil {
+		return 0, nil
+	}
+	if *seconds < 1 {
+		return 0, errors.New("ttl_seconds must be a positive integer")
+	}
+	return time.Duration(*seconds) * time.Second, nil
+}
+
+// queryTTL reads ttl_seconds from the query string of raw and multipart
+// uploads, which have no JSON body to carry it.
+func queryTTL(r *http.Request) (time.Duration, error) {
+	v := r.URL.Query().Get("ttl_seconds")
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ttl_seconds %q", v)
+	}
+	return parseTTL(&n)
+}
+
+// sweepExpired hard-deletes every record whose TTL has passed.
+func sweepExpired() (int, error) {
+	now := time.Now()
+	items, err := backend().Filter(func(item store.StoredString) bool { return item.Expired(now) })
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, item := range items {
+		if _, err := db.Delete(item.ID); err == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// startExpirySweeper removes expired records every interval. Until then they
+// answer 410 and are left out of listings built from the store, though
+// indexes and cached listings may still include them.
+func startExpirySweeper(interval time.Duration) {
+	every(interval, func() {
+		if _, err := sweepExpired(); err != nil {
+			logger.Error("expiry sweep failed", "error", err)
+		}
+	})
+}
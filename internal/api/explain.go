@@ -0,0 +1,119 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+type filterTiming struct {
+	Filter     string  `json:"filter"`
+	Evaluated  int     `json:"evaluated"`
+	Matched    int     `json:"matched"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// queryExplain describes how a listing or search request was answered.
+type queryExplain struct {
+	Strategy    string          `json:"strategy"`
+	IndexesUsed []string        `json:"indexes_used"`
+	Scanned     int             `json:"scanned"`
+	Matched     int             `json:"matched"`
+	Filters     []*filterTiming `json:"filters"`
+	DurationMs  float64         `json:"duration_ms"`
+}
+
+func newQueryExplain(strategy string, indexes ...string) *queryExplain {
+	if indexes == nil {
+		indexes = []string{}
+	}
+	return &queryExplain{Strategy: strategy, IndexesUsed: indexes, Filters: []*filterTiming{}}
+}
+
+func millisSince(start time.Time) float64 {
+	return float64(time.Since(start).Nanoseconds()) / 1e6
+}
+
+func (ex *queryExplain) timing(name string) *filterTiming {
+	t := &filterTiming{Filter: name}
+	ex.Filters = append(ex.Filters, t)
+	return t
+}
+
+func (t *filterTiming) eval(match func() bool) bool {
+	start := time.Now()
+	ok := match()
+	t.DurationMs += millisSince(start)
+	t.Evaluated++
+	if ok {
+		t.Matched++
+	}
+	return ok
+}
+
+// instrumented returns a matcher equivalent to fs.matches that records, per
+// condition, how many records reached it, how many passed and the time spent.
+// Conditions in OR groups are reported as "or[i].name".
+func (fs filterSet) instrumented(ex *queryExplain) func(store.StoredString) bool {
+	and := make([]*filterTiming, len(fs.and))
+	for i, c := range fs.and {
+		and[i] = ex.timing(c.spec.name)
+	}
+	or := make([][]*filterTiming, len(fs.or))
+	for i, group := range fs.or {
+		for _, c := range group {
+			or[i] = append(or[i], ex.timing(fmt.Sprintf("or[%d].%s", i, c.spec.name)))
+		}
+	}
+	return func(item store.StoredString) bool {
+		ex.Scanned++
+		if fs.tenant != "" && storedTenant(item) != fs.tenant {
+			return false
+		}
+		for i, c := range fs.and {
+			if !and[i].eval(func() bool { return c.spec.match(item, c.value) }) {
+				return false
+			}
+		}
+		for i, group := range fs.or {
+			ok := false
+			for j, c := range group {
+				if or[i][j].eval(func() bool { return c.spec.match(item, c.value) }) {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				return false
+			}
+		}
+		ex.Matched++
+		return true
+	}
+}
+
+// explainFilterRecords runs fs like filterRecords while collecting explain
+// data. Backends with filter pushdown only report the rows they returned.
+func explainFilterRecords(fs filterSet, includeDeleted bool) ([]store.StoredString, *queryExplain, error) {
+	start := time.Now()
+	if p, ok := backendPushdown(); ok {
+		ex := newQueryExplain("pushdown", "backend")
+		results, err := p.FilterQuery(fs.query())
+		ex.Scanned = len(results)
+		if err == nil && !includeDeleted {
+			results = liveRecords(results)
+		}
+		ex.Matched = len(results)
+		ex.DurationMs = millisSince(start)
+		return results, ex, err
+	}
+	ex := newQueryExplain("full_scan")
+	src := db
+	if includeDeleted {
+		src = backend()
+	}
+	results, err := src.Filter(fs.instrumented(ex))
+	ex.DurationMs = millisSince(start)
+	return results, ex, err
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+var exportParams = append(paramSchema{enumParam("format", "csv", "csv")}, sortParams...)
+
+var exportColumns = []string{"value", "length", "word_count", "is_palindrome", "unique_characters", "sha256", "created_at"}
+
+func exportRow(item store.StoredString) []string {
+	p := item.Properties
+	return []string{
+		item.Value,
+		strconv.Itoa(p.Length),
+		strconv.Itoa(p.WordCount),
+		strconv.FormatBool(p.IsPalindrome),
+		strconv.Itoa(p.UniqueCharacters),
+		p.SHA256Hash,
+		store.FormatTime(item.CreatedAt.Time),
+	}
+}
+
+// exportHandler writes every string matching the listing filters as CSV,
+// one row per string under a header row.
+func exportHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var errs paramErrors
+	p := exportParams.parse(q, &errs)
+	filters, err := parseFilterSet(q)
+	errs.add("", err)
+	filters.tenant = tenantOf(r)
+	sortBy, err := parseSortSpec(p)
+	errs.add("order", err)
+	if sortBy == nil {
+		sortBy = defaultSort
+	}
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	results, err := filterRecords(r.Context(), filters, false)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	sortStrings(results, sortBy)
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="strings.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write(exportColumns)
+	for _, item := range results {
+		_ = cw.Write(exportRow(item))
+	}
+	cw.Flush()
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+)
+
+const (
+	defaultFetchMaxBytes = 10 << 20
+	maxFetchRedirects    = 5
+)
+
+var (
+	errFetchTooLarge      = errors.New("remote content exceeds the size limit")
+	errFetchPrivateTarget = errors.New("url resolves to an address that is not publicly routable")
+)
+
+// urlFetch holds the limits of POST /strings/from-url. Unless private
+// networks are allowed, connections are only made to publicly routable
+// unicast addresses, checked after DNS resolution and on redirects, so the
+// endpoint cannot reach internal services.
+var urlFetch = struct {
+	schemes      []string
+	maxBytes     int64
+	allowPrivate bool
+	client       *http.Client
+}{schemes: []string{"http", "https"}, maxBytes: defaultFetchMaxBytes}
+
+// nonPublicPrefixes are the unicast ranges of the IANA special-purpose
+// address registries that are not globally reachable, or that embed IPv4
+// addresses which may be, beyond the private and link-local ones netip
+// already reports.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("3fff::/20"),
+	netip.MustParsePrefix("5f00::/16"),
+}
+
+// publicAddress reports whether ip is a globally routable unicast address,
+// the only kind fetched from unless private networks are allowed.
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap().WithZone("")
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkFetchURL(u *url.URL) error {
+	if !slices.Contains(urlFetch.schemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("url scheme must be one of %s", strings.Join(urlFetch.schemes, ", "))
+	}
+	if u.Host == "" {
+		return errors.New("url must have a host")
+	}
+	return nil
+}
+
+// configureURLFetch applies the FETCH_* settings.
+func configureURLFetch() error {
+	timeout := 10 * time.Second
+	if v := os.Getenv("FETCH_TIMEOUT_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid FETCH_TIMEOUT_SECONDS: %s", v)
+		}
+		timeout = time.Duration(n) * time.Second
+	}
+	if v := os.Getenv("FETCH_MAX_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid FETCH_MAX_BYTES: %s", v)
+		}
+		urlFetch.maxBytes = n
+	}
+	if v := os.Getenv("FETCH_ALLOWED_SCHEMES"); v != "" {
+		urlFetch.schemes = nil
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+				urlFetch.schemes = append(urlFetch.schemes, s)
+			}
+		}
+	}
+	if v := os.Getenv("FETCH_ALLOW_PRIVATE_NETWORKS"); v != "" {
+		b, err := parseBoolParam(strings.ToLower(v))
+		if err != nil {
+			return fmt.Errorf("invalid FETCH_ALLOW_PRIVATE_NETWORKS: %s", v)
+		}
+		urlFetch.allowPrivate = b
+	}
+	dialer := &net.Dialer{Timeout: timeout, Control: func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip, err := netip.ParseAddr(host); !urlFetch.allowPrivate && (err != nil || !publicAddress(ip)) {
+			return errFetchPrivateTarget
+		}
+		return nil
+	}}
+	urlFetch.client = &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return errors.New("too many redirects")
+			}
+			return checkFetchURL(req.URL)
+		},
+	}
+	return nil
+}
+
+// limitedBody fails once more than n bytes have been read, rather than
+// silently truncating like io.LimitReader.
+type limitedBody struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, errFetchTooLarge
+	}
+	return n, err
+}
+
+type fromURLReq struct {
+	URL             string   `json:"url"`
+	CaseInsensitive *bool    `json:"case_insensitive"`
+	Collection      string   `json:"collection"`
+	Language        string   `json:"language"`
+	TTLSeconds      *int     `json:"ttl_seconds"`
+	Tags            []string `json:"tags"`
+}
+
+// fromURLHandler fetches the text at a URL and stores its analysis.
+func fromURLHandler(w http.ResponseWriter, r *http.Request) {
+	var body fromURLReq
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if body.URL == "" {
+		writeError(w, http.StatusBadRequest, "MISSING_URL", `missing "url" field`)
+		return
+	}
+	u, err := url.Parse(body.URL)
+	if err == nil {
+		err = checkFetchURL(u)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_URL", "invalid url: "+err.Error())
+		return
+	}
+	collection, opts, err := createOptions(r, CreateReq{CaseInsensitive: body.CaseInsensitive, Collection: body.Collection, Language: body.Language})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_OPTIONS", err.Error())
+		return
+	}
+	ttl, err := parseTTL(body.TTLSeconds)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TTL", err.Error())
+		return
+	}
+	tags, err := normalizeTags(body.Tags)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TAGS", err.Error())
+		return
+	}
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_URL", "invalid url: "+err.Error())
+		return
+	}
+	resp, err := urlFetch.client.Do(req)
+	if err != nil {
+		writeFetchError(w, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		writeError(w, http.StatusBadGateway, "UPSTREAM_ERROR", "remote server returned "+resp.Status)
+		return
+	}
+	if resp.ContentLength > urlFetch.maxBytes {
+		writeFetchError(w, errFetchTooLarge)
+		return
+	}
+	val, props, err := analysis.AnalyzeReader(&limitedBody{r: resp.Body, n: urlFetch.maxBytes}, opts)
+	if err != nil {
+		writeFetchError(w, err)
+		return
+	}
+	storeStreamed(w, r, collection, val, props, opts, ttl, tags)
+}
+
+func writeFetchError(w http.ResponseWriter, err error) {
+	var ne net.Error
+	switch {
+	case errors.Is(err, errFetchTooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, "REMOTE_CONTENT_TOO_LARGE", fmt.Sprintf("remote content exceeds %d bytes", urlFetch.maxBytes))
+	case errors.Is(err, errFetchPrivateTarget):
+		writeError(w, http.StatusBadRequest, "INVALID_URL", "invalid url: "+errFetchPrivateTarget.Error())
+	case errors.As(err, &ne) && ne.Timeout():
+		writeError(w, http.StatusGatewayTimeout, "UPSTREAM_TIMEOUT", "timed out fetching url")
+	default:
+		writeError(w, http.StatusBadGateway, "UPSTREAM_ERROR", "unable to fetch url: "+err.Error())
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublicAddress(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":        true,
+		"8.8.8.8":              true,
+		"2606:4700:4700::1111": true,
+		"::ffff:93.184.216.34": true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"100.127.255.254":      false,
+		"0.0.0.0":              false,
+		"0.1.2.3":              false,
+		"192.0.0.8":            false,
+		"192.0.2.1":            false,
+		"198.18.0.1":           false,
+		"198.19.255.255":       false,
+		"198.51.100.7":         false,
+		"203.0.113.9":          false,
+		"224.0.0.1":            false,
+		"240.0.0.1":            false,
+		"255.255.255.255":      false,
+		"::":                   false,
+		"::1":                  false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:10.0.0.1":      false,
+		"fc00::1":              false,
+		"fd12:3456:789a::1":    false,
+		"fe80::1":              false,
+		"fe80::1%eth0":         false,
+		"ff02::1":              false,
+		"64:ff9b::a00:1":       false,
+		"2001:db8::1":          false,
+		"2001::1":              false,
+		"2002:a00:1::1":        false,
+		"100::1":               false,
+		"3fff::1":              false,
+	} {
+		if got := publicAddress(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("publicAddress(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+// TestFromURLCancelsFetch checks that the remote request ends when the
+// client's request does.
+func TestFromURLCancelsFetch(t *testing.T) {
+	saved := urlFetch
+	t.Cleanup(func() { urlFetch = saved })
+	t.Setenv("FETCH_ALLOW_PRIVATE_NETWORKS", "true")
+	if err := configureURLFetch(); err != nil {
+		t.Fatal(err)
+	}
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+	defer remote.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest(http.MethodPost, "/strings/from-url", strings.NewReader(`{"url":"`+remote.URL+`"}`)).WithContext(ctx)
+	done := make(chan struct{})
+	go func() {
+		fromURLHandler(httptest.NewRecorder(), r)
+		close(done)
+	}()
+	<-started
+	cancel()
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("remote request still running after the client's request ended")
+	}
+	<-done
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+// fieldSet is a parsed `fields` parameter: the record fields to keep, each
+// a top-level field or a `properties.<name>` path. A nil fieldSet keeps
+// everything.
+type fieldSet [][]string
+
+var (
+	recordFieldNames   = jsonFieldNames(reflect.TypeOf(store.StoredString{}))
+	propertyFieldNames = jsonFieldNames(reflect.TypeOf(analysis.Properties{}))
+)
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+func parseFields(q url.Values) (fieldSet, error) {
+	v := q.Get("fields")
+	if v == "" {
+		return nil, nil
+	}
+	fs := fieldSet{}
+	for _, f := range strings.Split(v, ",") {
+		f = strings.TrimSpace(f)
+		path := strings.Split(f, ".")
+		switch {
+		case len(path) == 1 && recordFieldNames[path[0]]:
+		case len(path) == 2 && path[0] == "properties" && propertyFieldNames[path[1]]:
+		default:
+			return nil, fmt.Errorf("unknown field %q", f)
+		}
+		fs = append(fs, path)
+	}
+	return fs, nil
+}
+
+// project keeps the selected fields of an encoded record. Fields the record
+// omits, such as an unset updated_at, stay absent.
+func (fs fieldSet) project(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var record map[string]interface{}
+	if err := dec.Decode(&record); err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{}
+	for _, path := range fs {
+		v, ok := record[path[0]]
+		if !ok {
+			continue
+		}
+		if len(path) == 1 {
+			out[path[0]] = v
+			continue
+		}
+		props, _ := v.(map[string]interface{})
+		sub, ok := props[path[1]]
+		if !ok {
+			continue
+		}
+		selected, ok := out["properties"].(map[string]interface{})
+		if !ok {
+			selected = map[string]interface{}{}
+			out["properties"] = selected
+		}
+		selected[path[1]] = sub
+	}
+	return json.Marshal(out)
+}
+
+// writeFields writes v, reduced to fs when that is set.
+func writeFields(w http.ResponseWriter, code int, v interface{}, fs fieldSet) {
+	if fs == nil {
+		writeJSON(w, code, v)
+		return
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		data, err = fs.project(data)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "unable to encode response")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(append(data, '\n'))
+}
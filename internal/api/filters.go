@@ -0,0 +1,400 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+type filterSpec struct {
+	name  string
+	parse func(string) (interface{}, error)
+	match func(item store.StoredString, v interface{}) bool
+}
+
+type filterCond struct {
+	spec  *filterSpec
+	value interface{}
+}
+
+// filterSet is a parsed listing query. tenant, when set, limits it to that
+// tenant's records.
+type filterSet struct {
+	and    []filterCond
+	or     [][]filterCond
+	tenant string
+}
+
+func parseNonNegativeInt(name string) func(string) (interface{}, error) {
+	return func(v string) (interface{}, error) {
+		x, err := strconv.Atoi(v)
+		if err != nil || x < 0 {
+			return nil, errors.New("invalid " + name)
+		}
+		return x, nil
+	}
+}
+
+func parseFloatFilter(name string) func(string) (interface{}, error) {
+	return func(v string) (interface{}, error) {
+		x, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, errors.New("invalid " + name)
+		}
+		return x, nil
+	}
+}
+
+func parseBoolFilter(name string) func(string) (interface{}, error) {
+	return func(v string) (interface{}, error) {
+		b, err := parseBoolParam(strings.ToLower(v))
+		if err != nil {
+			return nil, errors.New("invalid " + name + " value")
+		}
+		return b, nil
+	}
+}
+
+var listFilters = []*filterSpec{
+	{
+		name:  "is_palindrome",
+		parse: parseBoolFilter("is_palindrome"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.IsPalindrome == v.(bool)
+		},
+	},
+	{
+		name:  "min_length",
+		parse: parseNonNegativeInt("min_length"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.Length >= v.(int)
+		},
+	},
+	{
+		name:  "max_length",
+		parse: parseNonNegativeInt("max_length"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.Length <= v.(int)
+		},
+	},
+	{
+		name:  "word_count",
+		parse: parseNonNegativeInt("word_count"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.WordCount == v.(int)
+		},
+	},
+	{
+		name: "contains_character",
+		parse: func(v string) (interface{}, error) {
+			rs := []rune(v)
+			if len(rs) != 1 {
+				return nil, errors.New("contains_character must be a single character")
+			}
+			return string(rs[0]), nil
+		},
+		match: func(item store.StoredString, v interface{}) bool {
+			ch := v.(string)
+			if item.Properties.FrequencyMapCaseFolded {
+				ch = strings.ToLower(ch)
+			}
+			_, ok := item.Properties.CharacterFrequencyMap[ch]
+			return ok
+		},
+	},
+	{
+		name:  "is_multiline",
+		parse: parseBoolFilter("is_multiline"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.IsMultiline == v.(bool)
+		},
+	},
+	{
+		name:  "line_count",
+		parse: parseNonNegativeInt("line_count"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.LineCount == v.(int)
+		},
+	},
+	{
+		name:  "has_leading_whitespace",
+		parse: parseBoolFilter("has_leading_whitespace"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return (item.Properties.LeadingWhitespace > 0) == v.(bool)
+		},
+	},
+	{
+		name:  "has_trailing_whitespace",
+		parse: parseBoolFilter("has_trailing_whitespace"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return (item.Properties.TrailingWhitespace > 0) == v.(bool)
+		},
+	},
+	{
+		name:  "has_tabs",
+		parse: parseBoolFilter("has_tabs"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return (item.Properties.TabCount > 0) == v.(bool)
+		},
+	},
+	{
+		name:  "has_consecutive_spaces",
+		parse: parseBoolFilter("has_consecutive_spaces"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return (item.Properties.ConsecutiveSpaceRuns > 0) == v.(bool)
+		},
+	},
+	{
+		name:  "has_repeated_words",
+		parse: parseBoolFilter("has_repeated_words"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.HasRepeatedWords == v.(bool)
+		},
+	},
+	{
+		name:  "min_longest_run",
+		parse: parseNonNegativeInt("min_longest_run"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.LongestRunLength >= v.(int)
+		},
+	},
+	{
+		name: "script",
+		parse: func(v string) (interface{}, error) {
+			for name := range unicode.Scripts {
+				if strings.EqualFold(name, v) {
+					return name, nil
+				}
+			}
+			return nil, errors.New("invalid script")
+		},
+		match: func(item store.StoredString, v interface{}) bool {
+			for _, s := range item.Properties.Scripts {
+				if s == v.(string) {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	{
+		name:  "is_mixed_script",
+		parse: parseBoolFilter("is_mixed_script"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.IsMixedScript == v.(bool)
+		},
+	},
+	{
+		name:  "min_content_word_count",
+		parse: parseNonNegativeInt("min_content_word_count"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.ContentWordCount >= v.(int)
+		},
+	},
+	{
+		name:  "min_stopword_ratio",
+		parse: parseFloatFilter("min_stopword_ratio"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.StopwordRatio >= v.(float64)
+		},
+	},
+	{
+		name:  "max_stopword_ratio",
+		parse: parseFloatFilter("max_stopword_ratio"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.StopwordRatio <= v.(float64)
+		},
+	},
+	{
+		name: "collection",
+		parse: func(v string) (interface{}, error) {
+			if !collectionNamePattern.MatchString(v) {
+				return nil, errors.New("invalid collection name")
+			}
+			return v, nil
+		},
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Collection == v.(string)
+		},
+	},
+	{
+		name: "tag",
+		parse: func(v string) (interface{}, error) {
+			tags, err := normalizeTags(strings.Split(v, ","))
+			if err != nil || len(tags) == 0 {
+				return nil, errors.New("invalid tag, expected comma-separated tags")
+			}
+			return tags, nil
+		},
+		match: func(item store.StoredString, v interface{}) bool {
+			for _, t := range v.([]string) {
+				if !item.HasTag(t) {
+					return false
+				}
+			}
+			return true
+		},
+	},
+	{
+		name:  "analysis_incomplete",
+		parse: parseBoolFilter("analysis_incomplete"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return (len(item.Properties.AnalyzerStatus) > 0) == v.(bool)
+		},
+	},
+	{
+		name:  "contains_number",
+		parse: parseBoolFilter("contains_number"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return (item.Properties.NumberCount > 0) == v.(bool)
+		},
+	},
+	{
+		name:  "number_count",
+		parse: parseNonNegativeInt("number_count"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.NumberCount == v.(int)
+		},
+	},
+	{
+		name:  "min_number_sum",
+		parse: parseFloatFilter("min_number_sum"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.NumberCount > 0 && item.Properties.NumberSum >= v.(float64)
+		},
+	},
+	{
+		name:  "max_number_sum",
+		parse: parseFloatFilter("max_number_sum"),
+		match: func(item store.StoredString, v interface{}) bool {
+			return item.Properties.NumberCount > 0 && item.Properties.NumberSum <= v.(float64)
+		},
+	},
+}
+
+func lookupFilter(name string) *filterSpec {
+	for _, f := range listFilters {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// parseFilterSet reads the plain filter parameters (combined with AND) and
+// any number of `or` parameters. Each `or` parameter is a comma-separated
+// group of name:value conditions of which at least one must match. Every
+// invalid filter is reported, as paramErrors.
+func parseFilterSet(q url.Values) (filterSet, error) {
+	fs := filterSet{}
+	var errs paramErrors
+	for _, spec := range listFilters {
+		v := q.Get(spec.name)
+		if v == "" {
+			continue
+		}
+		val, err := spec.parse(v)
+		if err != nil {
+			errs.add(spec.name, err)
+			continue
+		}
+		fs.and = append(fs.and, filterCond{spec: spec, value: val})
+	}
+	for _, group := range q["or"] {
+		if strings.TrimSpace(group) == "" {
+			continue
+		}
+		conds := []filterCond{}
+		for _, part := range strings.Split(group, ",") {
+			name, raw, ok := strings.Cut(part, ":")
+			name = strings.TrimSpace(name)
+			if !ok || raw == "" {
+				errs.add("or", fmt.Errorf("invalid or condition %q, expected name:value", part))
+				continue
+			}
+			spec := lookupFilter(name)
+			if spec == nil {
+				errs.add("or", fmt.Errorf("unknown filter %q in or condition", name))
+				continue
+			}
+			val, err := spec.parse(raw)
+			if err != nil {
+				errs.add("or", err)
+				continue
+			}
+			conds = append(conds, filterCond{spec: spec, value: val})
+		}
+		fs.or = append(fs.or, conds)
+	}
+	return fs, errs.err()
+}
+
+func (fs filterSet) matches(item store.StoredString) bool {
+	if fs.tenant != "" && storedTenant(item) != fs.tenant {
+		return false
+	}
+	for _, c := range fs.and {
+		if !c.spec.match(item, c.value) {
+			return false
+		}
+	}
+	for _, group := range fs.or {
+		ok := false
+		for _, c := range group {
+			if c.spec.match(item, c.value) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// query converts fs for backends that evaluate listing queries themselves.
+func (fs filterSet) query() store.Query {
+	q := store.Query{Tenant: fs.tenant}
+	for _, c := range fs.and {
+		q.And = append(q.And, c.cond())
+	}
+	for _, group := range fs.or {
+		conds := make([]store.Cond, 0, len(group))
+		for _, c := range group {
+			conds = append(conds, c.cond())
+		}
+		q.Or = append(q.Or, conds)
+	}
+	return q
+}
+
+func (c filterCond) cond() store.Cond {
+	return store.Cond{Name: c.spec.name, Value: c.value, Match: func(item store.StoredString) bool {
+		return c.spec.match(item, c.value)
+	}}
+}
+
+func (fs filterSet) applied() map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, c := range fs.and {
+		out[c.spec.name] = c.value
+	}
+	if len(fs.or) > 0 {
+		groups := make([][]map[string]interface{}, 0, len(fs.or))
+		for _, group := range fs.or {
+			g := make([]map[string]interface{}, 0, len(group))
+			for _, c := range group {
+				g = append(g, map[string]interface{}{c.spec.name: c.value})
+			}
+			groups = append(groups, g)
+		}
+		out["or"] = groups
+	}
+	return out
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// grafanaMetrics are the series /stats/query serves from the time-series
+// counters, besides one word_count.<n> series per word count seen.
+var grafanaMetrics = map[string]func(b *timeBucket) float64{
+	"created":     func(b *timeBucket) float64 { return float64(b.Count) },
+	"palindromes": func(b *timeBucket) float64 { return float64(b.Palindromes) },
+	"palindrome_share": func(b *timeBucket) float64 {
+		return float64(b.Palindromes) / float64(b.Count)
+	},
+	"avg_length": func(b *timeBucket) float64 { return float64(b.TotalLength) / float64(b.Count) },
+}
+
+const grafanaCumulativeMetric = "created_total"
+
+type grafanaQuery struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	IntervalMs int64 `json:"intervalMs"`
+	Targets    []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// grafanaInterval picks the widest counter interval that fits in the
+// interval Grafana asks for, so each point is one bucket.
+func grafanaInterval(ms int64) string {
+	best, bestD := "minute", time.Minute
+	for name, d := range timeseriesIntervals {
+		if d > bestD && d.Milliseconds() <= ms {
+			best, bestD = name, d
+		}
+	}
+	return best
+}
+
+func grafanaSearch(tenant, prefix string) []string {
+	names := []string{grafanaCumulativeMetric}
+	for name := range grafanaMetrics {
+		names = append(names, name)
+	}
+	seen := map[int]bool{}
+	timeseries.Lock()
+	for _, b := range timeseries.buckets[seriesKey{tenant, "day"}] {
+		for wc := range b.WordCounts {
+			seen[wc] = true
+		}
+	}
+	timeseries.Unlock()
+	for wc := range seen {
+		names = append(names, "word_count."+strconv.Itoa(wc))
+	}
+	matched := names[:0]
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			matched = append(matched, name)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// grafanaValue returns how target reads one bucket; ok is false for unknown
+// targets.
+func grafanaValue(target string) (func(b *timeBucket) float64, bool) {
+	if f, ok := grafanaMetrics[target]; ok {
+		return f, true
+	}
+	if target == grafanaCumulativeMetric {
+		return func(b *timeBucket) float64 { return float64(b.Count) }, true
+	}
+	if v, ok := strings.CutPrefix(target, "word_count."); ok {
+		wc, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, false
+		}
+		return func(b *timeBucket) float64 { return float64(b.WordCounts[wc]) }, true
+	}
+	return nil, false
+}
+
+func grafanaSeriesFor(tenant, target, interval string, from, to time.Time) (grafanaSeries, bool) {
+	value, ok := grafanaValue(target)
+	if !ok {
+		return grafanaSeries{}, false
+	}
+	timeseries.Lock()
+	defer timeseries.Unlock()
+	buckets := timeseries.buckets[seriesKey{tenant, interval}]
+	starts := make([]int64, 0, len(buckets))
+	for start := range buckets {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	series := grafanaSeries{Target: target, Datapoints: [][2]float64{}}
+	total := 0.0
+	for _, start := range starts {
+		b := buckets[start]
+		t := time.Unix(start, 0)
+		total += float64(b.Count)
+		if t.Before(from.Truncate(timeseriesIntervals[interval])) || (!to.IsZero() && t.After(to)) {
+			continue
+		}
+		v := value(b)
+		if target == grafanaCumulativeMetric {
+			v = total
+		}
+		series.Datapoints = append(series.Datapoints, [2]float64{v, float64(t.UnixMilli())})
+	}
+	return series, true
+}
+
+// grafanaTestHandler, grafanaSearchHandler and grafanaQueryHandler
+// implement the Grafana simple JSON datasource contract over the time-series
+// counters: GET /stats/query answers the connection test, POST
+// /stats/query/search lists metrics and POST /stats/query/query returns
+// their datapoints in a time range.
+func grafanaTestHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func grafanaSearchHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Target string `json:"target"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	writeJSON(w, http.StatusOK, grafanaSearch(tenantOf(r), body.Target))
+}
+
+func grafanaQueryHandler(w http.ResponseWriter, r *http.Request) {
+	var body grafanaQuery
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	interval := grafanaInterval(body.IntervalMs)
+	out := make([]grafanaSeries, 0, len(body.Targets))
+	for _, t := range body.Targets {
+		series, ok := grafanaSeriesFor(tenantOf(r), t.Target, interval, body.Range.From, body.Range.To)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "UNKNOWN_TARGET", "unknown target "+strconv.Quote(t.Target))
+			return
+		}
+		out = append(out, series)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
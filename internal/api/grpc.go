@@ -0,0 +1,262 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/rpc/stringpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// rpcHeaders are the metadata keys a call carries into the HTTP request it
+// is served as.
+var rpcHeaders = []string{"x-api-key", "authorization", tenantHeader, requestIDHeader, "idempotency-key"}
+
+// rpcCodes map the status of an API response to the code of the call.
+var rpcCodes = map[int]codes.Code{
+	http.StatusMultipleChoices:       codes.FailedPrecondition,
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusGone:                  codes.NotFound,
+	http.StatusPreconditionFailed:    codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusInternalServerError:   codes.Internal,
+	http.StatusBadGateway:            codes.Unavailable,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+	http.StatusInsufficientStorage:   codes.ResourceExhausted,
+}
+
+// stringService serves StringService by making each call the matching
+// request to the HTTP API through handler, its full middleware chain, so
+// calls are authenticated, limited, validated, audited and stored exactly
+// as over HTTP.
+type stringService struct {
+	stringpb.UnimplementedStringServiceServer
+	handler http.Handler
+}
+
+// newGRPCServer serves StringService over TLS with tlsConfig, the
+// configuration of the HTTP listener, when it is not nil, so metadata
+// carrying keys and tokens is never sent in the clear.
+func newGRPCServer(handler http.Handler, tlsConfig *tls.Config) *grpc.Server {
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		tc := tlsConfig.Clone()
+		tc.NextProtos = []string{"h2"}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tc)))
+	}
+	s := grpc.NewServer(opts...)
+	stringpb.RegisterStringServiceServer(s, &stringService{handler: handler})
+	return s
+}
+
+// serveGRPC serves s on its own listener at addr until it is stopped.
+func serveGRPC(s *grpc.Server, addr string) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Error("gRPC listen failed", "addr", addr, "error", err)
+		return
+	}
+	logger.Info("gRPC listening", "addr", addr)
+	if err := s.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		logger.Error("gRPC server error", "error", err)
+	}
+}
+
+func (s *stringService) Create(ctx context.Context, req *stringpb.CreateRequest) (*stringpb.String, error) {
+	body := CreateReq{Value: req.GetValue(), Collection: req.GetCollection(), Language: req.GetLanguage(), Tags: req.GetTags()}
+	if ci := req.GetCaseInsensitive(); ci {
+		body.CaseInsensitive = &ci
+	}
+	if ttl := int(req.GetTtlSeconds()); ttl != 0 {
+		body.TTLSeconds = &ttl
+	}
+	out := &stringpb.String{}
+	if err := s.call(ctx, http.MethodPost, "/strings", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *stringService) Get(ctx context.Context, req *stringpb.GetRequest) (*stringpb.String, error) {
+	path, err := rpcRecordPath(req.GetValue(), req.GetId(), req.GetKey() != nil)
+	if err != nil {
+		return nil, err
+	}
+	out := &stringpb.String{}
+	if err := s.call(ctx, http.MethodGet, path, nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *stringService) List(ctx context.Context, req *stringpb.ListRequest) (*stringpb.ListResponse, error) {
+	q := url.Values{}
+	for k, v := range req.GetParams() {
+		q.Set(k, v)
+	}
+	if req.IsPalindrome != nil {
+		q.Set("is_palindrome", strconv.FormatBool(req.GetIsPalindrome()))
+	}
+	for name, v := range map[string]*int64{"min_length": req.MinLength, "max_length": req.MaxLength, "word_count": req.WordCount} {
+		if v != nil {
+			q.Set(name, strconv.FormatInt(*v, 10))
+		}
+	}
+	for name, v := range map[string]string{
+		"contains_character": req.GetContainsCharacter(),
+		"collection":         req.GetCollection(),
+		"tag":                strings.Join(req.GetTags(), ","),
+		"sort_by":            req.GetSortBy(),
+		"order":              req.GetOrder(),
+		"cursor":             req.GetCursor(),
+	} {
+		if v != "" {
+			q.Set(name, v)
+		}
+	}
+	if req.GetLimit() != 0 {
+		q.Set("limit", strconv.Itoa(int(req.GetLimit())))
+	}
+	out := &stringpb.ListResponse{}
+	if err := s.call(ctx, http.MethodGet, "/strings", q, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *stringService) Delete(ctx context.Context, req *stringpb.DeleteRequest) (*stringpb.DeleteResponse, error) {
+	path, err := rpcRecordPath(req.GetValue(), req.GetId(), req.GetKey() != nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.call(ctx, http.MethodDelete, path, nil, nil, nil); err != nil {
+		return nil, err
+	}
+	return &stringpb.DeleteResponse{}, nil
+}
+
+func (s *stringService) NaturalLanguageQuery(ctx context.Context, req *stringpb.NaturalLanguageQueryRequest) (*stringpb.NaturalLanguageQueryResponse, error) {
+	out := &stringpb.NaturalLanguageQueryResponse{}
+	if err := s.call(ctx, http.MethodGet, "/strings/filter-by-natural-language", url.Values{"query": {req.GetQuery()}}, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// rpcRecordPath returns the route of the string a call names by value or
+// id.
+func rpcRecordPath(value, id string, set bool) (string, error) {
+	switch {
+	case !set:
+		return "", status.Error(codes.InvalidArgument, "value or id is required")
+	case id != "":
+		return "/strings/id/" + url.PathEscape(id), nil
+	}
+	return "/strings/" + url.PathEscape(value), nil
+}
+
+// call serves the API request and decodes a successful JSON response into
+// out, or turns an error response into the status of the call.
+func (s *stringService) call(ctx context.Context, method, path string, query url.Values, body interface{}, out proto.Message) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	target := "/" + currentAPIVersion + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	r, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(data))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	r.Header.Set("Accept", "application/json")
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, h := range rpcHeaders {
+		for _, v := range md.Get(h) {
+			r.Header.Add(h, v)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+	buf := &bufferedResponse{header: http.Header{}}
+	s.handler.ServeHTTP(buf, r)
+	if buf.code == 0 {
+		buf.code = http.StatusOK
+	}
+	if id := buf.header.Get(requestIDHeader); id != "" {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+	}
+	if buf.code >= http.StatusMultipleChoices {
+		return rpcError(buf)
+	}
+	if out == nil || buf.body.Len() == 0 {
+		return nil
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(buf.body.Bytes(), out); err != nil {
+		return status.Error(codes.Internal, "unable to decode response: "+err.Error())
+	}
+	return nil
+}
+
+// rpcError turns an API error response into a status carrying the error
+// code as the reason of an ErrorInfo.
+func rpcError(buf *bufferedResponse) error {
+	var env errorEnvelope
+	_ = json.Unmarshal(buf.body.Bytes(), &env)
+	e := env.Error
+	code, ok := rpcCodes[buf.code]
+	switch {
+	case !ok:
+		code = codes.Unknown
+	case buf.code == http.StatusConflict && e.Code != "STRING_EXISTS":
+		code = codes.Aborted
+	case e.Code == "TIMEOUT":
+		code = codes.DeadlineExceeded
+	}
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(buf.code)
+	}
+	st := status.New(code, msg)
+	if e.Code != "" {
+		info := &errdetails.ErrorInfo{Reason: e.Code, Domain: "stringanalysis.v1"}
+		if e.RequestID != "" {
+			info.Metadata = map[string]string{"request_id": e.RequestID}
+		}
+		if withInfo, err := st.WithDetails(info); err == nil {
+			st = withInfo
+		}
+	}
+	return st.Err()
+}
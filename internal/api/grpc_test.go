@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/rpc/stringpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// TestGRPCServerUsesTLS checks that the gRPC listener takes the TLS
+// configuration of the HTTP one and refuses plaintext clients.
+func TestGRPCServerUsesTLS(t *testing.T) {
+	https := httptest.NewTLSServer(http.NotFoundHandler())
+	defer https.Close()
+	s := newGRPCServer(http.NotFoundHandler(), https.TLS)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(lis)
+	defer s.Stop()
+
+	get := func(creds credentials.TransportCredentials) error {
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(creds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = stringpb.NewStringServiceClient(conn).Get(ctx, &stringpb.GetRequest{Key: &stringpb.GetRequest_Value{Value: "abba"}})
+		return err
+	}
+	roots := https.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	if err := get(credentials.NewClientTLSFromCert(roots, "example.com")); status.Code(err) != codes.NotFound {
+		t.Fatalf("TLS call: %v, want NotFound", err)
+	}
+	if err := get(insecure.NewCredentials()); status.Code(err) != codes.Unavailable {
+		t.Fatalf("plaintext call: %v, want Unavailable", err)
+	}
+}
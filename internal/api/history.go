@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+// historyEntry is one version of a record, as its create or update event
+// recorded it.
+type historyEntry struct {
+	Version    int64               `json:"version"`
+	Event      string              `json:"event"`
+	At         store.Timestamp     `json:"at"`
+	Properties analysis.Properties `json:"properties"`
+}
+
+// recordHistory returns the versions of the record id in the tenant's
+// collection in the order they were written. The event stream already keeps
+// every version, and forgets them when a tenant is purged, so history is
+// read from it rather than stored twice.
+func recordHistory(id, tenant, collection string) []historyEntry {
+	events.Lock()
+	defer events.Unlock()
+	out := []historyEntry{}
+	for _, e := range events.list {
+		if e.ID == id && e.Record != nil && recordInScope(*e.Record, tenant, collection) {
+			out = append(out, historyEntry{Version: e.Record.Version, Event: e.Type, At: e.At, Properties: e.Record.Properties})
+		}
+	}
+	return out
+}
+
+// historyHandler lists the versions of a string, including one that has
+// since been deleted.
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	value := r.PathValue("value")
+	id, ok := pathRecordID(w, r, value)
+	if !ok {
+		return
+	}
+	versions := recordHistory(id, tenantOf(r), r.URL.Query().Get("collection"))
+	if len(versions) == 0 {
+		writeStorageError(w, r, store.ErrNotFound)
+		return
+	}
+	// Unlike the record under the same /strings/ route, history grows with
+	// every update, so caches must revalidate it.
+	w.Header().Set("Cache-Control", "public, no-cache")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":    id,
+		"value": value,
+		"data":  versions,
+		"count": len(versions),
+	})
+}
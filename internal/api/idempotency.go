@@ -0,0 +1,145 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultIdempotencyTTL = 24 * time.Hour
+	maxIdempotencyKeys    = 10000
+	maxIdempotencyKeyLen  = 255
+)
+
+// idempotentResponse is the response first given to an Idempotency-Key.
+// done is closed once it is known; until then retries are told the original
+// request is still in flight.
+type idempotentResponse struct {
+	fingerprint [sha256.Size]byte
+	expires     time.Time
+	done        chan struct{}
+	header      http.Header
+	code        int
+	body        []byte
+}
+
+var idempotency = struct {
+	sync.Mutex
+	ttl     time.Duration
+	entries map[string]*idempotentResponse
+}{ttl: defaultIdempotencyTTL, entries: map[string]*idempotentResponse{}}
+
+// requestFingerprint identifies what a request asks for, so a key reused for
+// a different request is refused rather than answered with the wrong
+// response. format and project only change how the response is rendered.
+func requestFingerprint(r *http.Request, body []byte) [sha256.Size]byte {
+	q := maps.Clone(r.URL.Query())
+	q.Del("format")
+	q.Del("project")
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00", r.Method, r.URL.Path, q.Encode(), r.Header.Get("Content-Type"))
+	h.Write(body)
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// reserveIdempotencyKey returns the entry held for key, and whether it was
+// just created as pending for this request. Expired entries are dropped
+// first so the cache stays within maxIdempotencyKeys, evicting the entries
+// closest to expiry if it is still full.
+func reserveIdempotencyKey(key string, fingerprint [sha256.Size]byte) (*idempotentResponse, bool) {
+	idempotency.Lock()
+	defer idempotency.Unlock()
+	now := time.Now()
+	if e, ok := idempotency.entries[key]; ok && now.Before(e.expires) {
+		return e, false
+	}
+	if len(idempotency.entries) >= maxIdempotencyKeys {
+		for k, e := range idempotency.entries {
+			if !now.Before(e.expires) {
+				delete(idempotency.entries, k)
+			}
+		}
+		for len(idempotency.entries) >= maxIdempotencyKeys {
+			var oldest string
+			for k, e := range idempotency.entries {
+				if oldest == "" || e.expires.Before(idempotency.entries[oldest].expires) {
+					oldest = k
+				}
+			}
+			delete(idempotency.entries, oldest)
+		}
+	}
+	e := &idempotentResponse{fingerprint: fingerprint, expires: now.Add(idempotency.ttl), done: make(chan struct{})}
+	idempotency.entries[key] = e
+	return e, true
+}
+
+// withIdempotencyKey replays the stored response when a request repeats an
+// Idempotency-Key, so a client retrying a create after a network failure
+// gets its original 201 instead of a 409. Keys are scoped to the tenant.
+// Server errors are not stored, so those requests can be retried for real.
+func withIdempotencyKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			writeError(w, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLen))
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_BODY", "failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		key = tenantOf(r) + "\x00" + key
+		fingerprint := requestFingerprint(r, body)
+		e, fresh := reserveIdempotencyKey(key, fingerprint)
+		if !fresh {
+			if e.fingerprint != fingerprint {
+				writeError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+				return
+			}
+			select {
+			case <-e.done:
+			default:
+				writeError(w, http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE", "a request with this Idempotency-Key is still in progress")
+				return
+			}
+			buf := &bufferedResponse{header: e.header.Clone(), code: e.code}
+			buf.body.Write(e.body)
+			buf.header.Set("Idempotent-Replayed", "true")
+			buf.flushTo(w)
+			return
+		}
+		buf := newBufferedResponse(w)
+		next(buf, r)
+		if buf.code == 0 {
+			buf.code = http.StatusOK
+		}
+		idempotency.Lock()
+		if buf.code >= 500 {
+			if idempotency.entries[key] == e {
+				delete(idempotency.entries, key)
+			}
+		} else {
+			e.header, e.code, e.body = buf.header.Clone(), buf.code, buf.body.Bytes()
+			// Replays are new requests with IDs of their own.
+			e.header.Del(requestIDHeader)
+		}
+		close(e.done)
+		idempotency.Unlock()
+		buf.flushTo(w)
+	}
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+// managedIndex is an in-process index kept in step with the store. While an
+// index is disabled its hooks are skipped and the writes it missed are
+// counted; enabling it again rebuilds it from the store.
+type managedIndex struct {
+	name         string
+	description  string
+	add          func(item store.StoredString)
+	remove       func(item store.StoredString)
+	rebuild      func(items []store.StoredString)
+	size         func() int
+	details      func() map[string]interface{}
+	enabled      bool
+	missedWrites int
+	lastRebuild  time.Time
+}
+
+func itemValues(items []store.StoredString) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = item.Value
+	}
+	return out
+}
+
+var indexRegistry = struct {
+	sync.Mutex
+	list []*managedIndex
+}{list: []*managedIndex{
+	{
+		name:        "words",
+		description: "Corpus word frequencies behind GET /strings/stats/words.",
+		add:         func(item store.StoredString) { corpusWords.of(storedTenant(item)).add(item.Value) },
+		remove:      func(item store.StoredString) { corpusWords.of(storedTenant(item)).remove(item.Value) },
+		rebuild: func(items []store.StoredString) {
+			corpusWords.reset()
+			for _, item := range items {
+				corpusWords.of(storedTenant(item)).add(item.Value)
+			}
+		},
+		size: func() int {
+			n := 0
+			for _, c := range corpusWords.all() {
+				c.Lock()
+				n += len(c.counts)
+				c.Unlock()
+			}
+			return n
+		},
+		enabled: true,
+	},
+	{
+		name:        "typeahead",
+		description: "Radix tree of lowercased values behind GET /strings/typeahead.",
+		add:         func(item store.StoredString) { typeaheadIndex.of(storedTenant(item)).insert(item.Value) },
+		remove:      func(item store.StoredString) { typeaheadIndex.of(storedTenant(item)).remove(item.Value) },
+		rebuild: func(items []store.StoredString) {
+			typeaheadIndex.reset()
+			for _, item := range items {
+				typeaheadIndex.of(storedTenant(item)).insert(item.Value)
+			}
+		},
+		size: func() int {
+			n := 0
+			for _, t := range typeaheadIndex.all() {
+				t.RLock()
+				n += t.size
+				t.RUnlock()
+			}
+			return n
+		},
+		enabled: true,
+	},
+	{
+		name:        "bktree",
+		description: "BK-tree over edit distance behind GET /strings/fuzzy.",
+		add:         func(item store.StoredString) { fuzzyIndex.of(storedTenant(item)).insert(item.Value) },
+		remove:      func(item store.StoredString) { fuzzyIndex.of(storedTenant(item)).remove(item.Value) },
+		rebuild: func(items []store.StoredString) {
+			fuzzyIndex.reset()
+			for tenant, owned := range byTenant(items) {
+				fuzzyIndex.of(tenant).rebuild(itemValues(owned))
+			}
+		},
+		size: func() int {
+			n := 0
+			for _, t := range fuzzyIndex.all() {
+				t.RLock()
+				n += t.live
+				t.RUnlock()
+			}
+			return n
+		},
+		details: fuzzyMetrics,
+		enabled: true,
+	},
+}}
+
+// indexCreated, indexUpdated, indexDeleted and indexReset keep the enabled
+// indexes in step with the store. indexedStorage calls them while holding its
+// write lock, in the order the backend applied the writes.
+func indexCreated(item store.StoredString) {
+	storeVersion.Add(1)
+	forgetEncoded(item.ID)
+	adjustTenantUsage(item, 1)
+	eachIndex(func(idx *managedIndex) { idx.add(item) })
+	precomputeDirty()
+}
+
+// indexUpdated runs when a record is re-analyzed. The value is unchanged, so
+// only cached listings are invalidated.
+func indexUpdated(item store.StoredString) {
+	storeVersion.Add(1)
+	forgetEncoded(item.ID)
+	precomputeDirty()
+}
+
+func indexDeleted(item store.StoredString) {
+	storeVersion.Add(1)
+	forgetEncoded(item.ID)
+	adjustTenantUsage(item, -1)
+	eachIndex(func(idx *managedIndex) { idx.remove(item) })
+	precomputeDirty()
+}
+
+func indexReset() {
+	storeVersion.Add(1)
+	forgetAllEncoded()
+	recountTenantUsage(nil)
+	precomputeDirty()
+	indexRegistry.Lock()
+	defer indexRegistry.Unlock()
+	for _, idx := range indexRegistry.list {
+		idx.rebuild(nil)
+		idx.missedWrites = 0
+		idx.lastRebuild = time.Now()
+	}
+}
+
+func eachIndex(fn func(idx *managedIndex)) {
+	indexRegistry.Lock()
+	defer indexRegistry.Unlock()
+	for _, idx := range indexRegistry.list {
+		if idx.enabled {
+			fn(idx)
+		} else {
+			idx.missedWrites++
+		}
+	}
+}
+
+func lookupIndex(name string) *managedIndex {
+	for _, idx := range indexRegistry.list {
+		if idx.name == name {
+			return idx
+		}
+	}
+	return nil
+}
+
+func indexEnabled(name string) bool {
+	indexRegistry.Lock()
+	defer indexRegistry.Unlock()
+	idx := lookupIndex(name)
+	return idx != nil && idx.enabled
+}
+
+func writeIndexDisabled(w http.ResponseWriter, name string) {
+	writeError(w, http.StatusServiceUnavailable, "INDEX_DISABLED", "the "+name+" index is disabled")
+}
+
+// rebuildIndex repopulates idx from the store. Writes are held off for the
+// duration so none land between listing the records and swapping them in.
+func rebuildIndex(idx *managedIndex) error {
+	if s, ok := db.(*indexedStorage); ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	items, err := db.List()
+	if err != nil {
+		return err
+	}
+	indexRegistry.Lock()
+	defer indexRegistry.Unlock()
+	idx.rebuild(items)
+	idx.missedWrites = 0
+	idx.lastRebuild = time.Now()
+	return nil
+}
+
+// rebuildAllIndexes populates every index from the store, e.g. at startup
+// with a persistent backend.
+func rebuildAllIndexes() error {
+	for _, idx := range indexRegistry.list {
+		if err := rebuildIndex(idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func indexStatus(idx *managedIndex) map[string]interface{} {
+	m := map[string]interface{}{}
+	if idx.details != nil {
+		m = idx.details()
+	}
+	m["name"] = idx.name
+	m["description"] = idx.description
+	m["enabled"] = idx.enabled
+	m["size"] = idx.size()
+	m["stale"] = idx.missedWrites > 0
+	m["missed_writes"] = idx.missedWrites
+	m["last_rebuild_at"] = nil
+	if !idx.lastRebuild.IsZero() {
+		m["last_rebuild_at"] = store.NewTimestamp(idx.lastRebuild)
+	}
+	return m
+}
+
+func indexListHandler(w http.ResponseWriter, r *http.Request) {
+	indexRegistry.Lock()
+	list := make([]map[string]interface{}, 0, len(indexRegistry.list))
+	for _, idx := range indexRegistry.list {
+		list = append(list, indexStatus(idx))
+	}
+	indexRegistry.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": list, "count": len(list)})
+}
+
+// indexOf finds the index named by the request path, answering 404 when
+// there is none.
+func indexOf(w http.ResponseWriter, r *http.Request) *managedIndex {
+	name := r.PathValue("name")
+	indexRegistry.Lock()
+	idx := lookupIndex(name)
+	indexRegistry.Unlock()
+	if idx == nil {
+		writeError(w, http.StatusNotFound, "INDEX_NOT_FOUND", "index "+name+" does not exist")
+	}
+	return idx
+}
+
+func indexStatusHandler(w http.ResponseWriter, r *http.Request) {
+	idx := indexOf(w, r)
+	if idx == nil {
+		return
+	}
+	indexRegistry.Lock()
+	status := indexStatus(idx)
+	indexRegistry.Unlock()
+	writeJSON(w, http.StatusOK, status)
+}
+
+// indexActionHandler serves POST /admin/indexes/{name}/{enable,disable,rebuild}.
+func indexActionHandler(w http.ResponseWriter, r *http.Request) {
+	action := r.PathValue("action")
+	if action != "enable" && action != "disable" && action != "rebuild" {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+		return
+	}
+	idx := indexOf(w, r)
+	if idx == nil {
+		return
+	}
+	indexRegistry.Lock()
+	wasEnabled := idx.enabled
+	if action != "rebuild" {
+		idx.enabled = action == "enable"
+	}
+	stale := idx.missedWrites > 0
+	indexRegistry.Unlock()
+	if action == "rebuild" || (action == "enable" && !wasEnabled && stale) {
+		if err := rebuildIndex(idx); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+	}
+	indexRegistry.Lock()
+	status := indexStatus(idx)
+	indexRegistry.Unlock()
+	writeJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+type integrityResult struct {
+	Verified     bool   `json:"verified"`
+	ExpectedHash string `json:"expected_hash"`
+	ActualHash   string `json:"actual_hash"`
+}
+
+type scrubReport struct {
+	StartedAt  store.Timestamp   `json:"started_at"`
+	FinishedAt store.Timestamp   `json:"finished_at"`
+	Checked    int               `json:"checked"`
+	Corrupted  []corruptedRecord `json:"corrupted"`
+}
+
+type corruptedRecord struct {
+	ID         string `json:"id"`
+	ActualHash string `json:"actual_hash"`
+}
+
+var lastScrub = struct {
+	sync.Mutex
+	report *scrubReport
+}{}
+
+// verifyRecord recomputes the hash of the stored value and compares it with
+// the stored sha256_hash property, and the record ID derived from it, the
+// tenant and the collection with the stored ID.
+func verifyRecord(item store.StoredString) integrityResult {
+	actual := recordID(storedTenant(item), item.Collection, item.Value)
+	return integrityResult{
+		Verified:     actual == item.ID && analysis.Hash(item.Value) == item.Properties.SHA256Hash,
+		ExpectedHash: item.ID,
+		ActualHash:   actual,
+	}
+}
+
+func runScrub() (scrubReport, error) {
+	report := scrubReport{StartedAt: store.Now(), Corrupted: []corruptedRecord{}}
+	items, err := db.List()
+	if err != nil {
+		return report, err
+	}
+	for _, item := range items {
+		report.Checked++
+		if res := verifyRecord(item); !res.Verified {
+			report.Corrupted = append(report.Corrupted, corruptedRecord{ID: item.ID, ActualHash: res.ActualHash})
+		}
+	}
+	report.FinishedAt = store.Now()
+	lastScrub.Lock()
+	lastScrub.report = &report
+	lastScrub.Unlock()
+	return report, nil
+}
+
+func startScrubber(interval time.Duration) {
+	every(interval, func() {
+		if _, err := runScrub(); err != nil {
+			logger.Error("scrub failed", "error", err)
+		}
+	})
+}
+
+func scrubReportHandler(w http.ResponseWriter, r *http.Request) {
+	lastScrub.Lock()
+	report := lastScrub.report
+	lastScrub.Unlock()
+	if report == nil {
+		writeError(w, http.StatusNotFound, "NO_SCRUB_REPORT", "no scrub has run yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func scrubHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := runScrub()
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
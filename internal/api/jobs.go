@@ -0,0 +1,407 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	defaultImportChunkSize = 100
+	maxImportChunkSize     = 10000
+	maxQueuedJobs          = 16
+	defaultJobWorkers      = 4
+)
+
+type chunkError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+type jobChunk struct {
+	Index     int          `json:"index"`
+	Start     int          `json:"start"`
+	End       int          `json:"end"`
+	Status    string       `json:"status"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Errors    []chunkError `json:"errors"`
+}
+
+// importJob is a chunked background job. Import jobs insert Values; reanalyze
+// jobs re-run analysis on the record IDs in Values.
+type importJob struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`
+	Status     string          `json:"status"`
+	Collection string          `json:"collection,omitempty"`
+	Tenant     string          `json:"tenant,omitempty"`
+	Total      int             `json:"total"`
+	Processed  int             `json:"processed"`
+	Succeeded  int             `json:"succeeded"`
+	Failed     int             `json:"failed"`
+	Chunks     []*jobChunk     `json:"chunks"`
+	CreatedAt  store.Timestamp `json:"created_at"`
+	UpdatedAt  store.Timestamp `json:"updated_at"`
+	Values     []interface{}   `json:"values,omitempty"`
+}
+
+type reanalyzeReq struct {
+	Collection     string `json:"collection"`
+	ChunkSize      int    `json:"chunk_size"`
+	IncompleteOnly bool   `json:"incomplete_only"`
+}
+
+type importReq struct {
+	Values     []interface{} `json:"values"`
+	Collection string        `json:"collection"`
+	ChunkSize  int           `json:"chunk_size"`
+}
+
+var jobs = struct {
+	sync.Mutex
+	dir   string
+	m     map[string]*importJob
+	queue chan string
+}{m: map[string]*importJob{}, queue: make(chan string, maxQueuedJobs)}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// persistJob writes the job, including its payload, so an interrupted import
+// can resume from its first unfinished chunk. Callers hold jobs.Mutex.
+func persistJob(job *importJob) error {
+	if jobs.dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(jobs.dir, job.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// snapshot returns a copy of the job without its payload for responses.
+func (j *importJob) snapshot() importJob {
+	c := *j
+	c.Values = nil
+	c.Chunks = make([]*jobChunk, len(j.Chunks))
+	for i, ch := range j.Chunks {
+		cc := *ch
+		cc.Errors = append([]chunkError{}, ch.Errors...)
+		c.Chunks[i] = &cc
+	}
+	return c
+}
+
+// startJobs loads the jobs persisted in dir and starts workers goroutines
+// that run queued jobs concurrently.
+func startJobs(dir string, workers int) error {
+	jobs.dir = dir
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				return err
+			}
+			var job importJob
+			if err := json.Unmarshal(data, &job); err != nil {
+				return fmt.Errorf("job %s: %w", e.Name(), err)
+			}
+			jobs.m[job.ID] = &job
+			if job.Status == "queued" || job.Status == "running" {
+				job.Status = "queued"
+				select {
+				case jobs.queue <- job.ID:
+				default:
+					job.Status = "failed"
+				}
+			}
+		}
+	}
+	for i := 0; i < workers; i++ {
+		goBackground(runJobs)
+	}
+	return nil
+}
+
+func runJobs(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-jobs.queue:
+			runImportJob(ctx, id)
+		}
+	}
+}
+
+// runImportJob processes the chunks of job id in order. At shutdown it
+// stops after the chunk in progress, leaving the job running so it is
+// queued again, from its first unfinished chunk, on the next start.
+func runImportJob(ctx context.Context, id string) {
+	jobs.Lock()
+	job, ok := jobs.m[id]
+	if !ok || job.Status != "queued" {
+		jobs.Unlock()
+		return
+	}
+	job.Status = "running"
+	_ = persistJob(job)
+	opts := collectionOptions(job.tenant(), job.Collection)
+	jobs.Unlock()
+	for _, chunk := range job.Chunks {
+		if ctx.Err() != nil {
+			return
+		}
+		jobs.Lock()
+		if job.Status == "cancelled" {
+			jobs.Unlock()
+			return
+		}
+		if chunk.Status == "completed" {
+			jobs.Unlock()
+			continue
+		}
+		chunk.Status = "running"
+		values := job.Values[chunk.Start:chunk.End]
+		jobs.Unlock()
+
+		succeeded, failed := 0, 0
+		errs := []chunkError{}
+		for i, v := range values {
+			if err := job.process(v, opts); err != nil {
+				failed++
+				errs = append(errs, chunkError{Index: chunk.Start + i, Error: err.Error()})
+				continue
+			}
+			succeeded++
+		}
+
+		jobs.Lock()
+		chunk.Status = "completed"
+		chunk.Succeeded, chunk.Failed, chunk.Errors = succeeded, failed, errs
+		job.Processed += len(values)
+		job.Succeeded += succeeded
+		job.Failed += failed
+		job.UpdatedAt = store.Now()
+		_ = persistJob(job)
+		jobs.Unlock()
+	}
+	jobs.Lock()
+	if job.Status == "running" {
+		job.Status = "completed"
+		job.UpdatedAt = store.Now()
+		job.Values = nil
+		_ = persistJob(job)
+	}
+	jobs.Unlock()
+}
+
+func (j *importJob) process(v interface{}, opts analysis.Options) error {
+	if j.Kind == "reanalyze" {
+		id, _ := v.(string)
+		_, err := reanalyzeString(id, opts, nil)
+		return err
+	}
+	val, _, err := validateCreateBody(CreateReq{Value: v})
+	if err != nil {
+		return err
+	}
+	_, err = insertString(val, j.Collection, j.Tenant, opts, 0, nil)
+	return err
+}
+
+// tenant is the tenant that queued the job; jobs persisted before tenants
+// were recorded belong to the default one.
+func (j *importJob) tenant() string {
+	if j.Tenant == "" {
+		return defaultTenant
+	}
+	return j.Tenant
+}
+
+func createImportJob(body importReq, tenant string) (*importJob, error) {
+	return queueJob("import", body.Collection, tenant, body.Values, body.ChunkSize)
+}
+
+func queueJob(kind, collection, tenant string, values []interface{}, size int) (*importJob, error) {
+	if size == 0 {
+		size = defaultImportChunkSize
+	}
+	now := store.Now()
+	job := &importJob{
+		ID:         newJobID(),
+		Kind:       kind,
+		Status:     "queued",
+		Collection: collection,
+		Tenant:     tenant,
+		Total:      len(values),
+		Chunks:     []*jobChunk{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Values:     values,
+	}
+	for start := 0; start < len(values); start += size {
+		end := start + size
+		if end > len(values) {
+			end = len(values)
+		}
+		job.Chunks = append(job.Chunks, &jobChunk{Index: len(job.Chunks), Start: start, End: end, Status: "pending", Errors: []chunkError{}})
+	}
+	jobs.Lock()
+	defer jobs.Unlock()
+	select {
+	case jobs.queue <- job.ID:
+	default:
+		return nil, errors.New("too many queued jobs, retry later")
+	}
+	jobs.m[job.ID] = job
+	if err := persistJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func importJobHandler(w http.ResponseWriter, r *http.Request) {
+	var body importReq
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if len(body.Values) == 0 {
+		writeError(w, http.StatusBadRequest, "MISSING_VALUES", `"values" must be a non-empty array`)
+		return
+	}
+	if body.ChunkSize < 0 || body.ChunkSize > maxImportChunkSize {
+		writeError(w, http.StatusBadRequest, "INVALID_CHUNK_SIZE", fmt.Sprintf("chunk_size must be between 1 and %d", maxImportChunkSize))
+		return
+	}
+	if body.Collection != "" && !collectionNamePattern.MatchString(body.Collection) {
+		writeError(w, http.StatusBadRequest, "INVALID_COLLECTION", "invalid collection name")
+		return
+	}
+	job, err := createImportJob(body, tenantOf(r))
+	if err != nil {
+		w.Header().Set("Retry-After", "5")
+		writeError(w, http.StatusServiceUnavailable, "JOB_QUEUE_FULL", err.Error())
+		return
+	}
+	jobs.Lock()
+	snap := job.snapshot()
+	jobs.Unlock()
+	w.Header().Set("Location", versionedPath(r, "/jobs/"+job.ID))
+	writeJSON(w, http.StatusAccepted, snap)
+}
+
+// reanalyzeJobHandler queues a job that re-runs analysis on every record of a
+// collection with its current configuration, e.g. after changing tokenizer.
+func reanalyzeJobHandler(w http.ResponseWriter, r *http.Request) {
+	var body reanalyzeReq
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if body.ChunkSize < 0 || body.ChunkSize > maxImportChunkSize {
+		writeError(w, http.StatusBadRequest, "INVALID_CHUNK_SIZE", fmt.Sprintf("chunk_size must be between 1 and %d", maxImportChunkSize))
+		return
+	}
+	if body.Collection != "" && !collectionNamePattern.MatchString(body.Collection) {
+		writeError(w, http.StatusBadRequest, "INVALID_COLLECTION", "invalid collection name")
+		return
+	}
+	items, err := db.Filter(func(item store.StoredString) bool {
+		return item.Collection == body.Collection && storedTenant(item) == tenantOf(r) && (!body.IncompleteOnly || len(item.Properties.AnalyzerStatus) > 0)
+	})
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	ids := make([]interface{}, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	job, err := queueJob("reanalyze", body.Collection, tenantOf(r), ids, body.ChunkSize)
+	if err != nil {
+		w.Header().Set("Retry-After", "5")
+		writeError(w, http.StatusServiceUnavailable, "JOB_QUEUE_FULL", err.Error())
+		return
+	}
+	jobs.Lock()
+	snap := job.snapshot()
+	jobs.Unlock()
+	w.Header().Set("Location", versionedPath(r, "/jobs/"+job.ID))
+	writeJSON(w, http.StatusAccepted, snap)
+}
+
+// jobOf finds the job named by the request path, answering 404 unless it
+// belongs to the request's tenant.
+func jobOf(w http.ResponseWriter, r *http.Request) *importJob {
+	jobs.Lock()
+	job, ok := jobs.m[r.PathValue("id")]
+	jobs.Unlock()
+	if !ok || job.tenant() != tenantOf(r) {
+		writeError(w, http.StatusNotFound, "JOB_NOT_FOUND", "job does not exist")
+		return nil
+	}
+	return job
+}
+
+func jobHandler(w http.ResponseWriter, r *http.Request) {
+	job := jobOf(w, r)
+	if job == nil {
+		return
+	}
+	jobs.Lock()
+	snap := job.snapshot()
+	jobs.Unlock()
+	writeJSON(w, http.StatusOK, snap)
+}
+
+func cancelJobHandler(w http.ResponseWriter, r *http.Request) {
+	job := jobOf(w, r)
+	if job == nil {
+		return
+	}
+	jobs.Lock()
+	defer jobs.Unlock()
+	if job.Status != "queued" && job.Status != "running" {
+		writeError(w, http.StatusConflict, "JOB_FINISHED", "job is already "+job.Status)
+		return
+	}
+	job.Status = "cancelled"
+	job.Values = nil
+	job.UpdatedAt = store.Now()
+	_ = persistJob(job)
+	writeJSON(w, http.StatusOK, job.snapshot())
+}
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	jwtLeeway             = time.Minute
+	jwksMinRefresh        = time.Minute
+	defaultJWKSRefresh    = time.Hour
+	defaultJWTTenantClaim = "tenant"
+	defaultJWTRoleClaim   = "role"
+)
+
+var errInvalidToken = errors.New("invalid bearer token")
+
+// jwtAuth validates bearer tokens signed with HS256 by secret, or with
+// RS256 by publicKey or a key of the JWKS at jwksURL. Claims name the
+// tenant and role; identity provider roles map to ours through roleMap, and
+// our own role names need no mapping. Tokens must expire unless allowNoExp.
+var jwtAuth = struct {
+	sync.Mutex
+	secret      []byte
+	publicKey   *rsa.PublicKey
+	jwksURL     string
+	jwks        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	refresh     time.Duration
+	client      *http.Client
+	issuer      string
+	audience    string
+	tenantClaim string
+	roleClaim   string
+	roleMap     map[string]string
+	allowNoExp  bool
+}{refresh: defaultJWKSRefresh, tenantClaim: defaultJWTTenantClaim, roleClaim: defaultJWTRoleClaim, client: &http.Client{Timeout: 10 * time.Second}}
+
+func jwtEnabled() bool {
+	return jwtAuth.secret != nil || jwtAuth.publicKey != nil || jwtAuth.jwksURL != ""
+}
+
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		if key, ok := cert.PublicKey.(*rsa.PublicKey); ok {
+			return key, nil
+		}
+		return nil, errors.New("certificate does not hold an RSA key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA public key")
+	}
+	return rsaKey, nil
+}
+
+// configureJWT applies the JWT_* settings.
+func configureJWT() error {
+	if v := os.Getenv("JWT_HS256_SECRET"); v != "" {
+		jwtAuth.secret = []byte(v)
+	}
+	if path := os.Getenv("JWT_PUBLIC_KEY_PATH"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if jwtAuth.publicKey, err = parseRSAPublicKey(data); err != nil {
+			return fmt.Errorf("invalid JWT_PUBLIC_KEY_PATH: %w", err)
+		}
+	}
+	jwtAuth.jwksURL = os.Getenv("JWT_JWKS_URL")
+	if v := os.Getenv("JWT_JWKS_REFRESH_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid JWT_JWKS_REFRESH_SECONDS: %s", v)
+		}
+		jwtAuth.refresh = time.Duration(n) * time.Second
+	}
+	if v := os.Getenv("JWT_ALLOW_NO_EXP"); v != "" {
+		allow, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid JWT_ALLOW_NO_EXP: %s", v)
+		}
+		jwtAuth.allowNoExp = allow
+	}
+	jwtAuth.issuer = os.Getenv("JWT_ISSUER")
+	jwtAuth.audience = os.Getenv("JWT_AUDIENCE")
+	if v := os.Getenv("JWT_TENANT_CLAIM"); v != "" {
+		jwtAuth.tenantClaim = v
+	}
+	if v := os.Getenv("JWT_ROLE_CLAIM"); v != "" {
+		jwtAuth.roleClaim = v
+	}
+	if v := os.Getenv("JWT_ROLE_MAP"); v != "" {
+		jwtAuth.roleMap = map[string]string{}
+		for _, entry := range strings.Split(v, ",") {
+			claim, role, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if role = normalizeRole(role); !ok || claim == "" || role == "" {
+				return fmt.Errorf("invalid JWT_ROLE_MAP entry %q", entry)
+			}
+			jwtAuth.roleMap[claim] = role
+		}
+	}
+	return nil
+}
+
+type jwksFile struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// fetchJWKS loads the RSA signing keys of the JWKS at url.
+func fetchJWKS(client *http.Client, url string) (map[string]*rsa.PublicKey, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS answered %d", resp.StatusCode)
+	}
+	var file jwksFile
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, err
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range file.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+		e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+		if err1 != nil || err2 != nil || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+// rsaKeyFor finds the RS256 key for kid, refetching the JWKS when it is due
+// or the key is unknown, at most once a minute. The fetch runs without the
+// lock, so other tokens verify against the current keys meanwhile; the
+// request that claims a refresh is the only one to make it.
+func rsaKeyFor(kid string) *rsa.PublicKey {
+	jwtAuth.Lock()
+	defer jwtAuth.Unlock()
+	if jwtAuth.jwksURL == "" {
+		return jwtAuth.publicKey
+	}
+	key, ok := jwtAuth.jwks[kid]
+	since := time.Since(jwtAuth.fetchedAt)
+	if (!ok && since >= jwksMinRefresh) || since >= jwtAuth.refresh {
+		jwtAuth.fetchedAt = time.Now()
+		client, url := jwtAuth.client, jwtAuth.jwksURL
+		jwtAuth.Unlock()
+		keys, err := fetchJWKS(client, url)
+		jwtAuth.Lock()
+		if err != nil {
+			logger.Error("JWKS fetch failed", "error", err)
+		} else {
+			jwtAuth.jwks = keys
+		}
+		key, ok = jwtAuth.jwks[kid]
+	}
+	if !ok {
+		return jwtAuth.publicKey
+	}
+	return key
+}
+
+func verifyJWTSignature(alg, kid, signed string, sig []byte) bool {
+	switch alg {
+	case "HS256":
+		if jwtAuth.secret == nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, jwtAuth.secret)
+		mac.Write([]byte(signed))
+		return hmac.Equal(mac.Sum(nil), sig)
+	case "RS256":
+		key := rsaKeyFor(kid)
+		if key == nil {
+			return false
+		}
+		sum := sha256.Sum256([]byte(signed))
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig) == nil
+	}
+	return false
+}
+
+// numericClaim reads a NumericDate claim; ok is false when it is absent.
+func numericClaim(claims map[string]interface{}, name string) (time.Time, bool, error) {
+	v, present := claims[name]
+	if !present {
+		return time.Time{}, false, nil
+	}
+	n, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false, errInvalidToken
+	}
+	return time.Unix(int64(n), 0), true, nil
+}
+
+// claimStrings reads a claim holding a string or an array of strings.
+func claimStrings(v interface{}) []string {
+	switch c := v.(type) {
+	case string:
+		return []string{c}
+	case []interface{}:
+		out := []string{}
+		for _, s := range c {
+			if str, ok := s.(string); ok {
+				out = append(out, str)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// authenticateJWT validates a bearer token and returns the identity it
+// grants: its subject, tenant and the most privileged of its roles.
+func authenticateJWT(token string) (apiKey, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return apiKey{}, errInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	var claims map[string]interface{}
+	h, err1 := base64.RawURLEncoding.DecodeString(parts[0])
+	c, err2 := base64.RawURLEncoding.DecodeString(parts[1])
+	sig, err3 := base64.RawURLEncoding.DecodeString(parts[2])
+	if err1 != nil || err2 != nil || err3 != nil || json.Unmarshal(h, &header) != nil || json.Unmarshal(c, &claims) != nil {
+		return apiKey{}, errInvalidToken
+	}
+	if !verifyJWTSignature(header.Alg, header.Kid, parts[0]+"."+parts[1], sig) {
+		return apiKey{}, errInvalidToken
+	}
+	now := time.Now()
+	exp, hasExp, err := numericClaim(claims, "exp")
+	if err != nil || (hasExp && !now.Before(exp.Add(jwtLeeway))) {
+		return apiKey{}, errors.New("bearer token has expired")
+	}
+	if !hasExp && !jwtAuth.allowNoExp {
+		return apiKey{}, errors.New("bearer token has no expiry")
+	}
+	nbf, hasNbf, err := numericClaim(claims, "nbf")
+	if err != nil || (hasNbf && now.Add(jwtLeeway).Before(nbf)) {
+		return apiKey{}, errors.New("bearer token is not valid yet")
+	}
+	if jwtAuth.issuer != "" && claims["iss"] != jwtAuth.issuer {
+		return apiKey{}, errors.New("bearer token has the wrong issuer")
+	}
+	if jwtAuth.audience != "" && !slices.Contains(claimStrings(claims["aud"]), jwtAuth.audience) {
+		return apiKey{}, errors.New("bearer token has the wrong audience")
+	}
+	tenant := defaultTenant
+	if t, ok := claims[jwtAuth.tenantClaim].(string); ok && t != "" {
+		tenant = t
+	}
+	if !tenantIDPattern.MatchString(tenant) {
+		return apiKey{}, errors.New("bearer token names an invalid tenant")
+	}
+	role := ""
+	for _, claim := range claimStrings(claims[jwtAuth.roleClaim]) {
+		r, ok := jwtAuth.roleMap[claim]
+		if !ok {
+			r = normalizeRole(claim)
+		}
+		if roleRank(r) > roleRank(role) {
+			role = r
+		}
+	}
+	if role == "" {
+		return apiKey{}, errors.New("bearer token grants no known role")
+	}
+	sub, _ := claims["sub"].(string)
+	return apiKey{ID: "jwt:" + sub, Tenant: tenant, Role: role}, nil
+}
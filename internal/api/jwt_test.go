@@ -0,0 +1,385 @@
+package api
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testJWTSecret = "test-jwt-secret"
+
+// resetJWT clears the JWT settings for a test and restores them after it.
+func resetJWT(t *testing.T) {
+	t.Helper()
+	jwtAuth.Lock()
+	secret, publicKey, jwksURL, jwks, fetchedAt := jwtAuth.secret, jwtAuth.publicKey, jwtAuth.jwksURL, jwtAuth.jwks, jwtAuth.fetchedAt
+	issuer, audience, roleMap, allowNoExp := jwtAuth.issuer, jwtAuth.audience, jwtAuth.roleMap, jwtAuth.allowNoExp
+	jwtAuth.secret, jwtAuth.publicKey, jwtAuth.jwksURL, jwtAuth.jwks, jwtAuth.fetchedAt = nil, nil, "", nil, time.Time{}
+	jwtAuth.issuer, jwtAuth.audience, jwtAuth.roleMap, jwtAuth.allowNoExp = "", "", nil, false
+	jwtAuth.Unlock()
+	t.Cleanup(func() {
+		jwtAuth.Lock()
+		defer jwtAuth.Unlock()
+		jwtAuth.secret, jwtAuth.publicKey, jwtAuth.jwksURL, jwtAuth.jwks, jwtAuth.fetchedAt = secret, publicKey, jwksURL, jwks, fetchedAt
+		jwtAuth.issuer, jwtAuth.audience, jwtAuth.roleMap, jwtAuth.allowNoExp = issuer, audience, roleMap, allowNoExp
+	})
+}
+
+func encodeJWT(t *testing.T, header, claims map[string]interface{}, sign func(signed string) []byte) string {
+	t.Helper()
+	h, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign(signed))
+}
+
+func hs256(key []byte) func(string) []byte {
+	return func(signed string) []byte {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		return mac.Sum(nil)
+	}
+}
+
+func rs256(t *testing.T, key *rsa.PrivateKey) func(string) []byte {
+	return func(signed string) []byte {
+		sum := sha256.Sum256([]byte(signed))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+}
+
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// validClaims are claims every configuration in the tests accepts.
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":  "user-1",
+		"role": "editor",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	rsaKey := testRSAKey(t)
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	with := func(changes map[string]interface{}) map[string]interface{} {
+		claims := validClaims()
+		for k, v := range changes {
+			if v == nil {
+				delete(claims, k)
+			} else {
+				claims[k] = v
+			}
+		}
+		return claims
+	}
+	hsHeader := map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+	rsHeader := map[string]interface{}{"alg": "RS256", "typ": "JWT"}
+	tests := []struct {
+		name      string
+		configure func()
+		token     func() string
+		wantErr   string
+		want      apiKey
+	}{
+		{
+			name:  "valid HS256",
+			token: func() string { return encodeJWT(t, hsHeader, validClaims(), hs256([]byte(testJWTSecret))) },
+			want:  apiKey{ID: "jwt:user-1", Tenant: defaultTenant, Role: "editor"},
+		},
+		{
+			name:    "wrong HS256 secret",
+			token:   func() string { return encodeJWT(t, hsHeader, validClaims(), hs256([]byte("other"))) },
+			wantErr: errInvalidToken.Error(),
+		},
+		{
+			name: "alg none",
+			token: func() string {
+				return encodeJWT(t, map[string]interface{}{"alg": "none"}, validClaims(), func(string) []byte { return nil })
+			},
+			wantErr: errInvalidToken.Error(),
+		},
+		{
+			name:    "RS256 without an RSA key",
+			token:   func() string { return encodeJWT(t, rsHeader, validClaims(), rs256(t, rsaKey)) },
+			wantErr: errInvalidToken.Error(),
+		},
+		{
+			name:      "valid RS256",
+			configure: func() { jwtAuth.secret, jwtAuth.publicKey = nil, &rsaKey.PublicKey },
+			token:     func() string { return encodeJWT(t, rsHeader, validClaims(), rs256(t, rsaKey)) },
+			want:      apiKey{ID: "jwt:user-1", Tenant: defaultTenant, Role: "editor"},
+		},
+		{
+			// An HS256 token keyed with the RSA public key must not pass
+			// when only the RSA key is configured.
+			name:      "HS256 signed with the RSA public key",
+			configure: func() { jwtAuth.secret, jwtAuth.publicKey = nil, &rsaKey.PublicKey },
+			token:     func() string { return encodeJWT(t, hsHeader, validClaims(), hs256(publicDER)) },
+			wantErr:   errInvalidToken.Error(),
+		},
+		{
+			name:    "malformed",
+			token:   func() string { return "not.a-token" },
+			wantErr: errInvalidToken.Error(),
+		},
+		{
+			name: "expired",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"exp": now.Add(-2 * jwtLeeway).Unix()}), hs256([]byte(testJWTSecret)))
+			},
+			wantErr: "bearer token has expired",
+		},
+		{
+			name: "expired within the leeway",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"exp": now.Add(-jwtLeeway / 2).Unix()}), hs256([]byte(testJWTSecret)))
+			},
+			want: apiKey{ID: "jwt:user-1", Tenant: defaultTenant, Role: "editor"},
+		},
+		{
+			name: "exp is not a number",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"exp": "tomorrow"}), hs256([]byte(testJWTSecret)))
+			},
+			wantErr: "bearer token has expired",
+		},
+		{
+			name: "no exp",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"exp": nil}), hs256([]byte(testJWTSecret)))
+			},
+			wantErr: "bearer token has no expiry",
+		},
+		{
+			name:      "no exp when allowed",
+			configure: func() { jwtAuth.allowNoExp = true },
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"exp": nil}), hs256([]byte(testJWTSecret)))
+			},
+			want: apiKey{ID: "jwt:user-1", Tenant: defaultTenant, Role: "editor"},
+		},
+		{
+			name: "not valid yet",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"nbf": now.Add(2 * jwtLeeway).Unix()}), hs256([]byte(testJWTSecret)))
+			},
+			wantErr: "bearer token is not valid yet",
+		},
+		{
+			name: "nbf within the leeway",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"nbf": now.Add(jwtLeeway / 2).Unix()}), hs256([]byte(testJWTSecret)))
+			},
+			want: apiKey{ID: "jwt:user-1", Tenant: defaultTenant, Role: "editor"},
+		},
+		{
+			name:      "wrong issuer",
+			configure: func() { jwtAuth.issuer = "https://idp.example" },
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"iss": "https://other.example"}), hs256([]byte(testJWTSecret)))
+			},
+			wantErr: "bearer token has the wrong issuer",
+		},
+		{
+			name:      "missing issuer",
+			configure: func() { jwtAuth.issuer = "https://idp.example" },
+			token:     func() string { return encodeJWT(t, hsHeader, validClaims(), hs256([]byte(testJWTSecret))) },
+			wantErr:   "bearer token has the wrong issuer",
+		},
+		{
+			name:      "matching issuer",
+			configure: func() { jwtAuth.issuer = "https://idp.example" },
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"iss": "https://idp.example"}), hs256([]byte(testJWTSecret)))
+			},
+			want: apiKey{ID: "jwt:user-1", Tenant: defaultTenant, Role: "editor"},
+		},
+		{
+			name:      "wrong audience",
+			configure: func() { jwtAuth.audience = "strings-api" },
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"aud": "billing"}), hs256([]byte(testJWTSecret)))
+			},
+			wantErr: "bearer token has the wrong audience",
+		},
+		{
+			name:      "audience in a list",
+			configure: func() { jwtAuth.audience = "strings-api" },
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"aud": []string{"billing", "strings-api"}}), hs256([]byte(testJWTSecret)))
+			},
+			want: apiKey{ID: "jwt:user-1", Tenant: defaultTenant, Role: "editor"},
+		},
+		{
+			name: "tenant claim",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"tenant": "acme"}), hs256([]byte(testJWTSecret)))
+			},
+			want: apiKey{ID: "jwt:user-1", Tenant: "acme", Role: "editor"},
+		},
+		{
+			name: "invalid tenant",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"tenant": "no spaces allowed"}), hs256([]byte(testJWTSecret)))
+			},
+			wantErr: "bearer token names an invalid tenant",
+		},
+		{
+			name:      "mapped role",
+			configure: func() { jwtAuth.roleMap = map[string]string{"ops": "admin"} },
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"role": "ops"}), hs256([]byte(testJWTSecret)))
+			},
+			want: apiKey{ID: "jwt:user-1", Tenant: defaultTenant, Role: "admin"},
+		},
+		{
+			name: "most privileged of several roles",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"role": []string{"viewer", "unknown", "editor"}}), hs256([]byte(testJWTSecret)))
+			},
+			want: apiKey{ID: "jwt:user-1", Tenant: defaultTenant, Role: "editor"},
+		},
+		{
+			name: "legacy scope as role",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"role": "read"}), hs256([]byte(testJWTSecret)))
+			},
+			want: apiKey{ID: "jwt:user-1", Tenant: defaultTenant, Role: "viewer"},
+		},
+		{
+			name: "unknown role",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"role": "superuser"}), hs256([]byte(testJWTSecret)))
+			},
+			wantErr: "bearer token grants no known role",
+		},
+		{
+			name: "no role",
+			token: func() string {
+				return encodeJWT(t, hsHeader, with(map[string]interface{}{"role": nil}), hs256([]byte(testJWTSecret)))
+			},
+			wantErr: "bearer token grants no known role",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetJWT(t)
+			jwtAuth.secret = []byte(testJWTSecret)
+			if tt.configure != nil {
+				tt.configure()
+			}
+			got, err := authenticateJWT(tt.token())
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("identity = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func jwksHandler(kid string, key *rsa.PublicKey) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}
+}
+
+func TestJWKSKeyByKid(t *testing.T) {
+	resetJWT(t)
+	key := testRSAKey(t)
+	srv := httptest.NewServer(jwksHandler("k1", &key.PublicKey))
+	defer srv.Close()
+	jwtAuth.jwksURL = srv.URL
+	for _, tt := range []struct {
+		kid  string
+		want bool
+	}{{"k1", true}, {"k2", false}} {
+		token := encodeJWT(t, map[string]interface{}{"alg": "RS256", "kid": tt.kid}, validClaims(), rs256(t, key))
+		_, err := authenticateJWT(token)
+		if got := err == nil; got != tt.want {
+			t.Errorf("kid %s: accepted = %v, want %v (error %v)", tt.kid, got, tt.want, err)
+		}
+	}
+}
+
+// TestJWKSFetchDoesNotBlock checks that tokens signed by a known key verify
+// while a refetch for an unknown kid waits on a slow JWKS endpoint.
+func TestJWKSFetchDoesNotBlock(t *testing.T) {
+	resetJWT(t)
+	key := testRSAKey(t)
+	release := make(chan struct{})
+	fetching := make(chan struct{}, 1)
+	keys := jwksHandler("k1", &key.PublicKey)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetching <- struct{}{}
+		<-release
+		keys(w, r)
+	}))
+	defer srv.Close()
+	defer close(release)
+	jwtAuth.jwksURL = srv.URL
+	jwtAuth.jwks = map[string]*rsa.PublicKey{"k1": &key.PublicKey}
+	jwtAuth.fetchedAt = time.Now().Add(-jwksMinRefresh)
+
+	token := encodeJWT(t, map[string]interface{}{"alg": "RS256", "kid": "k1"}, validClaims(), rs256(t, key))
+	go rsaKeyFor("rotated")
+	<-fetching
+	done := make(chan error, 1)
+	go func() {
+		_, err := authenticateJWT(token)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("token signed by a known key rejected: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("verification waited for the JWKS fetch")
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const benchRecords = 100000
+
+type discardResponse struct{ header http.Header }
+
+func (d *discardResponse) Header() http.Header         { return d.header }
+func (d *discardResponse) Write(b []byte) (int, error) { return len(b), nil }
+func (d *discardResponse) WriteHeader(int)             {}
+
+func benchRecordSet(b *testing.B) []store.StoredString {
+	b.Helper()
+	mem := store.NewMemory()
+	records := make([]store.StoredString, 0, benchRecords)
+	created := store.NewTimestamp(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	for i := 0; i < benchRecords; i++ {
+		val := fmt.Sprintf("benchmark record %d with a few words", i)
+		item := store.StoredString{ID: analysis.Hash(val), Value: val, Properties: analysis.Analyze(val, analysis.DefaultOptions()), CreatedAt: created}
+		if err := mem.Put(item); err != nil {
+			b.Fatal(err)
+		}
+		records = append(records, item)
+	}
+	prev := db
+	db = &indexedStorage{Storage: mem}
+	forgetAllEncoded()
+	b.Cleanup(func() {
+		db = prev
+		forgetAllEncoded()
+	})
+	return records
+}
+
+// BenchmarkListingMarshal encodes a 100k-record listing the way it was done
+// before records were pre-serialized.
+func BenchmarkListingMarshal(b *testing.B) {
+	records := benchRecordSet(b)
+	w := &discardResponse{header: http.Header{}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": records, "count": len(records), "filters_applied": map[string]interface{}{}})
+	}
+}
+
+func BenchmarkListingPreEncoded(b *testing.B) {
+	records := benchRecordSet(b)
+	w := &discardResponse{header: http.Header{}}
+	version := storeVersion.Load()
+	writeRecordListing(w, http.StatusOK, records, nil, version, map[string]interface{}{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		writeRecordListing(w, http.StatusOK, records, nil, version, map[string]interface{}{"count": len(records), "filters_applied": map[string]interface{}{}})
+	}
+}
+
+// BenchmarkListingHandler serves GET /strings end to end with warm listing
+// and record caches.
+func BenchmarkListingHandler(b *testing.B) {
+	benchRecordSet(b)
+	req := httptest.NewRequest(http.MethodGet, "/strings", nil)
+	w := &discardResponse{header: http.Header{}}
+	getAllStringsHandler(w, req)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		getAllStringsHandler(w, req)
+	}
+}
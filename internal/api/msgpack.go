@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+)
+
+type orderedField struct {
+	key   string
+	value interface{}
+}
+
+// decodeOrdered reads the next JSON value of dec, keeping object keys in
+// document order as []orderedField so encodings stay deterministic.
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	if d == '[' {
+		list := []interface{}{}
+		for dec.More() {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	fields := []orderedField{}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		v, err := decodeOrdered(dec)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, orderedField{key.(string), v})
+	}
+	_, err = dec.Token()
+	return fields, err
+}
+
+func msgpackHeader(buf *bytes.Buffer, n int, fix, b8, b16, b32 byte, fixMax int) {
+	switch {
+	case n < fixMax:
+		buf.WriteByte(fix | byte(n))
+	case b8 != 0 && n <= math.MaxUint8:
+		buf.WriteByte(b8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.Write(binary.BigEndian.AppendUint16([]byte{b16}, uint16(n)))
+	default:
+		buf.Write(binary.BigEndian.AppendUint32([]byte{b32}, uint32(n)))
+	}
+}
+
+func msgpackNumber(buf *bytes.Buffer, n json.Number) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		switch {
+		case i >= 0 && i <= 0x7f, i < 0 && i >= -32:
+			buf.WriteByte(byte(i))
+		case i >= 0 && i <= math.MaxUint8:
+			buf.Write([]byte{0xcc, byte(i)})
+		case i >= 0 && i <= math.MaxUint16:
+			buf.Write(binary.BigEndian.AppendUint16([]byte{0xcd}, uint16(i)))
+		case i >= 0 && i <= math.MaxUint32:
+			buf.Write(binary.BigEndian.AppendUint32([]byte{0xce}, uint32(i)))
+		case i >= 0:
+			buf.Write(binary.BigEndian.AppendUint64([]byte{0xcf}, uint64(i)))
+		case i >= math.MinInt8:
+			buf.Write([]byte{0xd0, byte(i)})
+		case i >= math.MinInt16:
+			buf.Write(binary.BigEndian.AppendUint16([]byte{0xd1}, uint16(i)))
+		case i >= math.MinInt32:
+			buf.Write(binary.BigEndian.AppendUint32([]byte{0xd2}, uint32(i)))
+		default:
+			buf.Write(binary.BigEndian.AppendUint64([]byte{0xd3}, uint64(i)))
+		}
+		return
+	}
+	f, _ := n.Float64()
+	buf.Write(binary.BigEndian.AppendUint64([]byte{0xcb}, math.Float64bits(f)))
+}
+
+func writeMsgpack(buf *bytes.Buffer, v interface{}) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if t {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		msgpackNumber(buf, t)
+	case string:
+		msgpackHeader(buf, len(t), 0xa0, 0xd9, 0xda, 0xdb, 32)
+		buf.WriteString(t)
+	case []interface{}:
+		msgpackHeader(buf, len(t), 0x90, 0, 0xdc, 0xdd, 16)
+		for _, item := range t {
+			if err := writeMsgpack(buf, item); err != nil {
+				return err
+			}
+		}
+	case []orderedField:
+		msgpackHeader(buf, len(t), 0x80, 0, 0xde, 0xdf, 16)
+		for _, f := range t {
+			_ = writeMsgpack(buf, f.key)
+			if err := writeMsgpack(buf, f.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New("unsupported value")
+	}
+	return nil
+}
+
+// jsonToMsgpack converts a JSON document to MessagePack, keeping object
+// keys in order. Integers use the smallest fitting encoding up to int64;
+// other numbers are float64.
+func jsonToMsgpack(data []byte, w io.Writer) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeMsgpack(&buf, v); err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// xmlNamePattern matches the JSON keys usable as XML element names; other
+// keys, such as the characters of character_frequency_map, become
+// <entry key="..."> elements.
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9._-]*$`)
+
+// acceptQuality returns the highest q-value the Accept header gives any of
+// types.
+func acceptQuality(header string, types ...string) float64 {
+	best := 0.0
+	for _, part := range strings.Split(header, ",") {
+		mt, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		mt = strings.ToLower(strings.TrimSpace(mt))
+		if !slices.Contains(types, mt) {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		best = max(best, q)
+	}
+	return best
+}
+
+// responseEncoder writes response bodies in one format. Every format other
+// than JSON is converted from the JSON encoding, so field names and order
+// follow the json struct tags everywhere.
+type responseEncoder struct {
+	contentType string
+	mediaTypes  []string
+	convert     func(data []byte, w io.Writer) error
+	getOnly     bool
+}
+
+func (e responseEncoder) encode(w io.Writer, v interface{}) error {
+	if e.convert == nil {
+		return json.NewEncoder(w).Encode(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return e.convert(data, w)
+}
+
+var jsonEncoder = responseEncoder{contentType: "application/json", mediaTypes: []string{"application/json", "application/*", "*/*"}}
+
+// responseFormats are the alternatives to JSON, in the order they win
+// Accept ties among themselves. getOnly formats serve GET and HEAD only.
+var responseFormats = map[string]responseEncoder{
+	"xml":     {"application/xml; charset=utf-8", []string{"application/xml", "text/xml"}, jsonToXML, false},
+	"yaml":    {"application/yaml; charset=utf-8", []string{"application/yaml", "application/x-yaml", "text/yaml"}, jsonToYAML, false},
+	"msgpack": {"application/msgpack", []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}, jsonToMsgpack, true},
+}
+
+var formatOrder = []string{"xml", "yaml", "msgpack"}
+
+func formatAllowed(e responseEncoder, r *http.Request) bool {
+	return !e.getOnly || r.Method == http.MethodGet || r.Method == http.MethodHead
+}
+
+// ownFormatRoutes are the routes left out of negotiation: the export reads
+// the format parameter itself, and the API docs are served as they are.
+var ownFormatRoutes = map[string]bool{"/strings/export": true, "/openapi.json": true, "/docs": true}
+
+// negotiateFormat picks the response format from the `format` parameter,
+// or else the format the Accept header prefers to JSON. It returns "json"
+// when JSON wins, including ties and an absent Accept header.
+func negotiateFormat(r *http.Request) (string, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		e, ok := responseFormats[f]
+		if !ok && f != "json" {
+			return "", fmt.Errorf("invalid format %q, expected json, xml, yaml or msgpack", f)
+		}
+		if ok && !formatAllowed(e, r) {
+			return "", fmt.Errorf("format %s is only available on GET requests", f)
+		}
+		return f, nil
+	}
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return "json", nil
+	}
+	best, bestQ := "json", acceptQuality(accept, jsonEncoder.mediaTypes...)
+	for _, name := range formatOrder {
+		e := responseFormats[name]
+		if !formatAllowed(e, r) {
+			continue
+		}
+		if q := acceptQuality(accept, e.mediaTypes...); q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+	return best, nil
+}
+
+func xmlElement(name string) xml.StartElement {
+	if xmlNamePattern.MatchString(name) && !strings.HasPrefix(strings.ToLower(name), "xml") {
+		return xml.StartElement{Name: xml.Name{Local: name}}
+	}
+	return xml.StartElement{Name: xml.Name{Local: "entry"}, Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}}}
+}
+
+// writeXMLValue converts the next JSON value of dec to an element. Object
+// keys keep their order and become child elements, array members become
+// <item> elements, and null becomes an empty element.
+func writeXMLValue(dec *json.Decoder, enc *xml.Encoder, start xml.StartElement) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		for dec.More() {
+			child := xml.StartElement{Name: xml.Name{Local: "item"}}
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = xmlElement(key.(string))
+			}
+			if err := writeXMLValue(dec, enc, child); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case string:
+		err = enc.EncodeToken(xml.CharData(t))
+	case json.Number:
+		err = enc.EncodeToken(xml.CharData(t))
+	case bool:
+		err = enc.EncodeToken(xml.CharData(strconv.FormatBool(t)))
+	}
+	if err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// jsonToXML converts a JSON document to XML under a <response> root.
+func jsonToXML(data []byte, w io.Writer) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := writeXMLValue(dec, enc, xml.StartElement{Name: xml.Name{Local: "response"}}); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("trailing data after JSON document")
+	}
+	return enc.Flush()
+}
+
+// withContentNegotiation serves responses as XML, YAML or MessagePack to
+// clients that ask for it, converting the JSON the handlers write. JSON
+// remains the default.
+func withContentNegotiation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ownFormatRoutes[routeOf(r)] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept")
+		name, err := negotiateFormat(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "UNSUPPORTED_FORMAT", err.Error())
+			return
+		}
+		format, ok := responseFormats[name]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		buf := newBufferedResponse(w)
+		next.ServeHTTP(buf, r)
+		if !strings.HasPrefix(buf.header.Get("Content-Type"), "application/json") {
+			buf.flushTo(w)
+			return
+		}
+		var out bytes.Buffer
+		if err := format.encode(&out, json.RawMessage(buf.body.Bytes())); err != nil {
+			buf.flushTo(w)
+			return
+		}
+		buf.body.Reset()
+		buf.body.Write(out.Bytes())
+		buf.header.Set("Content-Type", format.contentType)
+		buf.header.Del("Content-Length")
+		buf.flushTo(w)
+	})
+}
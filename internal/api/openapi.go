@@ -0,0 +1,545 @@
+package api
+
+//go:generate go run ../../cmd/openapi-gen -o openapi.json
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+// openAPIDocument is the generated document served at /openapi.json. Run
+// go generate ./... after changing a route, its parameters or the types it
+// reads and writes.
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// jsonSchema is a literal JSON Schema.
+type jsonSchema map[string]interface{}
+
+// objectSchema describes an object by example: each property is a Go value
+// of the type it has, or a jsonSchema or objectSchema.
+type objectSchema map[string]interface{}
+
+// apiOperation documents one route. body and response are Go values whose
+// types are described the way encoding/json encodes them; a nil response is
+// a free-form object.
+type apiOperation struct {
+	pattern  string
+	summary  string
+	params   paramSchema
+	filters  bool
+	body     interface{}
+	bodyType string
+	status   int
+	response interface{}
+	produces string
+	public   bool
+	// unversioned routes are served at the root rather than under the API
+	// version.
+	unversioned bool
+}
+
+var (
+	fieldsParam   = stringParam("fields", "")
+	analysisQuery = paramSchema{stringParam("collection", ""), boolParam("case_insensitive", false), stringParam("language", "")}
+	storeQuery    = append(paramSchema{{name: "ttl_seconds", schema: jsonSchema{"type": "integer", "minimum": 1}}, stringParam("tags", "")}, analysisQuery...)
+	recordListing = objectSchema{
+		"data":            []store.StoredString(nil),
+		"count":           0,
+		"total":           0,
+		"next_cursor":     "",
+		"filters_applied": map[string]interface{}(nil),
+		"explain":         (*queryExplain)(nil),
+	}
+	cursorPage = func(elem interface{}) objectSchema {
+		return objectSchema{"data": elem, "count": 0, "next_cursor": int64(0), "has_more": false}
+	}
+	listOf = func(elem interface{}) objectSchema {
+		return objectSchema{"data": elem, "count": 0}
+	}
+)
+
+var apiOperations = []apiOperation{
+	{pattern: "GET /strings", summary: "List stored strings", params: append(listingParams, fieldsParam), filters: true, response: recordListing},
+	{pattern: "POST /strings", summary: "Analyze and store a string", body: CreateReq{}, status: http.StatusCreated, response: store.StoredString{}},
+	{pattern: "GET /strings/filter-by-natural-language", summary: "List the strings matching a natural language query", params: naturalLanguageParams, response: objectSchema{
+		"data":              []store.StoredString(nil),
+		"count":             0,
+		"interpreted_query": objectSchema{"original": "", "parsed_filters": map[string]interface{}(nil)},
+	}},
+	{pattern: "GET /strings/stats/timeseries", summary: "Count stored strings per time bucket", params: timeseriesParams, response: objectSchema{"metric": "", "interval": "", "buckets": []map[string]interface{}(nil)}},
+	{pattern: "GET /strings/stats/words", summary: "Count words across the corpus", params: wordStatsParams, response: objectSchema{"data": []wordFrequency(nil), "count": 0, "total_words": 0, "distinct_words": 0}},
+	{pattern: "GET /strings/typeahead", summary: "Complete a prefix to stored strings", params: typeaheadParams, response: objectSchema{"data": []string(nil), "count": 0, "prefix": ""}},
+	{pattern: "GET /strings/palindrome-pairs", summary: "Find pairs of strings that concatenate to a palindrome", params: palindromePairParams, response: objectSchema{"data": []palindromePair(nil), "count": 0, "total": 0}},
+	{pattern: "GET /strings/fuzzy", summary: "Find strings within an edit distance", params: fuzzyParams, response: objectSchema{"data": []fuzzyMatch(nil), "count": 0, "query": "", "max_distance": 0, "nodes_visited": 0}},
+	{pattern: "GET /strings/export", summary: "Export the matching strings as CSV", params: exportParams, filters: true, produces: "text/csv"},
+	{pattern: "POST /strings/upload", summary: "Analyze and store an uploaded text file", params: storeQuery, body: objectSchema{"file": jsonSchema{"type": "string", "format": "binary"}}, bodyType: "multipart/form-data", status: http.StatusCreated, response: store.StoredString{}},
+	{pattern: "POST /strings/from-url", summary: "Analyze and store the text at a URL", body: fromURLReq{}, status: http.StatusCreated, response: store.StoredString{}},
+	{pattern: "GET /strings/semantic-search", summary: "Rank strings by embedding similarity", params: semanticParams, response: objectSchema{"data": []semanticMatch(nil), "count": 0, "query": ""}},
+	{pattern: "POST /strings/reanalyze", summary: "Reanalyze several strings", params: paramSchema{stringParam("collection", "")}, body: bulkReanalyzeReq{}, response: objectSchema{"data": []store.StoredString(nil), "count": 0, "errors": []chunkError(nil)}},
+	{pattern: "GET /strings/id/{id}", summary: "Get a string by its id or a unique prefix of it", params: append(recordParams, fieldsParam), response: store.StoredString{}},
+	{pattern: "DELETE /strings/id/{id}", summary: "Delete a string by its id", status: http.StatusNoContent},
+	{pattern: "GET /strings/{value}", summary: "Get a stored string", params: append(append(recordParams, fieldsParam), stringParam("collection", "")), response: store.StoredString{}},
+	{pattern: "PUT /strings/{value}", summary: "Store or reanalyze a string", params: storeQuery, response: store.StoredString{}},
+	{pattern: "DELETE /strings/{value}", summary: "Delete a stored string", params: paramSchema{stringParam("collection", "")}, status: http.StatusNoContent},
+	{pattern: "GET /strings/{value}/history", summary: "List the versions of a string", response: objectSchema{"id": "", "value": "", "data": []historyEntry(nil), "count": 0}},
+	{pattern: "PATCH /strings/{value}/tags", summary: "Replace, add or remove the tags of a string", body: tagsReq{}, response: store.StoredString{}},
+	{pattern: "PATCH /strings/{value}/reanalyze", summary: "Reanalyze a stored string", params: analysisQuery, response: store.StoredString{}},
+	{pattern: "POST /strings/{value}/share", summary: "Create a public link to a string", params: paramSchema{intParam("ttl_seconds", 1, int(maxShareTTL/time.Second), int(defaultShareTTL/time.Second))}, status: http.StatusCreated, response: objectSchema{"token": "", "url": "", "expires_at": time.Time{}}},
+	{pattern: "POST /strings/{value}/restore", summary: "Restore a deleted string", response: store.StoredString{}},
+	{pattern: "GET /stats/query", summary: "Test the Grafana data source", response: objectSchema{"status": ""}},
+	{pattern: "POST /stats/query/search", summary: "List the Grafana metrics", body: objectSchema{"target": ""}, response: []string(nil)},
+	{pattern: "POST /stats/query/query", summary: "Query Grafana time series", body: grafanaQuery{}, response: []grafanaSeries(nil)},
+	{pattern: "GET /admin/indexes", summary: "List the secondary indexes", response: listOf([]map[string]interface{}(nil))},
+	{pattern: "GET /admin/indexes/{name}", summary: "Get the status of an index"},
+	{pattern: "POST /admin/indexes/{name}/{action}", summary: "Enable, disable or rebuild an index"},
+	{pattern: "GET /admin/scrub", summary: "Get the last integrity scrub report", response: scrubReport{}},
+	{pattern: "POST /admin/scrub", summary: "Run an integrity scrub", response: scrubReport{}},
+	{pattern: "GET /admin/cache", summary: "Get listing cache statistics"},
+	{pattern: "GET /admin/webhooks", summary: "Get webhook delivery status"},
+	{pattern: "GET /admin/enrichment", summary: "Get enrichment status"},
+	{pattern: "GET /admin/precompute", summary: "List the precomputed queries", response: listOf([]map[string]interface{}(nil))},
+	{pattern: "POST /admin/precompute", summary: "Precompute listing queries", body: precomputeReq{}, response: listOf([]map[string]interface{}(nil))},
+	{pattern: "DELETE /admin/precompute", summary: "Drop the precomputed queries", status: http.StatusNoContent},
+	{pattern: "DELETE /admin/strings/{value}", summary: "Erase a string for good", status: http.StatusNoContent},
+	{pattern: "GET /admin/shadow", summary: "Compare shadow analysis with live analysis"},
+	{pattern: "PUT /admin/shadow", summary: "Set the shadow analyzer options", body: analysis.Options{}},
+	{pattern: "DELETE /admin/shadow", summary: "Stop shadow analysis"},
+	{pattern: "POST /admin/compaction", summary: "Compact the storage logs", response: compactionReport{}},
+	{pattern: "GET /admin/compaction/status", summary: "Get compaction status"},
+	{pattern: "GET /collections", summary: "List collections", response: listOf([]collectionSummary(nil))},
+	{pattern: "POST /collections", summary: "Create a collection", body: createCollectionReq{}, status: http.StatusCreated, response: collectionSummary{}},
+	{pattern: "GET /collections/{name}", summary: "Get a collection", response: collectionSummary{}},
+	{pattern: "DELETE /collections/{name}", summary: "Delete a collection and its strings", response: objectSchema{"collection": "", "strings_deleted": 0}},
+	{pattern: "GET /collections/{name}/config", summary: "Get the analyzer options of a collection", response: objectSchema{"collection": "", "config": analysis.Options{}}},
+	{pattern: "PUT /collections/{name}/config", summary: "Set the analyzer options of a collection", body: analysis.Options{}, response: objectSchema{"collection": "", "config": analysis.Options{}}},
+	{pattern: "GET /collections/{name}/strings", summary: "List the strings of a collection", params: append(listingParams, fieldsParam), filters: true, response: recordListing},
+	{pattern: "POST /collections/{name}/strings", summary: "Analyze and store a string in a collection", body: CreateReq{}, status: http.StatusCreated, response: store.StoredString{}},
+	{pattern: "POST /collections/{name}/strings/reanalyze", summary: "Reanalyze several strings of a collection", body: bulkReanalyzeReq{}, response: objectSchema{"data": []store.StoredString(nil), "count": 0, "errors": []chunkError(nil)}},
+	{pattern: "GET /collections/{name}/strings/{value}", summary: "Get a string of a collection", params: append(recordParams, fieldsParam), response: store.StoredString{}},
+	{pattern: "PUT /collections/{name}/strings/{value}", summary: "Store or reanalyze a string in a collection", params: storeQuery, response: store.StoredString{}},
+	{pattern: "DELETE /collections/{name}/strings/{value}", summary: "Delete a string of a collection", status: http.StatusNoContent},
+	{pattern: "POST /jobs/import", summary: "Start an import job", body: importReq{}, status: http.StatusAccepted, response: importJob{}},
+	{pattern: "POST /jobs/reanalyze", summary: "Start a reanalysis job", body: reanalyzeReq{}, status: http.StatusAccepted, response: importJob{}},
+	{pattern: "GET /jobs/{id}", summary: "Get a job", response: importJob{}},
+	{pattern: "POST /jobs/{id}/cancel", summary: "Cancel a job", response: importJob{}},
+	{pattern: "GET /admin/snapshots", summary: "List corpus snapshots", response: listOf([]corpusSnapshot(nil))},
+	{pattern: "POST /admin/snapshots", summary: "Take a corpus snapshot", status: http.StatusCreated, response: corpusSnapshot{}},
+	{pattern: "GET /admin/snapshots/diff", summary: "Compare two corpus snapshots", params: paramSchema{requiredParam("from"), requiredParam("to")}, response: objectSchema{
+		"from":          "",
+		"to":            "",
+		"added":         []snapshotEntry(nil),
+		"removed":       []snapshotEntry(nil),
+		"added_count":   0,
+		"removed_count": 0,
+	}},
+	{pattern: "GET /shared/{token}", summary: "Get a shared string", response: store.StoredString{}, public: true},
+	{pattern: "GET /usage", summary: "Get the quota usage of the tenant"},
+	{pattern: "GET /admin/keys", summary: "List API keys", params: apiKeyListParams, response: listOf([]apiKey(nil))},
+	{pattern: "POST /admin/keys", summary: "Issue an API key", body: createKeyReq{}, status: http.StatusCreated, response: issuedKey{}},
+	{pattern: "GET /admin/keys/{id}", summary: "Get an API key", response: apiKey{}},
+	{pattern: "DELETE /admin/keys/{id}", summary: "Revoke an API key", response: apiKey{}},
+	{pattern: "POST /admin/keys/{id}/rotate", summary: "Rotate an API key", body: rotateKeyReq{}, response: issuedKey{}},
+	{pattern: "GET /admin/tenants", summary: "List tenants", response: listOf([]tenantSummary(nil))},
+	{pattern: "GET /admin/tenants/{tenant}", summary: "Get a tenant", response: tenantSummary{}},
+	{pattern: "DELETE /admin/tenants/{tenant}", summary: "Purge a tenant's data"},
+	{pattern: "POST /admin/tenants/{tenant}/export", summary: "Export a tenant's strings", response: objectSchema{"tenant": "", "exported_at": time.Time{}, "count": 0, "data": []store.StoredString(nil)}},
+	{pattern: "GET /events", summary: "Page through store events", params: eventParams, response: cursorPage([]store.Event(nil))},
+	{pattern: "GET /audit", summary: "Page through the audit log", params: auditParams, response: cursorPage([]auditEntry(nil))},
+	{pattern: "GET /healthz", summary: "Liveness probe", response: objectSchema{"status": "", "uptime_seconds": int64(0)}, public: true, unversioned: true},
+	{pattern: "GET /readyz", summary: "Readiness probe", response: objectSchema{"status": "", "checks": map[string]healthCheck(nil)}, public: true, unversioned: true},
+	{pattern: "GET /openapi.json", summary: "Get this OpenAPI document", public: true},
+	{pattern: "GET /docs", summary: "Browse this document with Swagger UI", produces: "text/html", public: true},
+}
+
+// OpenAPISpec builds the OpenAPI document from the operation table and the
+// Go types the routes read and write. It fails when a registered route is
+// not documented, so the document cannot fall behind the router.
+func OpenAPISpec() ([]byte, error) {
+	if err := checkDocumentedRoutes(); err != nil {
+		return nil, err
+	}
+	b := &schemaBuilder{components: map[string]interface{}{}, names: map[reflect.Type]string{}}
+	paths := map[string]map[string]interface{}{}
+	for _, op := range apiOperations {
+		method, path, _ := strings.Cut(op.pattern, " ")
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(method)] = b.operation(method, path, op)
+		if op.unversioned {
+			paths[path]["servers"] = []map[string]string{{"url": "/"}}
+		}
+	}
+	errorSchema := b.schema(errorEnvelope{})
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "String Analysis API",
+			"version":     "1.0.0",
+			"description": "Stores strings and the properties computed from them. Every JSON response is also available as XML, YAML or MessagePack through the Accept header or the format parameter, and can be trimmed with the project parameter.",
+		},
+		"servers": []map[string]string{{"url": "/" + currentAPIVersion}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": b.components,
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "The request failed",
+					"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": errorSchema}},
+				},
+			},
+			"securitySchemes": map[string]interface{}{
+				"apiKey": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"bearer": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+		"security": []map[string][]string{{"apiKey": {}}, {"bearer": {}}, {}},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// checkDocumentedRoutes fails for registered patterns, and /strings/{value}
+// actions, that no operation documents. A pattern without a method is
+// covered by an operation of any method on a path it matches.
+func checkDocumentedRoutes() error {
+	documented := map[string]bool{}
+	for _, op := range apiOperations {
+		documented[op.pattern] = true
+	}
+	var patterns, missing []string
+	registerRoutes(func(pattern string, _ func(http.ResponseWriter, *http.Request)) {
+		patterns = append(patterns, pattern)
+	})
+	for action, methods := range stringActions {
+		for method := range methods {
+			patterns = append(patterns, method+" /strings/{value}/"+action)
+		}
+	}
+	for _, pattern := range patterns {
+		method, path, ok := strings.Cut(pattern, " ")
+		if !ok {
+			method, path = "", pattern
+		}
+		path = strings.TrimSuffix(strings.TrimSuffix(path, "{$}"), "/")
+		if method != "" {
+			if !documented[method+" "+path] {
+				missing = append(missing, pattern)
+			}
+			continue
+		}
+		if !slices.ContainsFunc(apiOperations, func(op apiOperation) bool {
+			_, p, _ := strings.Cut(op.pattern, " ")
+			return pathMatches(path, p)
+		}) {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("routes missing from the OpenAPI operations: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// pathMatches reports whether the documented path doc is served by the
+// route pattern, whose {name} segments match any segment and whose final
+// {name...} matches the rest of the path.
+func pathMatches(pattern, doc string) bool {
+	ps, ds := strings.Split(pattern, "/"), strings.Split(doc, "/")
+	for i, seg := range ps {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}") {
+			return len(ds) > i
+		}
+		if i >= len(ds) {
+			return false
+		}
+		if !strings.HasPrefix(seg, "{") && seg != ds[i] {
+			return false
+		}
+	}
+	return len(ps) == len(ds)
+}
+
+func (b *schemaBuilder) operation(method, path string, op apiOperation) map[string]interface{} {
+	params := []interface{}{}
+	for _, seg := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(seg, "{"); ok {
+			params = append(params, map[string]interface{}{"name": strings.TrimSuffix(name, "}"), "in": "path", "required": true, "schema": jsonSchema{"type": "string"}})
+		}
+	}
+	specs := op.params
+	if op.filters {
+		specs = append(append(paramSchema(nil), specs...), filterParams()...)
+	}
+	for _, spec := range specs {
+		schema := jsonSchema{}
+		for k, v := range spec.schema {
+			schema[k] = v
+		}
+		if spec.def != nil && spec.def != "" {
+			schema["default"] = spec.def
+		}
+		p := map[string]interface{}{"name": spec.name, "in": "query", "schema": schema}
+		if spec.required {
+			p["required"] = true
+		}
+		params = append(params, p)
+	}
+	out := map[string]interface{}{
+		"summary":     op.summary,
+		"operationId": operationID(method, path),
+		"tags":        []string{strings.Split(path, "/")[1]},
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+	if op.body != nil {
+		bodyType := op.bodyType
+		if bodyType == "" {
+			bodyType = "application/json"
+		}
+		out["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{bodyType: map[string]interface{}{"schema": b.schema(op.body)}},
+		}
+	}
+	status := op.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	switch {
+	case status == http.StatusNoContent:
+	case op.produces != "":
+		success["content"] = map[string]interface{}{op.produces: map[string]interface{}{"schema": jsonSchema{"type": "string"}}}
+	default:
+		success["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": b.schema(op.response)}}
+	}
+	out["responses"] = map[string]interface{}{
+		fmt.Sprint(status): success,
+		"default":          jsonSchema{"$ref": "#/components/responses/Error"},
+	}
+	if op.public {
+		out["security"] = []interface{}{}
+	}
+	return out
+}
+
+// filterParams documents the listing filters, typed by what their parsers
+// accept, and the or parameter that combines them.
+func filterParams() paramSchema {
+	specs := make(paramSchema, 0, len(listFilters)+1)
+	for _, f := range listFilters {
+		schema := jsonSchema{"type": "string"}
+		if v, err := f.parse("7"); err == nil {
+			switch v.(type) {
+			case int:
+				schema = jsonSchema{"type": "integer", "minimum": 0}
+			case float64:
+				schema = jsonSchema{"type": "number"}
+			}
+		} else if v, err := f.parse("true"); err == nil {
+			if _, ok := v.(bool); ok {
+				schema = jsonSchema{"type": "boolean"}
+			}
+		}
+		specs = append(specs, paramSpec{name: f.name, schema: schema})
+	}
+	return append(specs, paramSpec{name: "or", schema: jsonSchema{"type": "array", "items": jsonSchema{"type": "string"}}})
+}
+
+// operationID names an operation after its method and path, such as
+// getStringsByValueHistory for GET /strings/{value}/history.
+func operationID(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for _, seg := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(seg, "{"); ok {
+			sb.WriteString("By")
+			seg = strings.TrimSuffix(name, "}")
+		}
+		for _, word := range strings.FieldsFunc(seg, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+			sb.WriteString(exportedName(word))
+		}
+	}
+	return sb.String()
+}
+
+func exportedName(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+var (
+	timestampType  = reflect.TypeOf(store.Timestamp{})
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// schemaBuilder derives JSON Schemas from Go types the way encoding/json
+// encodes them, collecting named structs as components.
+type schemaBuilder struct {
+	components map[string]interface{}
+	names      map[reflect.Type]string
+}
+
+func (b *schemaBuilder) schema(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return jsonSchema{"type": "object"}
+	case jsonSchema:
+		return v
+	case objectSchema:
+		props := map[string]interface{}{}
+		for name, p := range v {
+			props[name] = b.schema(p)
+		}
+		return jsonSchema{"type": "object", "properties": props}
+	}
+	return b.typeSchema(reflect.TypeOf(v))
+}
+
+func (b *schemaBuilder) typeSchema(t reflect.Type) interface{} {
+	switch t {
+	case timestampType, timeType:
+		return jsonSchema{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return jsonSchema{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.typeSchema(t.Elem())
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int64, reflect.Uint64:
+		return jsonSchema{"type": "integer", "format": "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return jsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return jsonSchema{"type": "string", "format": "byte"}
+		}
+		return jsonSchema{"type": "array", "items": b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return jsonSchema{"type": "object", "additionalProperties": b.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return jsonSchema{"$ref": "#/components/schemas/" + b.component(t)}
+	}
+	return jsonSchema{}
+}
+
+// component registers the named struct t and returns its component name:
+// its exported type name, prefixed with its package name when another
+// package has a type of that name.
+func (b *schemaBuilder) component(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := exportedName(t.Name())
+	for other, n := range b.names {
+		if n == name && other != t {
+			pkg := t.PkgPath()
+			name = exportedName(pkg[strings.LastIndex(pkg, "/")+1:]) + name
+			break
+		}
+	}
+	b.names[t] = name
+	b.components[name] = b.structSchema(t)
+	return name
+}
+
+// structSchema describes the fields encoding/json writes for t: exported
+// fields under their json names, with the fields of embedded structs
+// inlined. Pointer fields without omitempty may be null.
+func (b *schemaBuilder) structSchema(t reflect.Type) jsonSchema {
+	props := map[string]interface{}{}
+	b.addFields(t, props)
+	return jsonSchema{"type": "object", "properties": props}
+}
+
+func (b *schemaBuilder) addFields(t reflect.Type, props map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.addFields(ft, props)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s := b.typeSchema(ft)
+		if ft.Kind() == reflect.Pointer && !strings.Contains(opts, "omitempty") {
+			s = jsonSchema{"allOf": []interface{}{s}, "nullable": true}
+		}
+		props[name] = s
+	}
+}
+
+// docsPaths are served without credentials.
+var docsPaths = map[string]bool{"/openapi.json": true, "/docs": true}
+
+// openAPIHandler serves the generated OpenAPI document.
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPIDocument)
+}
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>String Analysis API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`
+
+// docsHandler serves Swagger UI for the OpenAPI document. The UI itself is
+// loaded from unpkg.
+func docsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(docsPage))
+}
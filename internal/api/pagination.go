@@ -0,0 +1,110 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// pageCursor is the opaque cursor handed to clients: the sort position of
+// the last record on a page, and a fingerprint of the query it belongs to.
+// Paging resumes after that position, so records created or deleted between
+// requests never shift or repeat later pages.
+type pageCursor struct {
+	ID               string     `json:"id"`
+	CreatedAt        time.Time  `json:"c"`
+	UpdatedAt        *time.Time `json:"u,omitempty"`
+	Length           int        `json:"l,omitempty"`
+	WordCount        int        `json:"w,omitempty"`
+	UniqueCharacters int        `json:"uc,omitempty"`
+	Value            string     `json:"v,omitempty"`
+	Query            string     `json:"q"`
+}
+
+type pageRequest struct {
+	limit int
+	after *store.StoredString
+}
+
+func queryFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// encodePageCursor records the fields item is ordered by. The value is
+// only included when sorting by it, as it can be arbitrarily long.
+func encodePageCursor(item store.StoredString, spec *sortSpec, key string) string {
+	c := pageCursor{
+		ID:               item.ID,
+		CreatedAt:        item.CreatedAt.Time,
+		Length:           item.Properties.Length,
+		WordCount:        item.Properties.WordCount,
+		UniqueCharacters: item.Properties.UniqueCharacters,
+		Query:            queryFingerprint(key),
+	}
+	if item.UpdatedAt != nil {
+		c.UpdatedAt = &item.UpdatedAt.Time
+	}
+	if spec.key == "value" {
+		c.Value = item.Value
+	}
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+var pageParams = paramSchema{
+	intParam("limit", 1, maxPageLimit, defaultPageLimit),
+	stringParam("cursor", ""),
+}
+
+// parsePageRequest reads the cursor and limit of pageParams. It returns nil
+// when neither is given, in which case the whole listing is returned.
+func parsePageRequest(p queryParams, key string) (*pageRequest, error) {
+	if !p.has("cursor") && !p.has("limit") {
+		return nil, nil
+	}
+	page := &pageRequest{limit: p.int("limit")}
+	if cursor := p.str("cursor"); cursor != "" {
+		b, err := base64.RawURLEncoding.DecodeString(cursor)
+		var c pageCursor
+		if err != nil || json.Unmarshal(b, &c) != nil || c.ID == "" {
+			return nil, errors.New("invalid cursor")
+		}
+		if c.Query != queryFingerprint(key) {
+			return nil, errors.New("cursor does not belong to this query; keep the same filters and sort")
+		}
+		after := store.StoredString{ID: c.ID, Value: c.Value, CreatedAt: store.NewTimestamp(c.CreatedAt)}
+		after.Properties.Length, after.Properties.WordCount, after.Properties.UniqueCharacters = c.Length, c.WordCount, c.UniqueCharacters
+		if c.UpdatedAt != nil {
+			ts := store.NewTimestamp(*c.UpdatedAt)
+			after.UpdatedAt = &ts
+		}
+		page.after = &after
+	}
+	return page, nil
+}
+
+// paginate returns the page of sorted that follows the cursor position, and
+// the cursor for the next page, or "" on the last page.
+func paginate(sorted []store.StoredString, spec *sortSpec, page *pageRequest, key string) ([]store.StoredString, string) {
+	start := 0
+	if page.after != nil {
+		start = sort.Search(len(sorted), func(i int) bool { return spec.less(*page.after, sorted[i]) })
+	}
+	end := min(start+page.limit, len(sorted))
+	if end >= len(sorted) {
+		return sorted[start:end], ""
+	}
+	return sorted[start:end], encodePageCursor(sorted[end-1], spec, key)
+}
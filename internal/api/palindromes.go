@@ -0,0 +1,137 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"unicode"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	defaultPairLimit = 100
+	maxPairLimit     = 1000
+)
+
+type palindromePair struct {
+	First      string `json:"first"`
+	Second     string `json:"second"`
+	Palindrome string `json:"palindrome"`
+}
+
+func foldedRunes(s string) []rune {
+	rs := []rune(s)
+	for i, r := range rs {
+		rs[i] = unicode.ToLower(r)
+	}
+	return rs
+}
+
+func runesPalindrome(rs []rune) bool {
+	for i, j := 0, len(rs)-1; i < j; i, j = i+1, j-1 {
+		if rs[i] != rs[j] {
+			return false
+		}
+	}
+	return true
+}
+
+func reversedKey(rs []rune) string {
+	out := make([]rune, len(rs))
+	for i, r := range rs {
+		out[len(rs)-1-i] = r
+	}
+	return string(out)
+}
+
+// findPalindromePairs returns every ordered pair of distinct values whose
+// concatenation is a case-insensitive palindrome. Rather than testing all
+// n² concatenations, each value is split at every position: when one side
+// is a palindrome, a partner exists exactly when the reverse of the other
+// side is a stored value, which is a map lookup. The cost is O(n·k²) for
+// values of length k.
+func findPalindromePairs(values []string) []palindromePair {
+	byKey := map[string][]string{}
+	folded := make([][]rune, len(values))
+	for i, v := range values {
+		folded[i] = foldedRunes(v)
+		key := string(folded[i])
+		byKey[key] = append(byKey[key], v)
+	}
+	seen := map[[2]string]bool{}
+	pairs := []palindromePair{}
+	add := func(first, second string) {
+		k := [2]string{first, second}
+		if first == second || seen[k] {
+			return
+		}
+		seen[k] = true
+		pairs = append(pairs, palindromePair{First: first, Second: second, Palindrome: first + second})
+	}
+	for i, v := range values {
+		w := folded[i]
+		for k := 0; k <= len(w); k++ {
+			if runesPalindrome(w[:k]) {
+				for _, u := range byKey[reversedKey(w[k:])] {
+					add(u, v)
+				}
+			}
+			if runesPalindrome(w[k:]) {
+				for _, u := range byKey[reversedKey(w[:k])] {
+					add(v, u)
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].First != pairs[j].First {
+			return pairs[i].First < pairs[j].First
+		}
+		return pairs[i].Second < pairs[j].Second
+	})
+	return pairs
+}
+
+var palindromePairParams = paramSchema{
+	intParam("limit", 1, maxPairLimit, defaultPairLimit),
+	stringParam("value", ""),
+}
+
+func palindromePairsHandler(w http.ResponseWriter, r *http.Request) {
+	var errs paramErrors
+	p := palindromePairParams.parse(r.URL.Query(), &errs)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	limit, value := p.int("limit"), p.str("value")
+	tenant := tenantOf(r)
+	items, err := storeFor(r.Context()).Filter(func(item store.StoredString) bool { return storedTenant(item) == tenant })
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = item.Value
+	}
+	pairs := findPalindromePairs(values)
+	if value != "" {
+		matching := []palindromePair{}
+		for _, p := range pairs {
+			if p.First == value || p.Second == value {
+				matching = append(matching, p)
+			}
+		}
+		pairs = matching
+	}
+	total := len(pairs)
+	if len(pairs) > limit {
+		pairs = pairs[:limit]
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  pairs,
+		"count": len(pairs),
+		"total": total,
+	})
+}
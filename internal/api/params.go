@@ -0,0 +1,200 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// paramSpec declares one query parameter: how its value is parsed and
+// checked, the value used when it is absent, and the JSON Schema the OpenAPI
+// document gives it.
+type paramSpec struct {
+	name     string
+	required bool
+	def      interface{}
+	parse    func(string) (interface{}, error)
+	schema   jsonSchema
+}
+
+// paramSchema is the set of query parameters an endpoint accepts.
+type paramSchema []paramSpec
+
+type paramError struct {
+	Parameter string `json:"parameter"`
+	Message   string `json:"message"`
+}
+
+// paramErrors collects every invalid query parameter of a request, so
+// clients can fix them all at once.
+type paramErrors []paramError
+
+func (e paramErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, pe := range e {
+		msgs[i] = pe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// add records err against param. Errors that are already paramErrors keep
+// their own parameter names.
+func (e *paramErrors) add(param string, err error) {
+	if err == nil {
+		return
+	}
+	var pe paramErrors
+	if errors.As(err, &pe) {
+		*e = append(*e, pe...)
+		return
+	}
+	*e = append(*e, paramError{Parameter: param, Message: err.Error()})
+}
+
+func (e paramErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// writeParamErrors answers INVALID_FILTER when only listing filters are
+// invalid and INVALID_PARAMETER otherwise, listing every error in details.
+func writeParamErrors(w http.ResponseWriter, errs paramErrors) {
+	code := "INVALID_FILTER"
+	for _, pe := range errs {
+		if pe.Parameter != "or" && lookupFilter(pe.Parameter) == nil {
+			code = "INVALID_PARAMETER"
+		}
+	}
+	writeErrorDetails(w, http.StatusBadRequest, code, errs.Error(), errs)
+}
+
+// intParam accepts integers in [lo, hi]; math.MaxInt leaves it unbounded.
+func intParam(name string, lo, hi, def int) paramSpec {
+	msg := fmt.Sprintf("%s must be between %d and %d", name, lo, hi)
+	schema := jsonSchema{"type": "integer", "minimum": lo, "maximum": hi}
+	if hi == math.MaxInt {
+		msg = fmt.Sprintf("%s must be an integer of at least %d", name, lo)
+		delete(schema, "maximum")
+	}
+	return paramSpec{name: name, def: def, schema: schema, parse: func(v string) (interface{}, error) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < lo || n > hi {
+			return nil, errors.New(msg)
+		}
+		return n, nil
+	}}
+}
+
+func boolParam(name string, def bool) paramSpec {
+	return paramSpec{name: name, def: def, schema: jsonSchema{"type": "boolean"}, parse: func(v string) (interface{}, error) {
+		b, err := parseBoolParam(strings.ToLower(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value, expected true or false", name)
+		}
+		return b, nil
+	}}
+}
+
+func enumParam(name, def string, choices ...string) paramSpec {
+	return paramSpec{name: name, def: def, schema: jsonSchema{"type": "string", "enum": choices}, parse: func(v string) (interface{}, error) {
+		if !slices.Contains(choices, v) {
+			return nil, fmt.Errorf("invalid %s, expected one of %s", name, strings.Join(choices, ", "))
+		}
+		return v, nil
+	}}
+}
+
+func timeParam(name string) paramSpec {
+	return paramSpec{name: name, schema: jsonSchema{"type": "string", "format": "date-time"}, parse: func(v string) (interface{}, error) {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s, expected RFC 3339 timestamp", name)
+		}
+		return t, nil
+	}}
+}
+
+func stringParam(name, def string) paramSpec {
+	return paramSpec{name: name, def: def, schema: jsonSchema{"type": "string"}, parse: func(v string) (interface{}, error) {
+		return v, nil
+	}}
+}
+
+func requiredParam(name string) paramSpec {
+	spec := stringParam(name, "")
+	spec.required = true
+	return spec
+}
+
+// queryParams holds the parsed values of a schema's parameters.
+type queryParams struct {
+	q      url.Values
+	values map[string]interface{}
+}
+
+// parse reads every parameter of s from q, adding each failure to errs
+// rather than stopping at the first.
+func (s paramSchema) parse(q url.Values, errs *paramErrors) queryParams {
+	p := queryParams{q: q, values: map[string]interface{}{}}
+	for _, spec := range s {
+		v := q.Get(spec.name)
+		if v == "" {
+			if spec.required {
+				errs.add(spec.name, fmt.Errorf("%s parameter is required", spec.name))
+			}
+			if spec.def != nil {
+				p.values[spec.name] = spec.def
+			}
+			continue
+		}
+		val, err := spec.parse(v)
+		if err != nil {
+			errs.add(spec.name, err)
+			continue
+		}
+		p.values[spec.name] = val
+	}
+	return p
+}
+
+// has reports whether the request set name.
+func (p queryParams) has(name string) bool {
+	return p.q.Get(name) != ""
+}
+
+func (p queryParams) int(name string) int {
+	n, _ := p.values[name].(int)
+	return n
+}
+
+func (p queryParams) bool(name string) bool {
+	b, _ := p.values[name].(bool)
+	return b
+}
+
+func (p queryParams) str(name string) string {
+	s, _ := p.values[name].(string)
+	return s
+}
+
+func (p queryParams) strings(name string) []string {
+	s, _ := p.values[name].([]string)
+	return s
+}
+
+// time returns the parsed timestamp name, or nil when it was not given.
+func (p queryParams) time(name string) *time.Time {
+	t, ok := p.values[name].(time.Time)
+	if !ok {
+		return nil
+	}
+	return &t
+}
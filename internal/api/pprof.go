@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// newPprofMux serves the profiling routes. Importing net/http/pprof also
+// registers them on the default mux at /debug/pprof/. The API serves its own
+// router, and withPprof answers those paths with the usual JSON 404.
+func newPprofMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// startPprofServer serves the profiles on their own listener at addr,
+// meant to be bound to localhost or a private network, without
+// authentication.
+func startPprofServer(addr string) {
+	if addr == "" {
+		return
+	}
+	go func() {
+		logger.Info("pprof listening", "addr", addr)
+		if err := newServer(addr, newPprofMux()).ListenAndServe(); err != nil {
+			logger.Error("pprof server error", "error", err)
+		}
+	}()
+}
+
+// withPprof serves the profiles under /admin/debug/pprof/ once
+// PPROF_ENABLED is set, where they need an admin key like every /admin
+// route.
+func withPprof(next http.Handler) http.Handler {
+	mux := newPprofMux()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/debug/pprof/"):
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+		case strings.HasPrefix(r.URL.Path, "/admin/debug/pprof/"):
+			if !settings().Pprof {
+				writeError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+				return
+			}
+			r = r.Clone(r.Context())
+			r.URL.Path = strings.TrimPrefix(r.URL.Path, "/admin")
+			r.URL.RawPath = ""
+			mux.ServeHTTP(w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
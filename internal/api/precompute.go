@@ -0,0 +1,287 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	maxPrecomputed          = 32
+	defaultPrecomputeMaxAge = time.Second
+)
+
+// precomputableRoutes are the read endpoints whose responses can be kept
+// materialized: listings and aggregates over the whole store.
+var precomputableRoutes = map[string]bool{
+	"/strings":                            true,
+	"/strings/filter-by-natural-language": true,
+	"/strings/stats/timeseries":           true,
+	"/strings/stats/words":                true,
+	"/strings/palindrome-pairs":           true,
+}
+
+// materialized is a precomputed response of one tenant's query. dirtyAt is when the first write
+// after it was computed happened; it is served until dirtyAt is older than
+// maxStaleness, by which time a refresh has normally replaced it.
+type materialized struct {
+	tenant       string
+	query        string
+	maxStaleness time.Duration
+	header       http.Header
+	code         int
+	body         []byte
+	version      uint64
+	refreshedAt  time.Time
+	dirtyAt      time.Time
+	refreshes    int
+	hits         int
+}
+
+type precomputedQuery struct {
+	tenant, query string
+}
+
+var precomputed = struct {
+	sync.Mutex
+	entries map[precomputedQuery]*materialized
+	wake    chan struct{}
+	once    sync.Once
+}{entries: map[precomputedQuery]*materialized{}, wake: make(chan struct{}, 1)}
+
+// precomputeKey canonicalizes a GET request. format and project are left
+// out since they are applied to the response by outer middleware.
+func precomputeKey(path string, q url.Values) string {
+	q = maps.Clone(q)
+	q.Del("format")
+	q.Del("project")
+	if len(q) == 0 {
+		return path
+	}
+	return path + "?" + q.Encode()
+}
+
+// materialize runs the tenant's query against the routes and returns its
+// response.
+func materialize(tenant, query string) (*bufferedResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(tenantHeader, tenant)
+	buf := &bufferedResponse{header: http.Header{}}
+	router.ServeHTTP(buf, req)
+	if buf.code == 0 {
+		buf.code = http.StatusOK
+	}
+	return buf, nil
+}
+
+// precomputeDirty marks every materialized response stale after a write
+// and wakes the refresher. indexedStorage calls it through the index hooks.
+func precomputeDirty() {
+	precomputed.Lock()
+	defer precomputed.Unlock()
+	if len(precomputed.entries) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, m := range precomputed.entries {
+		if m.dirtyAt.IsZero() {
+			m.dirtyAt = now
+		}
+	}
+	select {
+	case precomputed.wake <- struct{}{}:
+	default:
+	}
+}
+
+// runPrecompute refreshes stale materialized responses after writes,
+// coalescing the writes that land while a refresh runs.
+func runPrecompute(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-precomputed.wake:
+		}
+		for ctx.Err() == nil {
+			precomputed.Lock()
+			var stale []*materialized
+			current := storeVersion.Load()
+			for _, m := range precomputed.entries {
+				if m.version != current {
+					stale = append(stale, m)
+				}
+			}
+			precomputed.Unlock()
+			if len(stale) == 0 {
+				break
+			}
+			for _, m := range stale {
+				refreshMaterialized(m)
+			}
+		}
+	}
+}
+
+func refreshMaterialized(m *materialized) {
+	version := storeVersion.Load()
+	buf, err := materialize(m.tenant, m.query)
+	if err != nil {
+		return
+	}
+	precomputed.Lock()
+	defer precomputed.Unlock()
+	m.header, m.code, m.body = buf.header, buf.code, buf.body.Bytes()
+	m.version, m.refreshedAt = version, time.Now()
+	m.refreshes++
+	if storeVersion.Load() == version {
+		m.dirtyAt = time.Time{}
+	}
+}
+
+// withPrecomputed serves GET requests matching a precomputed query from its
+// materialized response while it is within its staleness bound.
+func withPrecomputed(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		precomputed.Lock()
+		m, ok := precomputed.entries[precomputedQuery{tenantOf(r), precomputeKey(r.URL.Path, r.URL.Query())}]
+		if !ok || m.body == nil || (!m.dirtyAt.IsZero() && time.Since(m.dirtyAt) >= m.maxStaleness) {
+			precomputed.Unlock()
+			next.ServeHTTP(w, r)
+			return
+		}
+		m.hits++
+		buf := &bufferedResponse{header: m.header.Clone(), code: m.code}
+		buf.body.Write(m.body)
+		refreshedAt := m.refreshedAt
+		precomputed.Unlock()
+		buf.header.Set("X-Cache", "PRECOMPUTED")
+		buf.header.Set("X-Precomputed-At", store.FormatTime(refreshedAt))
+		buf.flushTo(w)
+	})
+}
+
+type precomputeReq struct {
+	Queries        []string `json:"queries"`
+	MaxStalenessMs *int     `json:"max_staleness_ms"`
+}
+
+// precomputeStatus lists the tenant's precomputed queries.
+func precomputeStatus(tenant string) []map[string]interface{} {
+	precomputed.Lock()
+	defer precomputed.Unlock()
+	out := make([]map[string]interface{}, 0, len(precomputed.entries))
+	for _, m := range precomputed.entries {
+		if m.tenant != tenant {
+			continue
+		}
+		out = append(out, map[string]interface{}{
+			"query":            m.query,
+			"max_staleness_ms": m.maxStaleness.Milliseconds(),
+			"refreshed_at":     store.FormatTime(m.refreshedAt),
+			"refreshes":        m.refreshes,
+			"hits":             m.hits,
+			"stale":            !m.dirtyAt.IsZero(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i]["query"].(string) < out[j]["query"].(string) })
+	return out
+}
+
+func precomputeStatusHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": precomputeStatus(tenantOf(r))})
+}
+
+// precomputeHandler registers queries of the tenant to keep materialized.
+// The limit applies across tenants.
+func precomputeHandler(w http.ResponseWriter, r *http.Request) {
+	tenant := tenantOf(r)
+	var body precomputeReq
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if len(body.Queries) == 0 {
+		writeError(w, http.StatusBadRequest, "MISSING_QUERIES", `"queries" must be a non-empty array`)
+		return
+	}
+	maxStaleness := defaultPrecomputeMaxAge
+	if body.MaxStalenessMs != nil {
+		if *body.MaxStalenessMs < 1 {
+			writeError(w, http.StatusBadRequest, "INVALID_MAX_STALENESS", "max_staleness_ms must be at least 1")
+			return
+		}
+		maxStaleness = time.Duration(*body.MaxStalenessMs) * time.Millisecond
+	}
+	added := make([]*materialized, 0, len(body.Queries))
+	for _, q := range body.Queries {
+		u, err := url.Parse(q)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_QUERY", fmt.Sprintf("invalid query %q", q))
+			return
+		}
+		req := &http.Request{Method: http.MethodGet, URL: u, Host: r.Host}
+		if route := routeOf(req); !precomputableRoutes[route] || u.Path != route {
+			writeError(w, http.StatusBadRequest, "INVALID_QUERY", fmt.Sprintf("%q is not a precomputable listing or stats query", q))
+			return
+		}
+		key := precomputeKey(u.Path, u.Query())
+		version := storeVersion.Load()
+		buf, err := materialize(tenant, key)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_QUERY", fmt.Sprintf("invalid query %q", q))
+			return
+		}
+		if buf.code != http.StatusOK {
+			writeErrorDetails(w, http.StatusBadRequest, "QUERY_FAILED", fmt.Sprintf("query %q failed", q), map[string]interface{}{"response": json.RawMessage(buf.body.Bytes())})
+			return
+		}
+		added = append(added, &materialized{tenant: tenant, query: key, maxStaleness: maxStaleness, header: buf.header, code: buf.code, body: buf.body.Bytes(), version: version, refreshedAt: time.Now()})
+	}
+	precomputed.Lock()
+	n := len(precomputed.entries)
+	for _, m := range added {
+		if _, ok := precomputed.entries[precomputedQuery{tenant, m.query}]; !ok {
+			n++
+		}
+	}
+	if n > maxPrecomputed {
+		precomputed.Unlock()
+		writeError(w, http.StatusBadRequest, "TOO_MANY_QUERIES", fmt.Sprintf("at most %d queries can be precomputed", maxPrecomputed))
+		return
+	}
+	for _, m := range added {
+		precomputed.entries[precomputedQuery{tenant, m.query}] = m
+	}
+	precomputed.once.Do(func() { goBackground(runPrecompute) })
+	precomputed.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": precomputeStatus(tenant)})
+}
+
+// clearPrecomputedHandler drops every materialized query of the tenant.
+func clearPrecomputedHandler(w http.ResponseWriter, r *http.Request) {
+	tenant := tenantOf(r)
+	precomputed.Lock()
+	for key := range precomputed.entries {
+		if key.tenant == tenant {
+			delete(precomputed.entries, key)
+		}
+	}
+	precomputed.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,600 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The projection language is a small subset of jq: paths (.a.b, .["k"], .[0]),
+// iteration (.[]), pipes, commas, array collection ([...]) and object
+// construction ({a, b: .x}). Literals are limited to strings and numbers.
+
+type projNode interface {
+	eval(v interface{}) ([]interface{}, error)
+}
+
+type projIdentity struct{}
+
+type projField struct {
+	src  projNode
+	name string
+}
+
+type projIndex struct {
+	src projNode
+	idx int
+}
+
+type projIterate struct {
+	src projNode
+}
+
+type projPipe struct {
+	left, right projNode
+}
+
+type projComma struct {
+	left, right projNode
+}
+
+type projCollect struct {
+	inner projNode
+}
+
+type projObjectEntry struct {
+	key   string
+	value projNode
+}
+
+type projObject struct {
+	entries []projObjectEntry
+}
+
+type projLiteral struct {
+	value interface{}
+}
+
+func (projIdentity) eval(v interface{}) ([]interface{}, error) {
+	return []interface{}{v}, nil
+}
+
+func (n projField) eval(v interface{}) ([]interface{}, error) {
+	ins, err := n.src.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, 0, len(ins))
+	for _, in := range ins {
+		switch t := in.(type) {
+		case nil:
+			out = append(out, nil)
+		case map[string]interface{}:
+			out = append(out, t[n.name])
+		default:
+			return nil, fmt.Errorf("cannot index %s with %q", projTypeName(in), n.name)
+		}
+	}
+	return out, nil
+}
+
+func (n projIndex) eval(v interface{}) ([]interface{}, error) {
+	ins, err := n.src.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, 0, len(ins))
+	for _, in := range ins {
+		switch t := in.(type) {
+		case nil:
+			out = append(out, nil)
+		case []interface{}:
+			i := n.idx
+			if i < 0 {
+				i += len(t)
+			}
+			if i < 0 || i >= len(t) {
+				out = append(out, nil)
+			} else {
+				out = append(out, t[i])
+			}
+		default:
+			return nil, fmt.Errorf("cannot index %s with number", projTypeName(in))
+		}
+	}
+	return out, nil
+}
+
+func (n projIterate) eval(v interface{}) ([]interface{}, error) {
+	ins, err := n.src.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, in := range ins {
+		switch t := in.(type) {
+		case []interface{}:
+			out = append(out, t...)
+		case map[string]interface{}:
+			for _, k := range sortedKeys(t) {
+				out = append(out, t[k])
+			}
+		default:
+			return nil, fmt.Errorf("cannot iterate over %s", projTypeName(in))
+		}
+	}
+	return out, nil
+}
+
+func (n projPipe) eval(v interface{}) ([]interface{}, error) {
+	ins, err := n.left.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, in := range ins {
+		res, err := n.right.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res...)
+	}
+	return out, nil
+}
+
+func (n projComma) eval(v interface{}) ([]interface{}, error) {
+	l, err := n.left.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(l, r...), nil
+}
+
+func (n projCollect) eval(v interface{}) ([]interface{}, error) {
+	if n.inner == nil {
+		return []interface{}{[]interface{}{}}, nil
+	}
+	res, err := n.inner.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{res}, nil
+}
+
+func (n projObject) eval(v interface{}) ([]interface{}, error) {
+	objs := []map[string]interface{}{{}}
+	for _, e := range n.entries {
+		vals, err := e.value.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		next := make([]map[string]interface{}, 0, len(objs)*len(vals))
+		for _, o := range objs {
+			for _, val := range vals {
+				c := make(map[string]interface{}, len(o)+1)
+				for k, x := range o {
+					c[k] = x
+				}
+				c[e.key] = val
+				next = append(next, c)
+			}
+		}
+		objs = next
+	}
+	out := make([]interface{}, len(objs))
+	for i, o := range objs {
+		out[i] = o
+	}
+	return out, nil
+}
+
+func (n projLiteral) eval(v interface{}) ([]interface{}, error) {
+	return []interface{}{n.value}, nil
+}
+
+func projTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "value"
+}
+
+type projToken struct {
+	kind string
+	text string
+}
+
+func lexProjection(s string) ([]projToken, error) {
+	toks := []projToken{}
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune(".[]{}(),:|", c):
+			toks = append(toks, projToken{kind: string(c)})
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(rs) && rs[j] != '"' {
+				if rs[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(rs) {
+				return nil, errors.New("unterminated string")
+			}
+			str, err := strconv.Unquote(string(rs[i : j+1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", string(rs[i:j+1]))
+			}
+			toks = append(toks, projToken{kind: "str", text: str})
+			i = j + 1
+		case c == '-' || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			toks = append(toks, projToken{kind: "num", text: string(rs[i:j])})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i + 1
+			for j < len(rs) && (rs[j] == '_' || unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+			toks = append(toks, projToken{kind: "ident", text: string(rs[i:j])})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return toks, nil
+}
+
+type projParser struct {
+	toks []projToken
+	pos  int
+}
+
+func parseProjection(s string) (projNode, error) {
+	toks, err := lexProjection(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return nil, errors.New("empty expression")
+	}
+	p := &projParser{toks: toks}
+	n, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected token %q", p.peek().display())
+	}
+	return n, nil
+}
+
+func (t projToken) display() string {
+	if t.text != "" {
+		return t.text
+	}
+	return t.kind
+}
+
+func (p *projParser) peek() projToken {
+	if p.pos >= len(p.toks) {
+		return projToken{kind: "eof"}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *projParser) accept(kind string) bool {
+	if p.peek().kind == kind {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *projParser) expect(kind string) (projToken, error) {
+	t := p.peek()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %q, got %q", kind, t.display())
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *projParser) parsePipe() (projNode, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("|") {
+		right, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		left = projPipe{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *projParser) parseComma() (projNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(",") {
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = projComma{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *projParser) parseTerm() (projNode, error) {
+	var n projNode
+	t := p.peek()
+	switch t.kind {
+	case ".":
+		p.pos++
+		n = projIdentity{}
+		switch p.peek().kind {
+		case "ident", "str":
+			n = projField{src: n, name: p.peek().text}
+			p.pos++
+		case "[":
+			var err error
+			if n, err = p.parseBracket(n); err != nil {
+				return nil, err
+			}
+		}
+	case "[":
+		p.pos++
+		if p.accept("]") {
+			n = projCollect{}
+			break
+		}
+		inner, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		n = projCollect{inner: inner}
+	case "{":
+		p.pos++
+		obj, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		n = obj
+	case "(":
+		p.pos++
+		inner, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		n = inner
+	case "str":
+		p.pos++
+		n = projLiteral{value: t.text}
+	case "num":
+		p.pos++
+		n = projLiteral{value: json.Number(t.text)}
+	default:
+		return nil, fmt.Errorf("unexpected token %q", t.display())
+	}
+	for {
+		switch p.peek().kind {
+		case ".":
+			p.pos++
+			switch p.peek().kind {
+			case "ident", "str":
+				n = projField{src: n, name: p.peek().text}
+				p.pos++
+			case "[":
+				var err error
+				if n, err = p.parseBracket(n); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("unexpected token %q after '.'", p.peek().display())
+			}
+		case "[":
+			var err error
+			if n, err = p.parseBracket(n); err != nil {
+				return nil, err
+			}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *projParser) parseBracket(src projNode) (projNode, error) {
+	if _, err := p.expect("["); err != nil {
+		return nil, err
+	}
+	if p.accept("]") {
+		return projIterate{src: src}, nil
+	}
+	t := p.peek()
+	var n projNode
+	switch t.kind {
+	case "num":
+		i, err := strconv.Atoi(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %q", t.text)
+		}
+		n = projIndex{src: src, idx: i}
+	case "str":
+		n = projField{src: src, name: t.text}
+	default:
+		return nil, fmt.Errorf("unexpected token %q in brackets", t.display())
+	}
+	p.pos++
+	if _, err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (p *projParser) parseObject() (projNode, error) {
+	obj := projObject{}
+	if p.accept("}") {
+		return obj, nil
+	}
+	for {
+		t := p.peek()
+		if t.kind != "ident" && t.kind != "str" {
+			return nil, fmt.Errorf("expected object key, got %q", t.display())
+		}
+		p.pos++
+		entry := projObjectEntry{key: t.text}
+		if p.accept(":") {
+			v, err := p.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			entry.value = v
+		} else {
+			entry.value = projField{src: projIdentity{}, name: t.text}
+		}
+		obj.entries = append(obj.entries, entry)
+		if p.accept("}") {
+			return obj, nil
+		}
+		if _, err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type bufferedResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+// newBufferedResponse buffers a response that will be flushed to w. It
+// starts with the X-Request-ID of w, so error bodies written to it name the
+// request.
+func newBufferedResponse(w http.ResponseWriter) *bufferedResponse {
+	h := http.Header{}
+	if id := w.Header().Get(requestIDHeader); id != "" {
+		h.Set(requestIDHeader, id)
+	}
+	return &bufferedResponse{header: h}
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.code == 0 {
+		b.code = code
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.code == 0 {
+		b.code = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// copyHeader copies src into dst. Vary values are merged, as each
+// middleware adds the request headers it negotiates on.
+func copyHeader(dst, src http.Header) {
+	for k, v := range src {
+		if k == "Vary" {
+			dst[k] = append(dst[k], v...)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+func (b *bufferedResponse) flushTo(w http.ResponseWriter) {
+	copyHeader(w.Header(), b.header)
+	if b.code == 0 {
+		b.code = http.StatusOK
+	}
+	w.WriteHeader(b.code)
+	_, _ = w.Write(b.body.Bytes())
+}
+
+func withProjection(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expr := r.URL.Query().Get("project")
+		if expr == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		node, err := parseProjection(expr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PROJECTION", "invalid project expression: "+err.Error())
+			return
+		}
+		buf := newBufferedResponse(w)
+		next.ServeHTTP(buf, r)
+		if buf.code < 200 || buf.code >= 300 || !strings.HasPrefix(buf.header.Get("Content-Type"), "application/json") {
+			buf.flushTo(w)
+			return
+		}
+		dec := json.NewDecoder(&buf.body)
+		dec.UseNumber()
+		var doc interface{}
+		if err := dec.Decode(&doc); err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "unable to project response")
+			return
+		}
+		out, err := node.eval(doc)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "PROJECTION_FAILED", "project expression failed: "+err.Error())
+			return
+		}
+		var result interface{} = out
+		if len(out) == 1 {
+			result = out[0]
+		}
+		for k, v := range buf.header {
+			if k != "Content-Length" {
+				w.Header()[k] = v
+			}
+		}
+		writeJSON(w, buf.code, result)
+	})
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	defaultTenant            = store.DefaultTenant
+	defaultQuotaWindow       = time.Hour
+	tenantHeader             = "X-Tenant-ID"
+	quotaRemainingHeader     = "X-Quota-Remaining"
+	storageRemainingHeader   = "X-Storage-Quota-Remaining"
+	quotaWarningHeader       = "X-Quota-Warning"
+	quotaWarningThreshold    = 0.80
+	quotaCriticalThreshold   = 0.95
+	maxTrackedRequestTenants = 10000
+)
+
+var (
+	tenantIDPattern  = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	errQuotaExceeded = errors.New("storage quota exceeded")
+)
+
+// quotas holds the per-tenant limits; zero disables a limit.
+var quotas = struct {
+	storage  int
+	requests int
+	window   time.Duration
+}{window: defaultQuotaWindow}
+
+// tenantUsage counts stored records per tenant. It is maintained by the
+// index hooks, so it changes in lock step with the store.
+var tenantUsage = struct {
+	sync.Mutex
+	m map[string]int
+}{m: map[string]int{}}
+
+type requestWindow struct {
+	start time.Time
+	count int
+}
+
+var requestUsage = struct {
+	sync.Mutex
+	m map[string]*requestWindow
+}{m: map[string]*requestWindow{}}
+
+func tenantOf(r *http.Request) string {
+	if t := r.Header.Get(tenantHeader); t != "" {
+		return t
+	}
+	return defaultTenant
+}
+
+func storedTenant(item store.StoredString) string {
+	if item.Tenant == "" {
+		return defaultTenant
+	}
+	return item.Tenant
+}
+
+func tenantStored(tenant string) int {
+	tenantUsage.Lock()
+	defer tenantUsage.Unlock()
+	return tenantUsage.m[tenant]
+}
+
+func adjustTenantUsage(item store.StoredString, delta int) {
+	tenantUsage.Lock()
+	defer tenantUsage.Unlock()
+	t := storedTenant(item)
+	tenantUsage.m[t] += delta
+	if tenantUsage.m[t] <= 0 {
+		delete(tenantUsage.m, t)
+	}
+}
+
+func recountTenantUsage(items []store.StoredString) {
+	tenantUsage.Lock()
+	defer tenantUsage.Unlock()
+	tenantUsage.m = map[string]int{}
+	for _, item := range items {
+		tenantUsage.m[storedTenant(item)]++
+	}
+}
+
+// checkStorageQuota is called by indexedStorage before a Put, under its
+// write lock, so concurrent creates cannot overshoot the quota.
+func checkStorageQuota(item store.StoredString) error {
+	if quotas.storage > 0 && tenantStored(storedTenant(item)) >= quotas.storage {
+		return errQuotaExceeded
+	}
+	return nil
+}
+
+// countRequest records a request and returns the tenant's usage in the
+// current window and when that window ends.
+func countRequest(tenant string, now time.Time) (int, time.Time) {
+	requestUsage.Lock()
+	defer requestUsage.Unlock()
+	rw, ok := requestUsage.m[tenant]
+	if !ok || now.Sub(rw.start) >= quotas.window {
+		if !ok && len(requestUsage.m) >= maxTrackedRequestTenants {
+			for t, old := range requestUsage.m {
+				if now.Sub(old.start) >= quotas.window {
+					delete(requestUsage.m, t)
+				}
+			}
+		}
+		rw = &requestWindow{start: now}
+		requestUsage.m[tenant] = rw
+	}
+	rw.count++
+	return rw.count, rw.start.Add(quotas.window)
+}
+
+func requestsUsed(tenant string, now time.Time) (int, time.Time) {
+	requestUsage.Lock()
+	defer requestUsage.Unlock()
+	rw, ok := requestUsage.m[tenant]
+	if !ok || now.Sub(rw.start) >= quotas.window {
+		return 0, now.Add(quotas.window)
+	}
+	return rw.count, rw.start.Add(quotas.window)
+}
+
+// quotaWarning describes usage of a limit once it crosses 80% or 95%.
+func quotaWarning(kind string, used, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	share := float64(used) / float64(limit)
+	switch {
+	case share >= quotaCriticalThreshold:
+		return fmt.Sprintf("%s quota %d%% used (critical)", kind, int(math.Min(share, 1)*100))
+	case share >= quotaWarningThreshold:
+		return fmt.Sprintf("%s quota %d%% used", kind, int(share*100))
+	}
+	return ""
+}
+
+func quotaWarnings(tenant string, requests int) []string {
+	warnings := []string{}
+	if w := quotaWarning("request", requests, quotas.requests); w != "" {
+		warnings = append(warnings, w)
+	}
+	if w := quotaWarning("storage", tenantStored(tenant), quotas.storage); w != "" {
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+// setStorageQuotaHeaders reports the tenant's remaining storage and any
+// warnings; handlers that change usage call it again after the write.
+func setStorageQuotaHeaders(w http.ResponseWriter, tenant string, requests int) {
+	if quotas.storage > 0 {
+		w.Header().Set(storageRemainingHeader, strconv.Itoa(max(quotas.storage-tenantStored(tenant), 0)))
+	}
+	w.Header().Del(quotaWarningHeader)
+	for _, warning := range quotaWarnings(tenant, requests) {
+		w.Header().Add(quotaWarningHeader, warning)
+	}
+}
+
+// withQuota identifies the tenant, enforces the request quota and adds quota
+// headers to every response, with warnings from 80% of either quota.
+func withQuota(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := tenantOf(r)
+		if !tenantIDPattern.MatchString(tenant) {
+			writeError(w, http.StatusBadRequest, "INVALID_TENANT", "invalid "+tenantHeader)
+			return
+		}
+		now := time.Now()
+		used, resets := countRequest(tenant, now)
+		if quotas.requests > 0 {
+			if used > quotas.requests {
+				w.Header().Set(quotaRemainingHeader, "0")
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(resets.Sub(now).Seconds()))))
+				writeError(w, http.StatusTooManyRequests, "QUOTA_EXCEEDED", "request quota exceeded")
+				return
+			}
+			w.Header().Set(quotaRemainingHeader, strconv.Itoa(quotas.requests-used))
+		}
+		setStorageQuotaHeaders(w, tenant, used)
+		next.ServeHTTP(w, r)
+	})
+}
+
+type quotaState struct {
+	Used      int              `json:"used"`
+	Limit     *int             `json:"limit"`
+	Remaining *int             `json:"remaining"`
+	Percent   *float64         `json:"percent"`
+	ResetsAt  *store.Timestamp `json:"resets_at,omitempty"`
+}
+
+func newQuotaState(used, limit int) quotaState {
+	s := quotaState{Used: used}
+	if limit > 0 {
+		remaining := max(limit-used, 0)
+		percent := math.Round(float64(used)/float64(limit)*10000) / 100
+		s.Limit, s.Remaining, s.Percent = &limit, &remaining, &percent
+	}
+	return s
+}
+
+func usageHandler(w http.ResponseWriter, r *http.Request) {
+	tenant := tenantOf(r)
+	used, resets := requestsUsed(tenant, time.Now())
+	requests := newQuotaState(used, quotas.requests)
+	ts := store.NewTimestamp(resets)
+	requests.ResetsAt = &ts
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tenant":   tenant,
+		"requests": requests,
+		"storage":  newQuotaState(tenantStored(tenant), quotas.storage),
+		"warnings": quotaWarnings(tenant, used),
+	})
+}
+
+func parseQuotaEnv(name string) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s: %s", name, v)
+	}
+	return n, nil
+}
+
+// configureQuotas reads TENANT_STORAGE_QUOTA, TENANT_REQUEST_QUOTA and
+// TENANT_REQUEST_WINDOW_SECONDS.
+func configureQuotas() error {
+	var err error
+	if quotas.storage, err = parseQuotaEnv("TENANT_STORAGE_QUOTA"); err != nil {
+		return err
+	}
+	if quotas.requests, err = parseQuotaEnv("TENANT_REQUEST_QUOTA"); err != nil {
+		return err
+	}
+	window, err := parseQuotaEnv("TENANT_REQUEST_WINDOW_SECONDS")
+	if err != nil {
+		return err
+	}
+	if window > 0 {
+		quotas.window = time.Duration(window) * time.Second
+	}
+	return nil
+}
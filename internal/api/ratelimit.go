@@ -0,0 +1,100 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultRateLimitBurst = 20
+
+// rateLimit limits every client IP to the RateLimitPerMinute in effect.
+var rateLimit = newRateLimiter(0, defaultRateLimitBurst)
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+// set changes the limits. Buckets keep their tokens, capped at the new
+// burst; a rate of 0 lifts the limit.
+func (l *rateLimiter) set(perMinute, burst int) {
+	l.Lock()
+	defer l.Unlock()
+	l.rate, l.burst = float64(perMinute)/60, float64(burst)
+}
+
+// allow takes a token from the client's bucket, returning how long to wait
+// when the bucket is empty.
+func (l *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	if l.rate == 0 {
+		return true, 0
+	}
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+func (l *rateLimiter) prune(now time.Time) {
+	l.Lock()
+	defer l.Unlock()
+	for k, b := range l.buckets {
+		if l.rate == 0 || now.Sub(b.last) > time.Duration(l.burst/l.rate)*time.Second {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func withRateLimit(l *rateLimiter, next http.Handler) http.Handler {
+	go func() {
+		for now := range time.Tick(time.Minute) {
+			l.prune(now)
+		}
+	}()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := l.allow(clientIP(r), time.Now())
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, "RATE_LIMITED", "rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
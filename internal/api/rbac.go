@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// roles lists the roles from least to most privileged: viewers may only
+// make GET and HEAD requests, editors may also create, change and delete
+// the tenant's strings, and admins may use the /admin routes and act as any
+// tenant.
+var roles = []string{"viewer", "editor", "admin"}
+
+// legacyRoles maps the read and write scopes API keys were first issued
+// with to their roles.
+var legacyRoles = map[string]string{"read": "viewer", "write": "editor"}
+
+// normalizeRole returns role, or the role a legacy scope stands for, and
+// "" for anything else.
+func normalizeRole(role string) string {
+	if r, ok := legacyRoles[role]; ok {
+		return r
+	}
+	if roleRank(role) < 0 {
+		return ""
+	}
+	return role
+}
+
+func roleRank(role string) int {
+	for i, r := range roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// requiredRole is the least role allowed to make r.
+func requiredRole(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, "/admin/") {
+		return "admin"
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return "viewer"
+	}
+	return "editor"
+}
+
+// writeForbidden answers 403 with a code clients can act on, and the role
+// held and needed when the role is what fell short.
+func writeForbidden(w http.ResponseWriter, code, msg, role, required string) {
+	var details map[string]string
+	if required != "" {
+		details = map[string]string{"role": role, "required_role": required}
+	}
+	writeErrorDetails(w, http.StatusForbidden, code, msg, details)
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const testAdminKey = "test-admin-key"
+
+// useAPIKeys enables authentication with ADMIN_API_KEY and one key per
+// secret in keys, restoring the previous keys after the test.
+func useAPIKeys(t *testing.T, keys map[string]apiKey) {
+	t.Helper()
+	apiKeys.Lock()
+	enabled, path, adminHash, m := apiKeys.enabled, apiKeys.path, apiKeys.adminHash, apiKeys.m
+	apiKeys.enabled, apiKeys.path, apiKeys.adminHash = true, "", hashAPIKey(testAdminKey)
+	apiKeys.m = map[string]*storedKey{}
+	for secret, k := range keys {
+		apiKeys.m[k.ID] = &storedKey{apiKey: k, Hash: hashAPIKey(secret)}
+	}
+	apiKeys.Unlock()
+	t.Cleanup(func() {
+		apiKeys.Lock()
+		defer apiKeys.Unlock()
+		apiKeys.enabled, apiKeys.path, apiKeys.adminHash, apiKeys.m = enabled, path, adminHash, m
+	})
+}
+
+func TestWithAuthRoles(t *testing.T) {
+	resetJWT(t)
+	revokedAt := store.Now()
+	useAPIKeys(t, map[string]apiKey{
+		"viewer-key":  {ID: "k1", Tenant: "acme", Role: "viewer"},
+		"editor-key":  {ID: "k2", Tenant: "acme", Role: "editor"},
+		"admin-key":   {ID: "k3", Tenant: "acme", Role: "admin"},
+		"revoked-key": {ID: "k4", Tenant: "acme", Role: "admin", RevokedAt: &revokedAt},
+	})
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		key        string
+		tenant     string
+		wantStatus int
+		wantCode   string
+		wantTenant string
+	}{
+		{name: "no key", method: http.MethodGet, path: "/strings", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHENTICATED"},
+		{name: "unknown key", method: http.MethodGet, path: "/strings", key: "guess", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHENTICATED"},
+		{name: "revoked key", method: http.MethodGet, path: "/strings", key: "revoked-key", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHENTICATED"},
+		{name: "viewer reads", method: http.MethodGet, path: "/strings", key: "viewer-key", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "viewer heads", method: http.MethodHead, path: "/strings/abba", key: "viewer-key", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "viewer creates", method: http.MethodPost, path: "/strings", key: "viewer-key", wantStatus: http.StatusForbidden, wantCode: "INSUFFICIENT_ROLE"},
+		{name: "viewer deletes", method: http.MethodDelete, path: "/strings/abba", key: "viewer-key", wantStatus: http.StatusForbidden, wantCode: "INSUFFICIENT_ROLE"},
+		{name: "editor creates", method: http.MethodPost, path: "/strings", key: "editor-key", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "editor deletes", method: http.MethodDelete, path: "/strings/abba", key: "editor-key", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "editor reads admin routes", method: http.MethodGet, path: "/admin/keys", key: "editor-key", wantStatus: http.StatusForbidden, wantCode: "INSUFFICIENT_ROLE"},
+		{name: "admin key reads admin routes", method: http.MethodGet, path: "/admin/keys", key: "admin-key", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "ADMIN_API_KEY reads admin routes", method: http.MethodGet, path: "/admin/keys", key: testAdminKey, wantStatus: http.StatusOK, wantTenant: defaultTenant},
+		{name: "own tenant named", method: http.MethodGet, path: "/strings", key: "viewer-key", tenant: "acme", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "other tenant named", method: http.MethodGet, path: "/strings", key: "editor-key", tenant: "globex", wantStatus: http.StatusForbidden, wantCode: "TENANT_MISMATCH"},
+		{name: "admin acts as another tenant", method: http.MethodPost, path: "/strings", key: "admin-key", tenant: "globex", wantStatus: http.StatusOK, wantTenant: "globex"},
+		{name: "preflight needs no key", method: http.MethodOptions, path: "/strings", wantStatus: http.StatusOK},
+		{name: "shared link needs no key", method: http.MethodGet, path: "/shared/token", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotTenant string
+			h := withAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotTenant = r.Header.Get(tenantHeader)
+			}))
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				r.Header.Set(apiKeyHeader, tt.key)
+			}
+			if tt.tenant != "" {
+				r.Header.Set(tenantHeader, tt.tenant)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantCode != "" {
+				if code := errorCode(t, w); code != tt.wantCode {
+					t.Fatalf("code = %s, want %s", code, tt.wantCode)
+				}
+				return
+			}
+			if gotTenant != tt.wantTenant {
+				t.Fatalf("tenant = %q, want %q", gotTenant, tt.wantTenant)
+			}
+		})
+	}
+}
+
+// TestWithAuthBearerRole checks that a bearer token is held to the role it
+// maps to, like an API key.
+func TestWithAuthBearerRole(t *testing.T) {
+	resetJWT(t)
+	useAPIKeys(t, nil)
+	jwtAuth.secret = []byte(testJWTSecret)
+	jwtAuth.roleMap = map[string]string{"reader": "viewer"}
+	claims := validClaims()
+	claims["role"], claims["tenant"] = "reader", "acme"
+	token := encodeJWT(t, map[string]interface{}{"alg": "HS256"}, claims, hs256([]byte(testJWTSecret)))
+	h := withAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, tt := range []struct {
+		method string
+		want   int
+	}{{http.MethodGet, http.StatusOK}, {http.MethodPost, http.StatusForbidden}} {
+		r := httptest.NewRequest(tt.method, "/strings", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d: %s", tt.method, w.Code, tt.want, w.Body.String())
+		}
+	}
+}
+
+func TestNormalizeRole(t *testing.T) {
+	for role, want := range map[string]string{
+		"viewer": "viewer",
+		"editor": "editor",
+		"admin":  "admin",
+		"read":   "viewer",
+		"write":  "editor",
+		"root":   "",
+		"":       "",
+	} {
+		if got := normalizeRole(role); got != want {
+			t.Errorf("normalizeRole(%q) = %q, want %q", role, got, want)
+		}
+	}
+}
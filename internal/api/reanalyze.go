@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const maxReanalyzeBatch = 1000
+
+// reanalyzeStringHandler recomputes the properties of one stored string in
+// place, e.g. after new analyzers were added. The record's collection
+// configuration applies unless case_insensitive or language override it.
+// If-Match restricts it to the listed record versions.
+func reanalyzeStringHandler(w http.ResponseWriter, r *http.Request) {
+	value := r.PathValue("value")
+	pre, err := parseIfMatch(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PRECONDITION", err.Error())
+		return
+	}
+	id, ok := pathRecordID(w, r, value)
+	if !ok {
+		return
+	}
+	item, err := storeFor(r.Context()).Get(id)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	opts, err := requestAnalysisOptions(r, item.Collection)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_OPTIONS", err.Error())
+		return
+	}
+	item, err = reanalyzeString(item.ID, opts, pre)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+type bulkReanalyzeReq struct {
+	Values     []string `json:"values"`
+	Collection string   `json:"collection"`
+}
+
+// bulkReanalyzeHandler re-analyzes the listed strings of one collection
+// synchronously with its configuration. Whole collections are better served
+// by POST /jobs/reanalyze.
+func bulkReanalyzeHandler(w http.ResponseWriter, r *http.Request) {
+	var body bulkReanalyzeReq
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if len(body.Values) == 0 || len(body.Values) > maxReanalyzeBatch {
+		writeError(w, http.StatusBadRequest, "MISSING_VALUES", fmt.Sprintf(`"values" must hold between 1 and %d strings`, maxReanalyzeBatch))
+		return
+	}
+	if body.Collection == "" {
+		body.Collection = r.URL.Query().Get("collection")
+	}
+	if body.Collection != "" && !collectionNamePattern.MatchString(body.Collection) {
+		writeError(w, http.StatusBadRequest, "INVALID_COLLECTION", "invalid collection name")
+		return
+	}
+	data := make([]store.StoredString, 0, len(body.Values))
+	errs := []chunkError{}
+	for i, v := range body.Values {
+		item, err := storeFor(r.Context()).Get(recordID(tenantOf(r), body.Collection, v))
+		if err == nil && !recordInScope(item, tenantOf(r), body.Collection) {
+			err = store.ErrNotFound
+		}
+		if err == nil {
+			item, err = reanalyzeString(item.ID, collectionOptions(storedTenant(item), item.Collection), nil)
+		}
+		if err != nil {
+			errs = append(errs, chunkError{Index: i, Error: err.Error()})
+			continue
+		}
+		data = append(data, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":   data,
+		"count":  len(data),
+		"errors": errs,
+	})
+}
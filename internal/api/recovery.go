@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// recoveryResponse records whether the response has started, after which a
+// panic can only abort it.
+type recoveryResponse struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (rw *recoveryResponse) WriteHeader(code int) {
+	rw.started = true
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recoveryResponse) Write(p []byte) (int, error) {
+	rw.started = true
+	return rw.ResponseWriter.Write(p)
+}
+
+// withRecovery turns a panicking handler into a 500, logging the panic and
+// its stack trace under the request ID the error body names. A response
+// already under way is aborted instead, as its status cannot change.
+func withRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recoveryResponse{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			requestLogger(r).Error("panic serving request", "panic", fmt.Sprint(v), "stack", string(debug.Stack()))
+			if rw.started {
+				panic(http.ErrAbortHandler)
+			}
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
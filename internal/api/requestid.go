@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// requestIDPattern bounds the request IDs accepted from clients and
+// proxies; others are replaced rather than echoed into logs and responses.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+var logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+// configureLogging applies LOG_FORMAT and LOG_LEVEL.
+func configureLogging() error {
+	var level slog.Level
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		if err := level.UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL: %s", v)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	switch v := os.Getenv("LOG_FORMAT"); v {
+	case "", "json":
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, opts))
+	case "text":
+		logger = slog.New(slog.NewTextHandler(os.Stdout, opts))
+	default:
+		return fmt.Errorf("invalid LOG_FORMAT: %s", v)
+	}
+	store.Logger = logger
+	return nil
+}
+
+// Logger returns the logger of the server, which applies LOG_FORMAT and
+// LOG_LEVEL once NewServer has run.
+func Logger() *slog.Logger {
+	return logger
+}
+
+type requestLoggerKey struct{}
+
+// requestLogger returns the logger of r, which names its request ID.
+func requestLogger(r *http.Request) *slog.Logger {
+	if l, ok := r.Context().Value(requestLoggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return logger
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// withRequestID names every request by the X-Request-ID it came with, or a
+// new one, and returns it in the X-Request-ID response header, from which
+// writeErrorDetails copies it into error bodies so a failed request can be
+// traced in the logs.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+			r.Header.Set(requestIDHeader, id)
+		}
+		w.Header().Set(requestIDHeader, id)
+		r = r.WithContext(context.WithValue(r.Context(), requestLoggerKey{}, logger.With("request_id", id)))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorBodiesNameTheRequest(t *testing.T) {
+	h := withRequestID(withContentNegotiation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+	})))
+	for accept, want := range map[string]string{
+		"application/json": `"request_id":"req-1"`,
+		"application/xml":  `<request_id>req-1</request_id>`,
+		"application/yaml": `request_id: req-1`,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/strings/missing", nil)
+		r.Header.Set("Accept", accept)
+		r.Header.Set(requestIDHeader, "req-1")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%s: body %q does not contain %s", accept, w.Body.String(), want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// router serves the API routes.
+var router = http.NewServeMux()
+
+// registerRoutes installs the API with handle, which is router.HandleFunc
+// when serving and a collector when the OpenAPI document checks that it
+// describes every route. Patterns name their method, so withMethods can
+// answer 405 with an Allow header for the others, and a literal route such
+// as /strings/export takes precedence over the value route only for the
+// methods it serves.
+func registerRoutes(handle func(pattern string, handler func(http.ResponseWriter, *http.Request))) {
+	handle("GET /strings", getAllStringsHandler)
+	handle("POST /strings", withIdempotencyKey(postStringsHandler))
+	handle("GET /strings/filter-by-natural-language", naturalLanguageHandler)
+	handle("GET /strings/stats/timeseries", timeseriesHandler)
+	handle("GET /strings/stats/words", wordStatsHandler)
+	handle("GET /strings/typeahead", typeaheadHandler)
+	handle("GET /strings/palindrome-pairs", palindromePairsHandler)
+	handle("GET /strings/fuzzy", fuzzyHandler)
+	handle("GET /strings/export", exportHandler)
+	handle("POST /strings/upload", uploadStringHandler)
+	handle("POST /strings/from-url", fromURLHandler)
+	handle("GET /strings/semantic-search", semanticSearchHandler)
+	handle("POST /strings/reanalyze", bulkReanalyzeHandler)
+	handle("GET /strings/id/{id}", stringByIDHandler)
+	handle("DELETE /strings/id/{id}", stringByIDHandler)
+	handle("GET /strings/{value}", getStringByValueHandler)
+	handle("PUT /strings/{value}", putStringHandler)
+	handle("DELETE /strings/{value}", deleteStringHandler)
+	handle("/strings/{value}/{action}", stringActionHandler)
+	handle("GET /stats/query", grafanaTestHandler)
+	handle("GET /stats/query/{$}", grafanaTestHandler)
+	handle("POST /stats/query/search", grafanaSearchHandler)
+	handle("POST /stats/query/query", grafanaQueryHandler)
+	handle("GET /admin/indexes", indexListHandler)
+	handle("GET /admin/indexes/{name}", indexStatusHandler)
+	handle("POST /admin/indexes/{name}/{action}", indexActionHandler)
+	handle("GET /admin/scrub", scrubReportHandler)
+	handle("POST /admin/scrub", scrubHandler)
+	handle("GET /admin/cache", cacheStatsHandler)
+	handle("GET /admin/webhooks", webhookStatusHandler)
+	handle("GET /admin/enrichment", enrichmentStatusHandler)
+	handle("GET /admin/precompute", precomputeStatusHandler)
+	handle("POST /admin/precompute", precomputeHandler)
+	handle("DELETE /admin/precompute", clearPrecomputedHandler)
+	handle("DELETE /admin/strings/{value}", hardDeleteStringHandler)
+	handle("GET /admin/shadow", shadowStatusHandler)
+	handle("PUT /admin/shadow", shadowHandler)
+	handle("DELETE /admin/shadow", clearShadowHandler)
+	handle("POST /admin/compaction", compactionHandler)
+	handle("GET /admin/compaction/status", compactionStatusHandler)
+	handle("GET /collections", collectionsHandler)
+	handle("POST /collections", createCollectionHandler)
+	handle("GET /collections/{name}", collectionHandler)
+	handle("DELETE /collections/{name}", deleteCollectionHandler)
+	handle("GET /collections/{name}/config", collectionConfigHandler)
+	handle("PUT /collections/{name}/config", putCollectionConfigHandler)
+	handle("/collections/{name}/strings", collectionStringsHandler)
+	handle("/collections/{name}/strings/{rest...}", collectionStringsHandler)
+	handle("POST /jobs/import", importJobHandler)
+	handle("POST /jobs/reanalyze", reanalyzeJobHandler)
+	handle("GET /jobs/{id}", jobHandler)
+	handle("POST /jobs/{id}/cancel", cancelJobHandler)
+	handle("GET /admin/snapshots", snapshotsHandler)
+	handle("POST /admin/snapshots", takeSnapshotHandler)
+	handle("GET /admin/snapshots/diff", snapshotDiffHandler)
+	handle("GET /shared/{token}", sharedRecordHandler)
+	handle("GET /usage", usageHandler)
+	handle("GET /admin/keys", apiKeysHandler)
+	handle("POST /admin/keys", createAPIKeyHandler)
+	handle("GET /admin/keys/{id}", apiKeyHandler)
+	handle("DELETE /admin/keys/{id}", revokeAPIKeyHandler)
+	handle("POST /admin/keys/{id}/rotate", rotateAPIKeyHandler)
+	handle("GET /admin/tenants", tenantsHandler)
+	handle("GET /admin/tenants/{tenant}", tenantHandler)
+	handle("DELETE /admin/tenants/{tenant}", purgeTenantHandler)
+	handle("POST /admin/tenants/{tenant}/export", exportTenantHandler)
+	handle("GET /events", eventsHandler)
+	handle("GET /audit", auditHandler)
+	handle("GET /openapi.json", openAPIHandler)
+	handle("GET /docs", docsHandler)
+}
+
+// stringActions are the routes under /strings/{value}/. They share one
+// pattern because GET /strings/{value}/history would otherwise overlap
+// GET /strings/id/{id}, which the mux refuses to register.
+var stringActions = map[string]map[string]http.HandlerFunc{
+	"history":   {http.MethodGet: historyHandler},
+	"tags":      {http.MethodPatch: tagsHandler},
+	"reanalyze": {http.MethodPatch: reanalyzeStringHandler},
+	"share":     {http.MethodPost: shareStringHandler},
+	"restore":   {http.MethodPost: restoreStringHandler},
+}
+
+// stringAction returns the handler of the action for method, or nil.
+func stringAction(method, action string) http.HandlerFunc {
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	return stringActions[action][method]
+}
+
+func stringActionHandler(w http.ResponseWriter, r *http.Request) {
+	if h := stringAction(r.Method, r.PathValue("action")); h != nil {
+		h(w, r)
+		return
+	}
+	writeError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+}
+
+// valueRoutes are the patterns a string's own routes are served by.
+var valueRoutes = map[string]bool{"GET /strings/{value}": true, "/strings/{value}/{action}": true}
+
+// reservedValue returns the literal route, such as GET /strings/export or
+// GET /strings/id/{id}, that a request for the string v or its history
+// would reach instead of the string, or "" when there is none. Dots are
+// escaped as clients must, so "." and ".." are not taken for path segments.
+func reservedValue(v string) string {
+	base := "/strings/" + strings.ReplaceAll(url.PathEscape(v), ".", "%2E")
+	for _, path := range []string{base, base + "/history"} {
+		u, err := url.Parse(path)
+		if err != nil {
+			return ""
+		}
+		if _, pattern := router.Handler(&http.Request{Method: http.MethodGet, URL: u}); pattern != "" && !valueRoutes[pattern] {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// routeMethods are the methods a path is probed for, in the order Allow
+// lists them.
+var routeMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// serves reports whether a route serves the method and path of r. Routes
+// registered without a method dispatch on the rest of the path themselves,
+// so they are asked in turn.
+func serves(r *http.Request) bool {
+	_, pattern := router.Handler(r)
+	switch pattern {
+	case "":
+		return false
+	case "/strings/{value}/{action}":
+		return stringAction(r.Method, pathSegment(r, -1)) != nil
+	case "/collections/{name}/strings", "/collections/{name}/strings/{rest...}":
+		name := pathSegment(r, 2)
+		if !collectionNamePattern.MatchString(name) {
+			return true
+		}
+		scoped := collectionRequest(r, name)
+		return collectionRoutes[routeOf(scoped)] && serves(scoped)
+	}
+	return true
+}
+
+// pathSegment returns the i-th segment of the path of r, counting from the
+// end when i is negative.
+func pathSegment(r *http.Request, i int) string {
+	segments := strings.Split(r.URL.EscapedPath(), "/")
+	if i < 0 {
+		i += len(segments)
+	}
+	if i < 0 || i >= len(segments) {
+		return ""
+	}
+	s, _ := url.PathUnescape(segments[i])
+	return s
+}
+
+// allowedMethods returns the methods some route serves the path of r with.
+func allowedMethods(r *http.Request) []string {
+	var allowed []string
+	for _, m := range routeMethods {
+		probe := *r
+		probe.Method = m
+		if serves(&probe) {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed
+}
+
+// withMethods dispatches by method ahead of the router: paths no route
+// serves are 404, OPTIONS answers which methods a path is served with, and
+// the other methods are 405, both with an Allow header.
+func withMethods(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions && serves(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		allowed := allowedMethods(r)
+		if len(allowed) == 0 {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "not found")
+			return
+		}
+		writeAllow(w, r, allowed)
+	})
+}
+
+// writeAllow answers a request that allowed does not include: OPTIONS with
+// 204 and other methods with 405, both naming allowed and OPTIONS in Allow.
+func writeAllow(w http.ResponseWriter, r *http.Request, allowed []string) {
+	allowed = append(allowed[:len(allowed):len(allowed)], http.MethodOptions)
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeErrorDetails(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method "+r.Method+" is not allowed", map[string]interface{}{"allowed": allowed})
+}
+
+// routeOf names the route that serves r by the path its pattern matches up
+// to the first wildcard: "/strings/" for every value route, "/strings/id/"
+// for lookups by ID and "/strings" for the listing. Cache policies,
+// precomputed queries and the other per-route settings are keyed by it, so
+// they name a route the same way whatever its method and wildcards.
+func routeOf(r *http.Request) string {
+	_, pattern := router.Handler(r)
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = path
+	}
+	if i := strings.IndexByte(pattern, '{'); i >= 0 {
+		pattern = pattern[:i]
+	}
+	return pattern
+}
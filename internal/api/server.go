@@ -0,0 +1,308 @@
+// Package api serves the string analysis HTTP API. Its configuration,
+// indexes, caches and background workers are package state, so a process
+// runs at most one Server.
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+	"google.golang.org/grpc"
+)
+
+// Server is the API with its middleware, bound to the address and TLS
+// settings of its Config.
+type Server struct {
+	http     *http.Server
+	grpc     *grpc.Server
+	grpcAddr string
+}
+
+var serverCreated bool
+
+// NewServer configures the API from cfg and the environment, installs s as
+// its storage, opening the backend cfg names when s is nil, and starts the
+// background workers.
+func NewServer(s store.Storage, cfg Config) (*Server, error) {
+	if serverCreated {
+		return nil, errors.New("a server was already created in this process")
+	}
+	serverCreated = true
+	applyConfig(&cfg)
+	registerRoutes(router.HandleFunc)
+	if tz := os.Getenv("OUTPUT_TIMEZONE"); tz != "" {
+		if err := store.SetOutputTimezone(tz); err != nil {
+			return nil, fmt.Errorf("invalid OUTPUT_TIMEZONE: %w", err)
+		}
+	}
+	if err := configureLogging(); err != nil {
+		return nil, err
+	}
+	if err := configureTracing(); err != nil {
+		return nil, fmt.Errorf("unable to configure tracing: %w", err)
+	}
+	if err := configureCachePolicies(os.Getenv("CACHE_POLICIES")); err != nil {
+		return nil, err
+	}
+	if err := configureShadowAnalysis(); err != nil {
+		return nil, err
+	}
+	if err := configureQuotas(); err != nil {
+		return nil, err
+	}
+	if err := configureURLFetch(); err != nil {
+		return nil, err
+	}
+	if err := configureEmbeddings(); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		var err error
+		if s, err = OpenStorage(cfg); err != nil {
+			return nil, fmt.Errorf("unable to open storage: %w", err)
+		}
+	}
+	if err := installStorage(s); err != nil {
+		return nil, fmt.Errorf("unable to open storage: %w", err)
+	}
+	if v := os.Getenv("EVENTS_MAX_ENTRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid EVENTS_MAX_ENTRIES: %s", v)
+		}
+		events.max = n
+	}
+	if err := openEventLog(cfg.EventsPath); err != nil {
+		return nil, fmt.Errorf("unable to open event log: %w", err)
+	}
+	if v := os.Getenv("AUDIT_MAX_ENTRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid AUDIT_MAX_ENTRIES: %s", v)
+		}
+		audit.max = n
+	}
+	if err := openAuditLog(cfg.AuditPath); err != nil {
+		return nil, fmt.Errorf("unable to open audit log: %w", err)
+	}
+	if err := startWebhooks(cfg.EventsPath); err != nil {
+		return nil, fmt.Errorf("unable to start webhooks: %w", err)
+	}
+	// Trim the loaded stream only now that undelivered events are held.
+	events.Lock()
+	trimEvents()
+	events.Unlock()
+	if err := startEnrichment(); err != nil {
+		return nil, fmt.Errorf("unable to start enrichment: %w", err)
+	}
+	initShareKey(os.Getenv("SHARE_SECRET"))
+	sweepInterval := defaultExpirySweepInterval
+	if v := os.Getenv("EXPIRY_SWEEP_INTERVAL_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid EXPIRY_SWEEP_INTERVAL_SECONDS: %s", v)
+		}
+		sweepInterval = time.Duration(n) * time.Second
+	}
+	startExpirySweeper(sweepInterval)
+	if v := os.Getenv("IDEMPOTENCY_TTL_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid IDEMPOTENCY_TTL_SECONDS: %s", v)
+		}
+		idempotency.ttl = time.Duration(n) * time.Second
+	}
+	if err := startJobs(cfg.JobsDir, cfg.JobWorkers); err != nil {
+		return nil, fmt.Errorf("unable to start jobs: %w", err)
+	}
+	if v := os.Getenv("ANALYZER_TIMEOUT_MS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid ANALYZER_TIMEOUT_MS: %s", v)
+		}
+		analysis.Timeout = time.Duration(n) * time.Millisecond
+	}
+	scrubInterval := time.Hour
+	if v := os.Getenv("SCRUB_INTERVAL_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid SCRUB_INTERVAL_SECONDS: %s", v)
+		}
+		scrubInterval = time.Duration(n) * time.Second
+	}
+	if scrubInterval > 0 {
+		startScrubber(scrubInterval)
+	}
+	snapshotInterval := store.DefaultSnapshotInterval
+	if v := os.Getenv("STORE_SNAPSHOT_INTERVAL_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid STORE_SNAPSHOT_INTERVAL_SECONDS: %s", v)
+		}
+		snapshotInterval = time.Duration(n) * time.Second
+	}
+	if store.Persistent() && snapshotInterval > 0 {
+		every(snapshotInterval, func() {
+			if err := store.SaveSnapshot(); err != nil {
+				logger.Error("store snapshot failed", "error", err)
+			}
+		})
+	}
+	compactionInterval := time.Hour
+	if v := os.Getenv("STORE_COMPACTION_INTERVAL_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid STORE_COMPACTION_INTERVAL_SECONDS: %s", v)
+		}
+		compactionInterval = time.Duration(n) * time.Second
+	}
+	startCompaction(compactionInterval)
+	if err := configureJWT(); err != nil {
+		return nil, err
+	}
+	startPprofServer(cfg.PprofAddr)
+	if err := configureCORS(); err != nil {
+		return nil, err
+	}
+	if err := configureAuth(os.Getenv("ADMIN_API_KEY"), cfg.APIKeysPath); err != nil {
+		return nil, fmt.Errorf("unable to load API keys: %w", err)
+	}
+	// Handler panics are recovered inside the middleware too, so audit and
+	// the other layers see the 500 they turn into.
+	var handler http.Handler = withAuth(withPprof(withMethods(withRequestTimeout(withQuota(withCachePolicy(withETag(withContentNegotiation(withProjection(withPrecomputed(withRecovery(router)))))))))))
+	if secret := os.Getenv("SIGNING_SECRET"); secret != "" {
+		if cfg.GRPCAddr != "" {
+			return nil, errors.New("GRPC_ADDR cannot be used with SIGNING_SECRET: gRPC calls are not signed")
+		}
+		window := 5 * time.Minute
+		if v := os.Getenv("SIGNING_WINDOW_SECONDS"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid SIGNING_WINDOW_SECONDS: %s", v)
+			}
+			window = time.Duration(n) * time.Second
+		}
+		handler = withSignature(secret, window, handler)
+	}
+	handler = withRateLimit(rateLimit, handler)
+	if cfg.Demo {
+		startDemoMode()
+		logger.Info("demo mode enabled")
+	}
+	apiVersions[currentAPIVersion] = withBodyLimit(withAudit(handler))
+	handler = withAccessLog(withCompression(withRequestID(withTracing(withRecovery(withProbes(withCORS(withAPIVersions())))))))
+	tlsConfig, err := serverTLSConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to configure TLS: %w", err)
+	}
+	srv := newServer(cfg.Addr(), handler)
+	srv.TLSConfig = tlsConfig
+	server := &Server{http: srv, grpcAddr: cfg.GRPCAddr}
+	if cfg.GRPCAddr != "" {
+		server.grpc = newGRPCServer(handler, tlsConfig)
+	}
+	return server, nil
+}
+
+// ServeHTTP serves r through the full middleware chain, for callers that
+// run their own listener.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.http.Handler.ServeHTTP(w, r)
+}
+
+// ListenAndServe accepts connections on the configured address, over TLS
+// when certificates are configured, until Shutdown. The gRPC service, when
+// configured, is served on its own address alongside.
+func (s *Server) ListenAndServe() error {
+	if s.grpc != nil {
+		go serveGRPC(s.grpc, s.grpcAddr)
+	}
+	logger.Info("server listening", "addr", s.http.Addr, "tls", s.http.TLSConfig != nil)
+	if s.http.TLSConfig != nil {
+		return s.http.ListenAndServeTLS("", "")
+	}
+	return s.http.ListenAndServe()
+}
+
+// Shutdown drains in-flight requests and calls until ctx is done, cutting
+// off the rest, stops the background workers, then flushes traces, saves
+// the store and closes the backend.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.grpc != nil {
+		stopped := make(chan struct{})
+		go func() {
+			s.grpc.GracefulStop()
+			close(stopped)
+		}()
+		defer func() {
+			s.grpc.Stop()
+			<-stopped
+		}()
+	}
+	err := s.http.Shutdown(ctx)
+	if err != nil {
+		_ = s.http.Close()
+	}
+	if berr := stopBackground(ctx); berr != nil {
+		logger.Error("background workers still running at the shutdown timeout", "error", berr)
+	}
+	shutdownTracing(ctx)
+	flushPersistence()
+	return err
+}
+
+// Reload re-reads the configuration and puts its tunables in effect.
+func (s *Server) Reload() {
+	reloadConfig()
+}
+
+// Run serves until SIGINT or SIGTERM and then shuts down within the
+// configured timeout; a second signal exits without draining. SIGHUP
+// reloads the configuration.
+func (s *Server) Run() error {
+	errc := make(chan error, 1)
+	go func() {
+		if err := s.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			errc <- err
+		}
+	}()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			s.Reload()
+		}
+	}()
+	stop := make(chan os.Signal, 2)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	var sig os.Signal
+	select {
+	case err := <-errc:
+		logger.Error("server error", "error", err)
+		return err
+	case sig = <-stop:
+	}
+	shutdownTimeout := time.Duration(settings().ShutdownTimeoutSeconds) * time.Second
+	logger.Info("shutting down", "signal", sig.String(), "timeout", shutdownTimeout.String())
+	go func() {
+		<-stop
+		logger.Error("second signal received, exiting without draining")
+		os.Exit(1)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		logger.Error("requests still in flight at the shutdown timeout were cut off", "error", err)
+	}
+	logger.Info("shutdown complete")
+	return nil
+}
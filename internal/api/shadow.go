@@ -0,0 +1,216 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	shadowQueueSize  = 1024
+	maxShadowSamples = 20
+)
+
+type shadowSample struct {
+	ID        string      `json:"id"`
+	Property  string      `json:"property"`
+	Current   interface{} `json:"current"`
+	Candidate interface{} `json:"candidate"`
+}
+
+type shadowJob struct {
+	item store.StoredString
+	opts analysis.Options
+}
+
+// shadow runs a candidate analyzer configuration beside the live one. Each
+// newly stored string is analyzed again with the candidate overrides
+// applied to the options it was stored with, off the request path, and the
+// properties that come out differently are counted and sampled.
+var shadow = struct {
+	sync.Mutex
+	overrides  json.RawMessage
+	startedAt  store.Timestamp
+	compared   int
+	differing  int
+	dropped    int
+	properties map[string]int
+	samples    []shadowSample
+	queue      chan shadowJob
+	once       sync.Once
+}{}
+
+// candidateOptions applies the JSON overrides to opts and normalizes them.
+func candidateOptions(opts analysis.Options, overrides json.RawMessage) (analysis.Options, error) {
+	opts.EnabledAnalyzers = slices.Clone(opts.EnabledAnalyzers)
+	opts.Normalization = slices.Clone(opts.Normalization)
+	opts.StopwordLanguages = slices.Clone(opts.StopwordLanguages)
+	opts.Stopwords = slices.Clone(opts.Stopwords)
+	if err := json.Unmarshal(overrides, &opts); err != nil {
+		return opts, err
+	}
+	return opts.Normalize()
+}
+
+func setShadowOverrides(overrides json.RawMessage) error {
+	if overrides != nil {
+		if _, err := candidateOptions(analysis.DefaultOptions(), overrides); err != nil {
+			return err
+		}
+	}
+	shadow.Lock()
+	defer shadow.Unlock()
+	shadow.overrides = overrides
+	shadow.startedAt = store.Now()
+	shadow.compared, shadow.differing, shadow.dropped = 0, 0, 0
+	shadow.properties = map[string]int{}
+	shadow.samples = nil
+	if overrides != nil {
+		shadow.once.Do(func() {
+			shadow.queue = make(chan shadowJob, shadowQueueSize)
+			go runShadowAnalysis(shadow.queue)
+		})
+	}
+	return nil
+}
+
+func configureShadowAnalysis() error {
+	v := os.Getenv("SHADOW_ANALYSIS")
+	if v == "" {
+		return nil
+	}
+	if err := setShadowOverrides(json.RawMessage(v)); err != nil {
+		return fmt.Errorf("invalid SHADOW_ANALYSIS: %w", err)
+	}
+	return nil
+}
+
+// shadowAnalyze queues item for comparison when a candidate is set. The
+// queue never blocks ingest; comparisons that do not fit are dropped.
+func shadowAnalyze(item store.StoredString, opts analysis.Options) {
+	shadow.Lock()
+	defer shadow.Unlock()
+	if shadow.overrides == nil {
+		return
+	}
+	select {
+	case shadow.queue <- shadowJob{item, opts}:
+	default:
+		shadow.dropped++
+	}
+}
+
+func propertyMap(p analysis.Properties) map[string]interface{} {
+	data, _ := json.Marshal(p)
+	m := map[string]interface{}{}
+	_ = json.Unmarshal(data, &m)
+	return m
+}
+
+func runShadowAnalysis(queue <-chan shadowJob) {
+	for job := range queue {
+		shadow.Lock()
+		overrides := shadow.overrides
+		shadow.Unlock()
+		if overrides == nil {
+			continue
+		}
+		opts, err := candidateOptions(job.opts, overrides)
+		if err != nil {
+			continue
+		}
+		current := propertyMap(job.item.Properties)
+		candidate := propertyMap(analysis.Analyze(job.item.Value, opts))
+		var diffs []string
+		for name := range current {
+			if !reflect.DeepEqual(current[name], candidate[name]) {
+				diffs = append(diffs, name)
+			}
+		}
+		for name := range candidate {
+			if _, ok := current[name]; !ok {
+				diffs = append(diffs, name)
+			}
+		}
+		sort.Strings(diffs)
+
+		shadow.Lock()
+		if string(shadow.overrides) != string(overrides) {
+			shadow.Unlock()
+			continue
+		}
+		shadow.compared++
+		if len(diffs) > 0 {
+			shadow.differing++
+			for _, name := range diffs {
+				shadow.properties[name]++
+				shadow.samples = append(shadow.samples, shadowSample{ID: job.item.ID, Property: name, Current: current[name], Candidate: candidate[name]})
+			}
+			if n := len(shadow.samples); n > maxShadowSamples {
+				shadow.samples = shadow.samples[n-maxShadowSamples:]
+			}
+		}
+		shadow.Unlock()
+		if len(diffs) > 0 {
+			logger.Warn("shadow analysis differs", "id", job.item.ID, "properties", diffs)
+		}
+	}
+}
+
+func shadowReport() map[string]interface{} {
+	shadow.Lock()
+	defer shadow.Unlock()
+	report := map[string]interface{}{"enabled": shadow.overrides != nil}
+	if shadow.overrides == nil {
+		return report
+	}
+	rate := 0.0
+	if shadow.compared > 0 {
+		rate = float64(shadow.differing) / float64(shadow.compared)
+	}
+	report["candidate"] = shadow.overrides
+	report["started_at"] = shadow.startedAt
+	report["compared"] = shadow.compared
+	report["differing"] = shadow.differing
+	report["difference_rate"] = rate
+	report["dropped"] = shadow.dropped
+	report["property_differences"] = maps.Clone(shadow.properties)
+	report["samples"] = append([]shadowSample{}, shadow.samples...)
+	return report
+}
+
+func shadowStatusHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, shadowReport())
+}
+
+func shadowHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	var obj map[string]json.RawMessage
+	if err == nil {
+		err = json.Unmarshal(body, &obj)
+	}
+	if err != nil || obj == nil {
+		writeError(w, http.StatusBadRequest, "INVALID_OPTIONS", "body must be a JSON object of analyzer options")
+		return
+	}
+	if err := setShadowOverrides(body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_OPTIONS", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, shadowReport())
+}
+
+func clearShadowHandler(w http.ResponseWriter, r *http.Request) {
+	_ = setShadowOverrides(nil)
+	writeJSON(w, http.StatusOK, shadowReport())
+}
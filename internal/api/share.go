@@ -0,0 +1,126 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	defaultShareTTL = 24 * time.Hour
+	maxShareTTL     = 7 * 24 * time.Hour
+)
+
+var shareKey []byte
+
+func initShareKey(secret string) {
+	if secret != "" {
+		shareKey = []byte(secret)
+		return
+	}
+	shareKey = make([]byte, 32)
+	if _, err := rand.Read(shareKey); err != nil {
+		panic(err)
+	}
+}
+
+func signShare(payload string) string {
+	mac := hmac.New(sha256.New, shareKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newShareToken encodes "<id>.<unix expiry>" and appends its HMAC, so the
+// token can be checked without any server-side state.
+func newShareToken(id string, expires time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(id + "." + strconv.FormatInt(expires.Unix(), 10)))
+	return payload + "." + signShare(payload)
+}
+
+func parseShareToken(token string) (string, time.Time, bool) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(signShare(payload))) {
+		return "", time.Time{}, false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	id, exp, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return "", time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return id, time.Unix(unix, 0), true
+}
+
+// pathRecordID is the ID of the tenant's value in the collection named by
+// the collection query parameter, or outside any collection without one. It
+// answers 404, as for a missing record, when the ID holds a record of
+// another tenant or collection.
+func pathRecordID(w http.ResponseWriter, r *http.Request, value string) (string, bool) {
+	tenant, collection := tenantOf(r), r.URL.Query().Get("collection")
+	id := recordID(tenant, collection, value)
+	if item, err := backendOf(storeFor(r.Context())).Get(id); err == nil && !recordInScope(item, tenant, collection) {
+		writeStorageError(w, r, store.ErrNotFound)
+		return "", false
+	}
+	return id, true
+}
+
+func shareStringHandler(w http.ResponseWriter, r *http.Request) {
+	value := r.PathValue("value")
+	ttl := defaultShareTTL
+	if v := r.URL.Query().Get("ttl_seconds"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || time.Duration(n)*time.Second > maxShareTTL {
+			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER", "ttl_seconds must be between 1 and 604800")
+			return
+		}
+		ttl = time.Duration(n) * time.Second
+	}
+	id, ok := pathRecordID(w, r, value)
+	if !ok {
+		return
+	}
+	if _, err := storeFor(r.Context()).Get(id); err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	expires := time.Now().Add(ttl)
+	token := newShareToken(id, expires)
+	writeJSON(w, http.StatusCreated, map[string]string{
+		"token":      token,
+		"url":        versionedPath(r, "/shared/"+token),
+		"expires_at": store.FormatTime(expires),
+	})
+}
+
+func sharedRecordHandler(w http.ResponseWriter, r *http.Request) {
+	id, expires, ok := parseShareToken(r.PathValue("token"))
+	if !ok {
+		writeError(w, http.StatusForbidden, "INVALID_SHARE_TOKEN", "invalid share token")
+		return
+	}
+	if time.Now().After(expires) {
+		writeError(w, http.StatusGone, "SHARE_EXPIRED", "share token has expired")
+		return
+	}
+	item, err := storeFor(r.Context()).Get(id)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	setLastModified(w, item)
+	writeJSON(w, http.StatusOK, item)
+}
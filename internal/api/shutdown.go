@@ -0,0 +1,34 @@
+package api
+
+import (
+	"io"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const defaultShutdownTimeout = 10 * time.Second
+
+// flushPersistence is the last step of a shutdown, once requests have
+// drained: it saves the store snapshot, flushes the event and audit logs
+// and closes the storage backend, releasing its connections or file lock.
+func flushPersistence() {
+	if err := store.SaveSnapshot(); err != nil {
+		logger.Error("store snapshot failed", "error", err)
+	}
+	events.Lock()
+	if err := store.SyncLog(&events.f); err != nil {
+		logger.Error("event log flush failed", "error", err)
+	}
+	events.Unlock()
+	audit.Lock()
+	if err := store.SyncLog(&audit.f); err != nil {
+		logger.Error("audit log flush failed", "error", err)
+	}
+	audit.Unlock()
+	if c, ok := backend().(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			logger.Error("closing storage failed", "error", err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSigningSecret = "test-signing-secret"
+
+// signedRequest is a POST carrying the headers withSignature checks.
+func signedRequest(secret string, at time.Time, uri, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, uri, strings.NewReader(body))
+	ts := strconv.FormatInt(at.Unix(), 10)
+	r.Header.Set(signatureTimestampHeader, ts)
+	r.Header.Set(signatureHeader, signRequest([]byte(secret), ts, http.MethodPost, uri, []byte(body)))
+	return r
+}
+
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var env errorEnvelope
+	if err := json.Unmarshal(w.Body.Bytes(), &env); err != nil {
+		t.Fatalf("error body %q: %v", w.Body.String(), err)
+	}
+	return env.Error.Code
+}
+
+func TestWithSignature(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		request  func() *http.Request
+		wantCode string
+	}{
+		{
+			name: "valid",
+			request: func() *http.Request {
+				return signedRequest(testSigningSecret, now, "/strings?limit=5", `{"value":"abba"}`)
+			},
+		},
+		{
+			name:    "empty body",
+			request: func() *http.Request { return signedRequest(testSigningSecret, now, "/strings", "") },
+		},
+		{
+			name: "missing signature",
+			request: func() *http.Request {
+				r := signedRequest(testSigningSecret, now, "/strings", `{"value":"abba"}`)
+				r.Header.Del(signatureHeader)
+				return r
+			},
+			wantCode: "MISSING_SIGNATURE",
+		},
+		{
+			name: "missing timestamp",
+			request: func() *http.Request {
+				r := signedRequest(testSigningSecret, now, "/strings", `{"value":"abba"}`)
+				r.Header.Del(signatureTimestampHeader)
+				return r
+			},
+			wantCode: "MISSING_SIGNATURE",
+		},
+		{
+			name: "invalid timestamp",
+			request: func() *http.Request {
+				r := signedRequest(testSigningSecret, now, "/strings", `{"value":"abba"}`)
+				r.Header.Set(signatureTimestampHeader, "yesterday")
+				return r
+			},
+			wantCode: "INVALID_SIGNATURE",
+		},
+		{
+			name: "too old",
+			request: func() *http.Request {
+				return signedRequest(testSigningSecret, now.Add(-6*time.Minute), "/strings", `{"value":"abba"}`)
+			},
+			wantCode: "SIGNATURE_EXPIRED",
+		},
+		{
+			name: "too far ahead",
+			request: func() *http.Request {
+				return signedRequest(testSigningSecret, now.Add(6*time.Minute), "/strings", `{"value":"abba"}`)
+			},
+			wantCode: "SIGNATURE_EXPIRED",
+		},
+		{
+			name:     "wrong secret",
+			request:  func() *http.Request { return signedRequest("other", now, "/strings", `{"value":"abba"}`) },
+			wantCode: "INVALID_SIGNATURE",
+		},
+		{
+			name: "tampered body",
+			request: func() *http.Request {
+				r := signedRequest(testSigningSecret, now, "/strings", `{"value":"abba"}`)
+				r.Body = io.NopCloser(strings.NewReader(`{"value":"abc"}`))
+				return r
+			},
+			wantCode: "INVALID_SIGNATURE",
+		},
+		{
+			name: "tampered query",
+			request: func() *http.Request {
+				signed := signedRequest(testSigningSecret, now, "/strings?limit=5", "")
+				r := httptest.NewRequest(http.MethodPost, "/strings?limit=500", nil)
+				r.Header = signed.Header
+				return r
+			},
+			wantCode: "INVALID_SIGNATURE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody string
+			h := withSignature(testSigningSecret, 5*time.Minute, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				gotBody = string(data)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			r := tt.request()
+			want, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(strings.NewReader(string(want)))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if tt.wantCode == "" {
+				if w.Code != http.StatusNoContent {
+					t.Fatalf("status = %d, want 204: %s", w.Code, w.Body.String())
+				}
+				if gotBody != string(want) {
+					t.Fatalf("handler read body %q, want %q", gotBody, want)
+				}
+				return
+			}
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", w.Code)
+			}
+			if code := errorCode(t, w); code != tt.wantCode {
+				t.Fatalf("code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestWithSignatureRejectsReplay(t *testing.T) {
+	h := withSignature(testSigningSecret, 5*time.Minute, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	now := time.Now()
+	first := httptest.NewRecorder()
+	h.ServeHTTP(first, signedRequest(testSigningSecret, now, "/strings", `{"value":"abba"}`))
+	if first.Code != http.StatusNoContent {
+		t.Fatalf("first request: status = %d", first.Code)
+	}
+	replay := httptest.NewRecorder()
+	h.ServeHTTP(replay, signedRequest(testSigningSecret, now, "/strings", `{"value":"abba"}`))
+	if replay.Code != http.StatusUnauthorized || errorCode(t, replay) != "REPLAYED_REQUEST" {
+		t.Fatalf("replay: status = %d, body %s", replay.Code, replay.Body.String())
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const maxSnapshots = 20
+
+type corpusSnapshot struct {
+	ID        string          `json:"id"`
+	CreatedAt store.Timestamp `json:"created_at"`
+	Count     int             `json:"count"`
+	values    map[string]string
+}
+
+type snapshotEntry struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+var snapshots = struct {
+	sync.Mutex
+	seq  int
+	list []*corpusSnapshot
+}{}
+
+func currentCorpus() (map[string]string, error) {
+	items, err := db.List()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(items))
+	for _, item := range items {
+		values[item.ID] = item.Value
+	}
+	return values, nil
+}
+
+func takeSnapshot() (*corpusSnapshot, error) {
+	values, err := currentCorpus()
+	if err != nil {
+		return nil, err
+	}
+	snapshots.Lock()
+	defer snapshots.Unlock()
+	snapshots.seq++
+	snap := &corpusSnapshot{
+		ID:        strconv.Itoa(snapshots.seq),
+		CreatedAt: store.Now(),
+		Count:     len(values),
+		values:    values,
+	}
+	snapshots.list = append(snapshots.list, snap)
+	if len(snapshots.list) > maxSnapshots {
+		snapshots.list = snapshots.list[len(snapshots.list)-maxSnapshots:]
+	}
+	return snap, nil
+}
+
+// snapshotValues resolves a snapshot ID, or "current" for the live store.
+func snapshotValues(id string) (map[string]string, bool, error) {
+	if id == "current" {
+		values, err := currentCorpus()
+		return values, true, err
+	}
+	snapshots.Lock()
+	defer snapshots.Unlock()
+	for _, snap := range snapshots.list {
+		if snap.ID == id {
+			return snap.values, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func diffEntries(a, b map[string]string) []snapshotEntry {
+	out := []snapshotEntry{}
+	for id, v := range b {
+		if _, ok := a[id]; !ok {
+			out = append(out, snapshotEntry{ID: id, Value: v})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Value < out[j].Value })
+	return out
+}
+
+func snapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	snapshots.Lock()
+	list := make([]*corpusSnapshot, len(snapshots.list))
+	copy(list, snapshots.list)
+	snapshots.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": list, "count": len(list)})
+}
+
+func takeSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	snap, err := takeSnapshot()
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, snap)
+}
+
+func snapshotDiffHandler(w http.ResponseWriter, r *http.Request) {
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		writeError(w, http.StatusBadRequest, "MISSING_PARAMETER", "from and to parameters are required")
+		return
+	}
+	a, ok, err := snapshotValues(from)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "SNAPSHOT_NOT_FOUND", "snapshot "+from+" does not exist")
+		return
+	}
+	b, ok, err := snapshotValues(to)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "SNAPSHOT_NOT_FOUND", "snapshot "+to+" does not exist")
+		return
+	}
+	added := diffEntries(a, b)
+	removed := diffEntries(b, a)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"from":          from,
+		"to":            to,
+		"added":         added,
+		"removed":       removed,
+		"added_count":   len(added),
+		"removed_count": len(removed),
+	})
+}
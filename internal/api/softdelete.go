@@ -0,0 +1,34 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+)
+
+// restoreStringHandler brings back a string deleted with DELETE
+// /strings/{value}.
+func restoreStringHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathRecordID(w, r, r.PathValue("value"))
+	if !ok {
+		return
+	}
+	item, err := storeFor(r.Context()).(*indexedStorage).restore(id)
+	switch {
+	case errors.Is(err, errNotDeleted):
+		writeError(w, http.StatusConflict, "STRING_NOT_DELETED", err.Error())
+	case errors.Is(err, errQuotaExceeded):
+		writeError(w, http.StatusForbidden, "QUOTA_EXCEEDED", err.Error())
+	case err != nil:
+		writeStorageError(w, r, err)
+	default:
+		writeJSON(w, http.StatusOK, item)
+	}
+}
+
+// hardDeleteStringHandler removes a string for good, live or soft-deleted,
+// for erasure requests that must not leave it restorable.
+func hardDeleteStringHandler(w http.ResponseWriter, r *http.Request) {
+	if id, ok := pathRecordID(w, r, r.PathValue("value")); ok {
+		deleteString(w, r, id, storeFor(r.Context()).(*indexedStorage).hardDelete)
+	}
+}
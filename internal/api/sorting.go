@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+type sortSpec struct {
+	key  string
+	desc bool
+}
+
+var sortKeys = map[string]func(a, b store.StoredString) bool{
+	"created_at": func(a, b store.StoredString) bool {
+		return a.CreatedAt.Before(b.CreatedAt.Time)
+	},
+	"updated_at": func(a, b store.StoredString) bool {
+		return lastModified(a).Before(lastModified(b))
+	},
+	"length": func(a, b store.StoredString) bool {
+		return a.Properties.Length < b.Properties.Length
+	},
+	"word_count": func(a, b store.StoredString) bool {
+		return a.Properties.WordCount < b.Properties.WordCount
+	},
+	"unique_characters": func(a, b store.StoredString) bool {
+		return a.Properties.UniqueCharacters < b.Properties.UniqueCharacters
+	},
+	"value": func(a, b store.StoredString) bool {
+		return a.Value < b.Value
+	},
+}
+
+func lastModified(item store.StoredString) time.Time {
+	if item.UpdatedAt != nil {
+		return item.UpdatedAt.Time
+	}
+	return item.CreatedAt.Time
+}
+
+var sortParams = paramSchema{
+	enumParam("sort_by", "", sortKeyNames()...),
+	enumParam("order", "", "asc", "desc"),
+}
+
+func sortKeyNames() []string {
+	names := make([]string, 0, len(sortKeys))
+	for name := range sortKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseSortSpec reads the sort_by and order parameters of sortParams. It
+// returns nil when no sort was asked for.
+func parseSortSpec(p queryParams) (*sortSpec, error) {
+	key := p.str("sort_by")
+	if key == "" {
+		if p.has("order") {
+			return nil, errors.New("order requires sort_by")
+		}
+		return nil, nil
+	}
+	return &sortSpec{key: key, desc: p.str("order") == "desc"}, nil
+}
+
+// defaultSort orders listings that do not ask for one, so pages are stable.
+var defaultSort = &sortSpec{key: "created_at"}
+
+// less reports whether a sorts before b under the spec, breaking ties by ID
+// so the order is total.
+func (spec *sortSpec) less(a, b store.StoredString) bool {
+	less := sortKeys[spec.key]
+	if spec.desc {
+		a, b = b, a
+	}
+	if less(a, b) {
+		return true
+	}
+	if less(b, a) {
+		return false
+	}
+	return a.ID < b.ID
+}
+
+// sortStrings orders items by the spec deterministically.
+func sortStrings(items []store.StoredString, spec *sortSpec) {
+	sort.Slice(items, func(i, j int) bool { return spec.less(items[i], items[j]) })
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+type timeBucket struct {
+	Count       int
+	Palindromes int
+	TotalLength int
+	WordCounts  map[int]int
+}
+
+var timeseriesIntervals = map[string]time.Duration{
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+}
+
+// timeseriesRetention is how far back the buckets of an interval are kept;
+// day buckets are kept for good.
+var timeseriesRetention = map[string]time.Duration{
+	"minute": 24 * time.Hour,
+	"hour":   90 * 24 * time.Hour,
+}
+
+// seriesKey names the buckets of one interval counted for one tenant.
+type seriesKey struct {
+	tenant, interval string
+}
+
+var (
+	timeseries = struct {
+		sync.Mutex
+		buckets map[seriesKey]map[int64]*timeBucket
+	}{buckets: map[seriesKey]map[int64]*timeBucket{}}
+)
+
+func recordCreation(item store.StoredString, at time.Time) {
+	timeseries.Lock()
+	defer timeseries.Unlock()
+	countCreation(item, at, time.Now())
+}
+
+// rebuildTimeseries recounts the buckets from the created_at of items, so
+// they outlive restarts of persistent backends.
+func rebuildTimeseries(items []store.StoredString) {
+	timeseries.Lock()
+	defer timeseries.Unlock()
+	timeseries.buckets = map[seriesKey]map[int64]*timeBucket{}
+	now := time.Now()
+	for _, item := range items {
+		countCreation(item, item.CreatedAt.Time, now)
+	}
+}
+
+// countCreation adds item to the buckets of at that are within their
+// retention at now. Opening a bucket drops those that have fallen out of
+// it. Callers hold timeseries.Mutex.
+func countCreation(item store.StoredString, at, now time.Time) {
+	for name, d := range timeseriesIntervals {
+		var cutoff int64
+		if keep, ok := timeseriesRetention[name]; ok {
+			cutoff = now.Add(-keep).UTC().Truncate(d).Unix()
+		}
+		start := at.UTC().Truncate(d).Unix()
+		if start < cutoff {
+			continue
+		}
+		key := seriesKey{storedTenant(item), name}
+		byStart, ok := timeseries.buckets[key]
+		if !ok {
+			byStart = map[int64]*timeBucket{}
+			timeseries.buckets[key] = byStart
+		}
+		b, ok := byStart[start]
+		if !ok {
+			for s := range byStart {
+				if s < cutoff {
+					delete(byStart, s)
+				}
+			}
+			b = &timeBucket{WordCounts: map[int]int{}}
+			byStart[start] = b
+		}
+		b.Count++
+		if item.Properties.IsPalindrome {
+			b.Palindromes++
+		}
+		b.TotalLength += item.Properties.Length
+		b.WordCounts[item.Properties.WordCount]++
+	}
+}
+
+func parseTimeParam(v string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, v)
+}
+
+var timeseriesParams = paramSchema{
+	enumParam("metric", "created", "created"),
+	enumParam("interval", "hour", "minute", "hour", "day"),
+	timeParam("from"),
+	timeParam("to"),
+}
+
+func timeseriesHandler(w http.ResponseWriter, r *http.Request) {
+	var errs paramErrors
+	p := timeseriesParams.parse(r.URL.Query(), &errs)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	metric, interval := p.str("metric"), p.str("interval")
+	from, to := p.time("from"), p.time("to")
+	timeseries.Lock()
+	byStart := timeseries.buckets[seriesKey{tenantOf(r), interval}]
+	starts := make([]int64, 0, len(byStart))
+	for start := range byStart {
+		if from != nil && start < from.Unix() {
+			continue
+		}
+		if to != nil && start > to.Unix() {
+			continue
+		}
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	buckets := make([]map[string]interface{}, 0, len(starts))
+	for _, start := range starts {
+		b := byStart[start]
+		histogram := map[string]int{}
+		for wc, n := range b.WordCounts {
+			histogram[strconv.Itoa(wc)] = n
+		}
+		buckets = append(buckets, map[string]interface{}{
+			"start":                store.FormatTime(time.Unix(start, 0)),
+			"count":                b.Count,
+			"palindrome_count":     b.Palindromes,
+			"palindrome_share":     float64(b.Palindromes) / float64(b.Count),
+			"avg_length":           float64(b.TotalLength) / float64(b.Count),
+			"word_count_histogram": histogram,
+		})
+	}
+	timeseries.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"metric":   metric,
+		"interval": interval,
+		"buckets":  buckets,
+	})
+}
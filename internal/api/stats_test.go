@@ -0,0 +1,42 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+func TestRebuildTimeseries(t *testing.T) {
+	t.Cleanup(func() { rebuildTimeseries(nil) })
+	now := time.Now()
+	created := func(v string, ago time.Duration) store.StoredString {
+		return store.StoredString{ID: v, Value: v, Properties: analysis.Analyze(v, analysis.DefaultOptions()), CreatedAt: store.NewTimestamp(now.Add(-ago))}
+	}
+	rebuildTimeseries([]store.StoredString{
+		created("abba", 0),
+		created("kayak", 0),
+		created("hello", 2*24*time.Hour),
+		created("noon", 200*24*time.Hour),
+	})
+	counts := func(interval string) (buckets, total int) {
+		for _, b := range timeseries.buckets[seriesKey{defaultTenant, interval}] {
+			buckets++
+			total += b.Count
+		}
+		return buckets, total
+	}
+	for interval, want := range map[string]int{"minute": 2, "hour": 3, "day": 4} {
+		if _, total := counts(interval); total != want {
+			t.Errorf("%s buckets count %d strings, want %d", interval, total, want)
+		}
+	}
+	timeseries.Lock()
+	later := now.Add(48 * time.Hour)
+	countCreation(created("level", 0), later, later)
+	timeseries.Unlock()
+	if buckets, total := counts("minute"); buckets != 1 || total != 1 {
+		t.Errorf("minute buckets after a day: %d holding %d strings, want only the new one", buckets, total)
+	}
+}
@@ -0,0 +1,409 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+var errNotDeleted = errors.New("string is not deleted")
+
+// indexedStorage wraps a backend and serializes its writes so the in-process
+// indexes are updated in the same order the backend applied them.
+//
+// Update only stores the next version of a record: item.Version must be one
+// more than the stored one, so a write based on a stale read fails with
+// errVersionConflict instead of clobbering the write it missed.
+//
+// Soft-deleted records stay in the backend with deleted_at set. Get, List
+// and Filter hide them and they are dropped from the indexes, so only
+// callers that go to the backend, like listings with include_deleted, see
+// them. Creating a soft-deleted string again replaces it. Records past
+// their expires_at are hidden and replaced the same way until swept.
+type indexedStorage struct {
+	store.Storage
+	mu sync.Mutex
+	// root is the store a view bound to a request context was made from.
+	// Views share its lock.
+	root *indexedStorage
+}
+
+func (s *indexedStorage) lock() {
+	if s.root != nil {
+		s.root.mu.Lock()
+		return
+	}
+	s.mu.Lock()
+}
+
+func (s *indexedStorage) unlock() {
+	if s.root != nil {
+		s.root.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+}
+
+func (s *indexedStorage) WithContext(ctx context.Context) store.Storage {
+	cs, ok := s.Storage.(store.ContextStorage)
+	if !ok {
+		return s
+	}
+	root := s
+	if s.root != nil {
+		root = s.root
+	}
+	return &indexedStorage{Storage: cs.WithContext(ctx), root: root}
+}
+
+func (s *indexedStorage) Get(id string) (store.StoredString, error) {
+	item, err := s.Storage.Get(id)
+	if err != nil {
+		return store.StoredString{}, err
+	}
+	if item.DeletedAt != nil {
+		return store.StoredString{}, store.ErrNotFound
+	}
+	if item.Expired(time.Now()) {
+		return store.StoredString{}, errStringExpired
+	}
+	return item, nil
+}
+
+func (s *indexedStorage) List() ([]store.StoredString, error) {
+	return s.Filter(func(store.StoredString) bool { return true })
+}
+
+func (s *indexedStorage) Filter(match func(store.StoredString) bool) ([]store.StoredString, error) {
+	now := time.Now()
+	return s.Storage.Filter(func(item store.StoredString) bool {
+		return item.DeletedAt == nil && !item.Expired(now) && match(item)
+	})
+}
+
+func (s *indexedStorage) Put(item store.StoredString) error {
+	_, err := s.create(item)
+	return err
+}
+
+// create stores item and returns it as stored. It replaces a soft-deleted
+// or expired record of the same id, continuing from that record's version
+// so versions never repeat in its history.
+func (s *indexedStorage) create(item store.StoredString) (store.StoredString, error) {
+	s.lock()
+	defer s.unlock()
+	if existing, err := s.Storage.Get(item.ID); err == nil {
+		if existing.DeletedAt == nil && !existing.Expired(time.Now()) {
+			return store.StoredString{}, store.ErrExists
+		}
+		if _, err := s.remove(item.ID); err != nil {
+			return store.StoredString{}, err
+		}
+		item.Version = existing.Version + 1
+	}
+	if quotas.storage > 0 {
+		if err := checkStorageQuota(item); err != nil {
+			return store.StoredString{}, err
+		}
+	}
+	if err := s.Storage.Put(item); err != nil {
+		return store.StoredString{}, err
+	}
+	indexCreated(item)
+	recordEvent("create", item.Tenant, &item, item.ID)
+	return item, nil
+}
+
+func (s *indexedStorage) Update(item store.StoredString) error {
+	s.lock()
+	defer s.unlock()
+	current, err := s.Get(item.ID)
+	if err != nil {
+		return err
+	}
+	if item.Version != current.Version+1 {
+		return errVersionConflict
+	}
+	if err := s.Storage.Update(item); err != nil {
+		return err
+	}
+	indexUpdated(item)
+	recordEvent("update", item.Tenant, &item, item.ID)
+	return nil
+}
+
+// Delete removes a record for good, whether or not it was soft-deleted.
+func (s *indexedStorage) Delete(id string) (store.StoredString, error) {
+	s.lock()
+	defer s.unlock()
+	return s.remove(id)
+}
+
+func (s *indexedStorage) remove(id string) (store.StoredString, error) {
+	item, err := s.Storage.Delete(id)
+	if err != nil {
+		return store.StoredString{}, err
+	}
+	if item.DeletedAt != nil {
+		storeVersion.Add(1)
+		return item, nil
+	}
+	indexDeleted(item)
+	recordEvent("delete", item.Tenant, nil, id)
+	return item, nil
+}
+
+// hardDelete is Delete for a live record satisfying pre.
+func (s *indexedStorage) hardDelete(id string, pre *versionPrecondition) (store.StoredString, error) {
+	s.lock()
+	defer s.unlock()
+	if pre != nil {
+		item, err := s.Get(id)
+		if err != nil {
+			return store.StoredString{}, err
+		}
+		if err := pre.check(item); err != nil {
+			return store.StoredString{}, err
+		}
+	}
+	return s.remove(id)
+}
+
+// softDelete marks a live record satisfying pre deleted at the current time.
+func (s *indexedStorage) softDelete(id string, pre *versionPrecondition) (store.StoredString, error) {
+	s.lock()
+	defer s.unlock()
+	item, err := s.Get(id)
+	if err != nil {
+		return store.StoredString{}, err
+	}
+	if err := pre.check(item); err != nil {
+		return store.StoredString{}, err
+	}
+	now := store.Now()
+	item.DeletedAt = &now
+	item.Version++
+	if err := s.Storage.Update(item); err != nil {
+		return store.StoredString{}, err
+	}
+	indexDeleted(item)
+	recordEvent("delete", item.Tenant, nil, id)
+	return item, nil
+}
+
+// restore brings a soft-deleted record back as a restore event.
+func (s *indexedStorage) restore(id string) (store.StoredString, error) {
+	s.lock()
+	defer s.unlock()
+	item, err := s.Storage.Get(id)
+	if err != nil {
+		return store.StoredString{}, err
+	}
+	if item.DeletedAt == nil {
+		return store.StoredString{}, errNotDeleted
+	}
+	if item.Expired(time.Now()) {
+		return store.StoredString{}, errStringExpired
+	}
+	item.DeletedAt = nil
+	item.Version++
+	if err := checkStorageQuota(item); err != nil {
+		return store.StoredString{}, err
+	}
+	if err := s.Storage.Update(item); err != nil {
+		return store.StoredString{}, err
+	}
+	indexCreated(item)
+	recordEvent("restore", item.Tenant, &item, id)
+	return item, nil
+}
+
+func (s *indexedStorage) Reset() error {
+	s.lock()
+	defer s.unlock()
+	if err := s.Storage.Reset(); err != nil {
+		return err
+	}
+	indexReset()
+	recordEvent("reset", "", nil, "")
+	return nil
+}
+
+var db store.Storage = &indexedStorage{Storage: store.NewMemory()}
+
+// storeFor returns store with its operations bound to ctx when the backend
+// supports that.
+func storeFor(ctx context.Context) store.Storage {
+	if cs, ok := db.(store.ContextStorage); ok {
+		return cs.WithContext(ctx)
+	}
+	return db
+}
+
+// OpenStorage opens the backend cfg names.
+func OpenStorage(cfg Config) (store.Storage, error) {
+	switch backend := cfg.Storage; backend {
+	case "", "memory":
+		return store.OpenMemory(cfg.SnapshotPath, cfg.WALPath)
+	case "postgres":
+		url := os.Getenv("DATABASE_URL")
+		if url == "" {
+			return nil, errors.New("DATABASE_URL is required for the postgres backend")
+		}
+		return store.OpenPostgres(url)
+	case "bolt":
+		return store.OpenBolt(cfg.BoltPath)
+	case "redis":
+		opts, err := redisOptions()
+		if err != nil {
+			return nil, err
+		}
+		return store.OpenRedis(opts)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// redisOptions reads REDIS_URL, REDIS_POOL_SIZE, REDIS_KEY_PREFIX and
+// REDIS_TTL_SECONDS.
+func redisOptions() (store.RedisOptions, error) {
+	opts := store.RedisOptions{URL: os.Getenv("REDIS_URL"), Prefix: store.DefaultRedisPrefix}
+	if opts.URL == "" {
+		return opts, errors.New("REDIS_URL is required for the redis backend")
+	}
+	if v := os.Getenv("REDIS_POOL_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return opts, errors.New("invalid REDIS_POOL_SIZE")
+		}
+		opts.PoolSize = n
+	}
+	if v, ok := os.LookupEnv("REDIS_KEY_PREFIX"); ok {
+		opts.Prefix = v
+	}
+	if v := os.Getenv("REDIS_TTL_SECONDS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return opts, errors.New("invalid REDIS_TTL_SECONDS")
+		}
+		opts.TTL = time.Duration(n) * time.Second
+	}
+	return opts, nil
+}
+
+// installStorage serves s and rebuilds the in-process indexes from its
+// existing records.
+func installStorage(s store.Storage) error {
+	switch s := s.(type) {
+	case *store.Postgres:
+		storageName = "postgres"
+		s.Outbox = os.Getenv("WEBHOOK_URL") != ""
+		s.OnCommit = notifyWebhooks
+	case *store.Bolt:
+		storageName = "bolt"
+	case *store.Redis:
+		storageName = "redis"
+		// Other instances write to the same store without bumping this
+		// process's storeVersion, and records may expire, so cached
+		// listings could go stale.
+		listCache.disabled = true
+	}
+	db = &indexedStorage{Storage: s}
+	if err := loadCollections(s); err != nil {
+		return err
+	}
+	if err := rebuildAllIndexes(); err != nil {
+		return err
+	}
+	all, err := s.List()
+	if err != nil {
+		return err
+	}
+	rebuildTimeseries(all)
+	items, err := db.List()
+	if err != nil {
+		return err
+	}
+	recountTenantUsage(items)
+	return nil
+}
+
+// filterRecords returns the records matching fs, pushing the filters down
+// to the backend when it supports that. Soft-deleted records are included
+// only when includeDeleted is set.
+func filterRecords(ctx context.Context, fs filterSet, includeDeleted bool) ([]store.StoredString, error) {
+	s := storeFor(ctx)
+	if p, ok := backendOf(s).(store.Pushdown); ok {
+		results, err := p.FilterQuery(fs.query())
+		if err != nil || includeDeleted {
+			return results, err
+		}
+		return liveRecords(results), nil
+	}
+	if includeDeleted {
+		return backendOf(s).Filter(fs.matches)
+	}
+	return s.Filter(fs.matches)
+}
+
+func liveRecords(items []store.StoredString) []store.StoredString {
+	now := time.Now()
+	live := items[:0]
+	for _, item := range items {
+		if item.DeletedAt == nil && !item.Expired(now) {
+			live = append(live, item)
+		}
+	}
+	return live
+}
+
+// backend is the Storage behind the indexedStorage wrapper, which still
+// holds soft-deleted records.
+func backend() store.Storage {
+	return backendOf(db)
+}
+
+func backendOf(s store.Storage) store.Storage {
+	if is, ok := s.(*indexedStorage); ok {
+		return is.Storage
+	}
+	return s
+}
+
+func backendPushdown() (store.Pushdown, bool) {
+	p, ok := backend().(store.Pushdown)
+	return p, ok
+}
+
+func writeStorageError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errPreconditionFailed) {
+		writeError(w, http.StatusPreconditionFailed, "PRECONDITION_FAILED", err.Error())
+		return
+	}
+	if errors.Is(err, errVersionConflict) {
+		writeError(w, http.StatusConflict, "VERSION_CONFLICT", err.Error())
+		return
+	}
+	if errors.Is(err, errStringExpired) {
+		writeError(w, http.StatusGone, "STRING_EXPIRED", err.Error())
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "STRING_NOT_FOUND", err.Error())
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		requestLogger(r).Warn("storage operation abandoned", "error", err)
+		writeError(w, http.StatusServiceUnavailable, "TIMEOUT", "request timed out")
+		return
+	}
+	requestLogger(r).Error("storage error", "error", err)
+	writeError(w, http.StatusInternalServerError, "STORAGE_ERROR", "storage error")
+}
@@ -0,0 +1,734 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type CreateReq struct {
+	Value           interface{} `json:"value"`
+	CaseInsensitive *bool       `json:"case_insensitive"`
+	Collection      string      `json:"collection"`
+	Language        string      `json:"language"`
+	TTLSeconds      *int        `json:"ttl_seconds"`
+	Tags            []string    `json:"tags"`
+}
+
+// insertString analyzes and stores val, failing if it already exists or the
+// store has reached store.MaxRecords. opts must already be normalized. A non-zero
+// ttl makes the record expire.
+func insertString(val, collection, tenant string, opts analysis.Options, ttl time.Duration, tags []string) (store.StoredString, error) {
+	item, err := insertAnalyzed(val, collection, tenant, analysis.Analyze(val, opts), ttl, tags)
+	if err == nil {
+		shadowAnalyze(item, opts)
+	}
+	return item, err
+}
+
+func insertAnalyzed(val, collection, tenant string, props analysis.Properties, ttl time.Duration, tags []string) (store.StoredString, error) {
+	id := props.SHA256Hash
+	if collection != "" || tenant != defaultTenant {
+		id = recordID(tenant, collection, val)
+	}
+	now := time.Now()
+	item := store.StoredString{
+		ID:         id,
+		Value:      val,
+		Properties: props,
+		CreatedAt:  store.NewTimestamp(now),
+		Collection: collection,
+		Tags:       tags,
+		Version:    1,
+	}
+	if tenant != defaultTenant {
+		item.Tenant = tenant
+	}
+	if ttl > 0 {
+		expires := store.NewTimestamp(now.Add(ttl))
+		item.ExpiresAt = &expires
+	}
+	if enrichmentEnabled() {
+		item.Enrichment = &store.Enrichment{Status: enrichmentPending, UpdatedAt: store.NewTimestamp(now)}
+	}
+	item, err := db.(*indexedStorage).create(item)
+	if err != nil {
+		return store.StoredString{}, err
+	}
+	recordCreation(item, now)
+	if item.Enrichment != nil {
+		queueEnrichment(item)
+	}
+	return item, nil
+}
+
+// reanalyzeString recomputes the properties of an existing record with opts
+// and stamps updated_at, if the record satisfies pre.
+func reanalyzeString(id string, opts analysis.Options, pre *versionPrecondition) (store.StoredString, error) {
+	return updateRecord(id, pre, func(item *store.StoredString) error {
+		item.Properties = analysis.Analyze(item.Value, opts)
+		now := store.Now()
+		item.UpdatedAt = &now
+		return nil
+	})
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", jsonEncoder.contentType)
+	w.WriteHeader(code)
+	_ = jsonEncoder.encode(w, v)
+}
+
+func validateCreateBody(body CreateReq) (string, int, error) {
+	if body.Value == nil {
+		return "", http.StatusBadRequest, &valueRuleError{"MISSING_VALUE", `missing "value" field`}
+	}
+	switch v := body.Value.(type) {
+	case string:
+		if err := checkValueRules(v); err != nil {
+			return "", http.StatusUnprocessableEntity, err
+		}
+		return v, 0, nil
+	default:
+		return "", http.StatusUnprocessableEntity, &valueRuleError{"VALUE_NOT_STRING", `"value" must be a string`}
+	}
+}
+
+func postStringsHandler(w http.ResponseWriter, r *http.Request) {
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "text/plain" {
+		postPlainStringHandler(w, r)
+		return
+	}
+	var body CreateReq
+	data, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.NewDecoder(bytes.NewReader(data)).Decode(&body)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	// The decoder replaces invalid UTF-8 with U+FFFD, so the rule is
+	// checked against the raw body.
+	if settings().RejectInvalidUTF8 && !utf8.Valid(data) {
+		writeValidationError(w, http.StatusUnprocessableEntity, errValueInvalidUTF8)
+		return
+	}
+	val, code, err := validateCreateBody(body)
+	if err != nil {
+		writeValidationError(w, code, err)
+		return
+	}
+	collection, opts, err := createOptions(r, body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_OPTIONS", err.Error())
+		return
+	}
+	ttl, err := parseTTL(body.TTLSeconds)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TTL", err.Error())
+		return
+	}
+	tags, err := normalizeTags(body.Tags)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TAGS", err.Error())
+		return
+	}
+	end := traceStore(r, "Insert")
+	item, err := insertString(val, collection, tenantOf(r), opts, ttl, tags)
+	end(err)
+	writeInsertResult(w, r, item, err)
+}
+
+// createOptions resolves the collection and analysis options of a create
+// request: body fields win over the query string, except that the
+// collection must agree.
+func createOptions(r *http.Request, body CreateReq) (string, analysis.Options, error) {
+	collection := r.URL.Query().Get("collection")
+	if body.Collection != "" {
+		if collection != "" && collection != body.Collection {
+			return "", analysis.Options{}, errors.New(`"collection" does not match the collection of the request`)
+		}
+		collection = body.Collection
+	}
+	opts, err := requestAnalysisOptions(r, collection)
+	if err != nil {
+		return "", analysis.Options{}, err
+	}
+	if body.CaseInsensitive != nil {
+		opts.CaseInsensitive = *body.CaseInsensitive
+	}
+	if body.Language != "" {
+		if !slices.Contains(analysis.PalindromeLanguages, body.Language) {
+			return "", analysis.Options{}, fmt.Errorf("invalid language %q", body.Language)
+		}
+		opts.Language = body.Language
+	}
+	return collection, opts, nil
+}
+
+// postPlainStringHandler stores a raw text/plain body, analyzing it as it is
+// read so very large values are not decoded from JSON first.
+func postPlainStringHandler(w http.ResponseWriter, r *http.Request) {
+	collection := r.URL.Query().Get("collection")
+	opts, err := requestAnalysisOptions(r, collection)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_OPTIONS", err.Error())
+		return
+	}
+	ttl, err := queryTTL(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TTL", err.Error())
+		return
+	}
+	tags, err := queryTags(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TAGS", err.Error())
+		return
+	}
+	val, props, err := analysis.AnalyzeReader(r.Body, opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", "failed to read request body")
+		return
+	}
+	storeStreamed(w, r, collection, val, props, opts, ttl, tags)
+}
+
+// uploadStringHandler stores the text file sent as the "file" field of a
+// multipart/form-data body, analyzing it as it is read.
+func uploadStringHandler(w http.ResponseWriter, r *http.Request) {
+	collection := r.URL.Query().Get("collection")
+	opts, err := requestAnalysisOptions(r, collection)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_OPTIONS", err.Error())
+		return
+	}
+	ttl, err := queryTTL(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TTL", err.Error())
+		return
+	}
+	tags, err := queryTags(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TAGS", err.Error())
+		return
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "body must be multipart/form-data")
+		return
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			writeError(w, http.StatusBadRequest, "MISSING_FILE", `missing "file" field`)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_BODY", "failed to read request body")
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		val, props, err := analysis.AnalyzeReader(part, opts)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_BODY", "failed to read request body")
+			return
+		}
+		storeStreamed(w, r, collection, val, props, opts, ttl, tags)
+		return
+	}
+}
+
+// storeStreamed stores a value analyzed by analysis.AnalyzeReader, rejecting empty
+// and invalid UTF-8 values.
+func storeStreamed(w http.ResponseWriter, r *http.Request, collection, val string, props analysis.Properties, opts analysis.Options, ttl time.Duration, tags []string) {
+	if val == "" {
+		writeError(w, http.StatusBadRequest, "MISSING_VALUE", "missing string value in body")
+		return
+	}
+	if !utf8.ValidString(val) {
+		writeError(w, http.StatusBadRequest, "INVALID_UTF8", "body must be valid UTF-8")
+		return
+	}
+	if err := checkValueRules(val); err != nil {
+		writeValidationError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	item, err := insertAnalyzed(val, collection, tenantOf(r), props, ttl, tags)
+	if err == nil {
+		shadowAnalyze(item, opts)
+	}
+	writeInsertResult(w, r, item, err)
+}
+
+func requestAnalysisOptions(r *http.Request, collection string) (analysis.Options, error) {
+	if collection != "" && !collectionNamePattern.MatchString(collection) {
+		return analysis.Options{}, errors.New("invalid collection name")
+	}
+	opts := collectionOptions(tenantOf(r), collection)
+	if v := r.URL.Query().Get("case_insensitive"); v != "" {
+		b, err := parseBoolParam(strings.ToLower(v))
+		if err != nil {
+			return analysis.Options{}, errors.New("invalid case_insensitive value")
+		}
+		opts.CaseInsensitive = b
+	}
+	if v := r.URL.Query().Get("language"); v != "" {
+		opts.Language = v
+	}
+	if opts.Language != "" && !slices.Contains(analysis.PalindromeLanguages, opts.Language) {
+		return analysis.Options{}, fmt.Errorf("invalid language %q", opts.Language)
+	}
+	return opts, nil
+}
+
+func writeInsertResult(w http.ResponseWriter, r *http.Request, item store.StoredString, err error) {
+	if errors.Is(err, errQuotaExceeded) {
+		writeError(w, http.StatusForbidden, "QUOTA_EXCEEDED", err.Error())
+		return
+	}
+	if errors.Is(err, store.ErrExists) {
+		writeError(w, http.StatusConflict, "STRING_EXISTS", err.Error())
+		return
+	}
+	if errors.Is(err, store.ErrFull) {
+		writeError(w, http.StatusInsufficientStorage, "STORE_FULL", err.Error())
+		return
+	}
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	used, _ := requestsUsed(tenantOf(r), time.Now())
+	setStorageQuotaHeaders(w, tenantOf(r), used)
+	writeJSON(w, http.StatusCreated, item)
+}
+
+var recordParams = paramSchema{boolParam("verify", false)}
+
+func getStringByValueHandler(w http.ResponseWriter, r *http.Request) {
+	if id, ok := pathRecordID(w, r, r.PathValue("value")); ok {
+		writeStoredRecord(w, r, id)
+	}
+}
+
+// writeStoredRecord answers a single-record lookup, honoring verify and
+// fields.
+func writeStoredRecord(w http.ResponseWriter, r *http.Request, id string) {
+	var errs paramErrors
+	verify := recordParams.parse(r.URL.Query(), &errs).bool("verify")
+	fields, err := parseFields(r.URL.Query())
+	errs.add("fields", err)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	end := traceStore(r, "Get", attribute.String("record.id", id))
+	item, err := storeFor(r.Context()).Get(id)
+	end(err)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	setLastModified(w, item)
+	if verify {
+		if fields != nil {
+			fields = append(fields, []string{"integrity"})
+		}
+		writeFields(w, http.StatusOK, struct {
+			store.StoredString
+			Integrity integrityResult `json:"integrity"`
+		}{item, verifyRecord(item)}, fields)
+		return
+	}
+	writeFields(w, http.StatusOK, item, fields)
+}
+
+func parseBoolParam(v string) (bool, error) {
+	if v == "true" {
+		return true, nil
+	}
+	if v == "false" {
+		return false, nil
+	}
+	return false, errors.New("invalid boolean")
+}
+
+// listingParams are the query parameters of GET /strings besides its
+// filters and fields.
+var listingParams = append(append(paramSchema{boolParam("explain", false), boolParam("include_deleted", false)}, sortParams...), pageParams...)
+
+func getAllStringsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var errs paramErrors
+	p := listingParams.parse(q, &errs)
+	filters, err := parseFilterSet(q)
+	errs.add("", err)
+	filters.tenant = tenantOf(r)
+	sortBy, err := parseSortSpec(p)
+	errs.add("order", err)
+	if sortBy == nil {
+		sortBy = defaultSort
+	}
+	fields, err := parseFields(q)
+	errs.add("fields", err)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	includeDeleted := p.bool("include_deleted")
+	key := listingCacheKey(filters, sortBy, includeDeleted)
+	page, err := parsePageRequest(p, key)
+	if err != nil {
+		writeParamErrors(w, paramErrors{{Parameter: "cursor", Message: err.Error()}})
+		return
+	}
+	explain := p.bool("explain")
+	start := time.Now()
+	var ex *queryExplain
+	version := storeVersion.Load()
+	// Explained listings always run the query, so the plan they report does
+	// not depend on what is cached.
+	var results []store.StoredString
+	hit := false
+	if !explain {
+		results, hit = listCache.get(key)
+	}
+	if hit {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		if explain {
+			w.Header().Set("X-Cache", "BYPASS")
+			results, ex, err = explainFilterRecords(filters, includeDeleted)
+		} else {
+			w.Header().Set("X-Cache", "MISS")
+			end := traceStore(r, "Filter")
+			results, err = filterRecords(r.Context(), filters, includeDeleted)
+			end(err)
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+		sortStrings(results, sortBy)
+		listCache.put(key, version, results)
+	}
+	resp := map[string]interface{}{
+		"filters_applied": filters.applied(),
+	}
+	if page != nil {
+		var next string
+		resp["total"] = len(results)
+		results, next = paginate(results, sortBy, page, key)
+		resp["next_cursor"] = nil
+		if next != "" {
+			resp["next_cursor"] = next
+		}
+	}
+	resp["count"] = len(results)
+	if ex != nil {
+		ex.DurationMs = millisSince(start)
+		resp["explain"] = ex
+	}
+	writeRecordListing(w, http.StatusOK, results, fields, version, resp)
+}
+
+func parseNaturalLanguage(query string) (map[string]interface{}, error) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return nil, errors.New("empty query")
+	}
+	parsed := map[string]interface{}{}
+	if strings.Contains(q, "single word") || strings.Contains(q, "single-word") || strings.Contains(q, "one word") {
+		parsed["word_count"] = 1
+	}
+	if strings.Contains(q, "palindrom") {
+		parsed["is_palindrome"] = true
+	}
+	reLonger := regexp.MustCompile(`longer than\s+(\d+)`)
+	if m := reLonger.FindStringSubmatch(q); len(m) == 2 {
+		n, err := strconv.Atoi(m[1])
+		if err == nil {
+			parsed["min_length"] = n + 1
+		}
+	}
+	reLonger2 := regexp.MustCompile(`longer than\s+(\d+)\s+characters`)
+	if m := reLonger2.FindStringSubmatch(q); len(m) == 2 {
+		n, err := strconv.Atoi(m[1])
+		if err == nil {
+			parsed["min_length"] = n + 1
+		}
+	}
+	reContains := regexp.MustCompile(`containing the letter\s+([a-zA-Z])|contain the letter\s+([a-zA-Z])|containing\s+([a-zA-Z])|contain\s+([a-zA-Z])`)
+	if m := reContains.FindStringSubmatch(q); len(m) >= 5 {
+		for i := 1; i <= 4; i++ {
+			if m[i] != "" {
+				parsed["contains_character"] = strings.ToLower(m[i])
+				break
+			}
+		}
+	}
+	if strings.Contains(q, "first vowel") || strings.Contains(q, "first vowel a") {
+		parsed["contains_character"] = "a"
+	}
+	if _, ok := parsed["word_count"]; !ok {
+		reWords := regexp.MustCompile(`\b(\d+)\s+word`)
+		if m := reWords.FindStringSubmatch(q); len(m) == 2 {
+			n, err := strconv.Atoi(m[1])
+			if err == nil {
+				parsed["word_count"] = n
+			}
+		}
+	}
+	if len(parsed) == 0 {
+		return nil, errors.New("unable to parse natural language query")
+	}
+	if min, ok1 := parsed["min_length"].(int); ok1 {
+		if max, ok2 := parsed["max_length"].(int); ok2 && min > max {
+			return nil, errors.New("conflicting filters")
+		}
+		if maxf, ok3 := parsed["max_length"].(float64); ok3 && min > int(maxf) {
+			return nil, errors.New("conflicting filters")
+		}
+	}
+	return parsed, nil
+}
+
+func applyParsedFilters(ctx context.Context, tenant string, parsed map[string]interface{}) ([]store.StoredString, error) {
+	return storeFor(ctx).Filter(parsedFilterMatcher(tenant, parsed))
+}
+
+func parsedFilterMatcher(tenant string, parsed map[string]interface{}) func(store.StoredString) bool {
+	return func(item store.StoredString) bool {
+		if storedTenant(item) != tenant {
+			return false
+		}
+		ok := true
+		if v, okp := parsed["is_palindrome"]; okp {
+			if b, ok2 := v.(bool); ok2 {
+				if item.Properties.IsPalindrome != b {
+					ok = false
+				}
+			}
+		}
+		if v, okp := parsed["word_count"]; okp {
+			switch vv := v.(type) {
+			case int:
+				if item.Properties.WordCount != vv {
+					ok = false
+				}
+			case float64:
+				if item.Properties.WordCount != int(vv) {
+					ok = false
+				}
+			}
+		}
+		if v, okp := parsed["min_length"]; okp {
+			switch vv := v.(type) {
+			case int:
+				if item.Properties.Length < vv {
+					ok = false
+				}
+			case float64:
+				if item.Properties.Length < int(vv) {
+					ok = false
+				}
+			}
+		}
+		if v, okp := parsed["max_length"]; okp {
+			switch vv := v.(type) {
+			case int:
+				if item.Properties.Length > vv {
+					ok = false
+				}
+			case float64:
+				if item.Properties.Length > int(vv) {
+					ok = false
+				}
+			}
+		}
+		if v, okp := parsed["contains_character"]; okp {
+			chStr := fmt.Sprintf("%v", v)
+			if chStr == "" {
+				ok = false
+			} else {
+				found := false
+				for ch := range item.Properties.CharacterFrequencyMap {
+					if strings.EqualFold(ch, chStr) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					ok = false
+				}
+			}
+		}
+		return ok
+	}
+}
+
+var naturalLanguageParams = paramSchema{requiredParam("query"), boolParam("explain", false)}
+
+func naturalLanguageHandler(w http.ResponseWriter, r *http.Request) {
+	var errs paramErrors
+	p := naturalLanguageParams.parse(r.URL.Query(), &errs)
+	q, explain := p.str("query"), p.bool("explain")
+	var parsed map[string]interface{}
+	if q != "" {
+		var err error
+		parsed, err = parseNaturalLanguage(q)
+		errs.add("query", err)
+	}
+	fields, err := parseFields(r.URL.Query())
+	errs.add("fields", err)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	var results []store.StoredString
+	var ex *queryExplain
+	version := storeVersion.Load()
+	if explain {
+		start := time.Now()
+		ex = newQueryExplain("full_scan")
+		t := ex.timing("interpreted_query")
+		match := parsedFilterMatcher(tenantOf(r), parsed)
+		results, err = storeFor(r.Context()).Filter(func(item store.StoredString) bool {
+			ex.Scanned++
+			return t.eval(func() bool { return match(item) })
+		})
+		ex.Matched = len(results)
+		ex.DurationMs = millisSince(start)
+	} else {
+		end := traceStore(r, "Filter")
+		results, err = applyParsedFilters(r.Context(), tenantOf(r), parsed)
+		end(err)
+	}
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	resp := map[string]interface{}{
+		"count": len(results),
+		"interpreted_query": map[string]interface{}{
+			"original":       q,
+			"parsed_filters": parsed,
+		},
+	}
+	if ex != nil {
+		resp["explain"] = ex
+	}
+	writeRecordListing(w, http.StatusOK, results, fields, version, resp)
+}
+
+func deleteStringHandler(w http.ResponseWriter, r *http.Request) {
+	if id, ok := pathRecordID(w, r, r.PathValue("value")); ok {
+		deleteString(w, r, id, storeFor(r.Context()).(*indexedStorage).softDelete)
+	}
+}
+
+// deleteString deletes the record id with remove, honoring If-Match and
+// X-Idempotent-Delete.
+func deleteString(w http.ResponseWriter, r *http.Request, id string, remove func(id string, pre *versionPrecondition) (store.StoredString, error)) {
+	pre, err := parseIfMatch(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PRECONDITION", err.Error())
+		return
+	}
+	idempotent := settings().IdempotentDeletes
+	if v := r.Header.Get("X-Idempotent-Delete"); v != "" {
+		if idempotent, err = parseBoolParam(strings.ToLower(v)); err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_HEADER", "invalid X-Idempotent-Delete header, expected true or false")
+			return
+		}
+	}
+	end := traceStore(r, "Delete", attribute.String("record.id", id))
+	_, err = remove(id, pre)
+	end(err)
+	if err != nil && !(idempotent && errors.Is(err, store.ErrNotFound)) {
+		writeStorageError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// putStringHandler stores the string in the path if it is missing and
+// re-analyzes it if it exists, answering 201 or 200, so pipelines can repeat
+// it safely. An existing record keeps its collection. With If-Match only an
+// existing record of a listed version is re-analyzed.
+func putStringHandler(w http.ResponseWriter, r *http.Request) {
+	value := r.PathValue("value")
+	if err := checkValueRules(value); err != nil {
+		writeValidationError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	pre, err := parseIfMatch(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PRECONDITION", err.Error())
+		return
+	}
+	collection := r.URL.Query().Get("collection")
+	id := recordID(tenantOf(r), collection, value)
+	end := traceStore(r, "Get", attribute.String("record.id", id))
+	_, err = storeFor(r.Context()).Get(id)
+	end(err)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		writeStorageError(w, r, err)
+		return
+	}
+	found := err == nil
+	if !found && pre != nil {
+		writeStorageError(w, r, errPreconditionFailed)
+		return
+	}
+	opts, err := requestAnalysisOptions(r, collection)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_OPTIONS", err.Error())
+		return
+	}
+	if !found {
+		end := traceStore(r, "Insert", attribute.String("record.id", id))
+		item, err := insertString(value, collection, tenantOf(r), opts, 0, nil)
+		end(err)
+		if !errors.Is(err, store.ErrExists) {
+			writeInsertResult(w, r, item, err)
+			return
+		}
+	}
+	end = traceStore(r, "Reanalyze", attribute.String("record.id", id))
+	item, err := reanalyzeString(id, opts, pre)
+	end(err)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
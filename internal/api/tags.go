@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const maxTags = 20
+
+var (
+	tagPattern     = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,63}$`)
+	errTooManyTags = fmt.Errorf("a string can have at most %d tags", maxTags)
+)
+
+// normalizeTags lowercases tags and returns them sorted without duplicates,
+// so a record's tags compare equal however they were sent.
+func normalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !tagPattern.MatchString(t) {
+			return nil, fmt.Errorf("invalid tag %q, expected up to 64 letters, digits, _ . : or -", t)
+		}
+		out = append(out, t)
+	}
+	slices.Sort(out)
+	out = slices.Compact(out)
+	if len(out) > maxTags {
+		return nil, errTooManyTags
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// queryTags reads comma-separated tags from the query string of raw and
+// multipart uploads, which have no JSON body to carry them.
+func queryTags(r *http.Request) ([]string, error) {
+	v := r.URL.Query().Get("tags")
+	if v == "" {
+		return nil, nil
+	}
+	return normalizeTags(strings.Split(v, ","))
+}
+
+type tagsReq struct {
+	Tags   *[]string `json:"tags"`
+	Add    []string  `json:"add"`
+	Remove []string  `json:"remove"`
+}
+
+// tagsHandler replaces a string's tags with "tags", or adds and removes the
+// listed ones, without re-analyzing it. If-Match restricts it to the listed
+// record versions.
+func tagsHandler(w http.ResponseWriter, r *http.Request) {
+	value := r.PathValue("value")
+	pre, err := parseIfMatch(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PRECONDITION", err.Error())
+		return
+	}
+	var body tagsReq
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if body.Tags == nil && len(body.Add) == 0 && len(body.Remove) == 0 {
+		writeError(w, http.StatusBadRequest, "MISSING_TAGS", `expected "tags", "add" or "remove"`)
+		return
+	}
+	if body.Tags != nil && (len(body.Add) > 0 || len(body.Remove) > 0) {
+		writeError(w, http.StatusBadRequest, "CONFLICTING_TAGS", `"tags" cannot be combined with "add" or "remove"`)
+		return
+	}
+	add, err := normalizeTags(body.Add)
+	if err == nil && body.Tags != nil {
+		add, err = normalizeTags(*body.Tags)
+	}
+	var remove []string
+	if err == nil {
+		remove, err = normalizeTags(body.Remove)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TAGS", err.Error())
+		return
+	}
+	id, ok := pathRecordID(w, r, value)
+	if !ok {
+		return
+	}
+	item, err := updateRecord(id, pre, func(item *store.StoredString) error {
+		tags := add
+		if body.Tags == nil {
+			tags = slices.DeleteFunc(append(slices.Clone(item.Tags), add...), func(t string) bool { return slices.Contains(remove, t) })
+		}
+		merged, err := normalizeTags(tags)
+		if err != nil {
+			return err
+		}
+		item.Tags = merged
+		return nil
+	})
+	if errors.Is(err, errTooManyTags) {
+		writeError(w, http.StatusBadRequest, "TOO_MANY_TAGS", err.Error())
+		return
+	}
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
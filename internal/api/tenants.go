@@ -0,0 +1,297 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+// tenantShards holds a separate instance of an index for each tenant, so
+// queries only ever see the tenant's own strings.
+type tenantShards[T any] struct {
+	sync.Mutex
+	m        map[string]T
+	newShard func() T
+}
+
+func newTenantShards[T any](newShard func() T) *tenantShards[T] {
+	return &tenantShards[T]{m: map[string]T{}, newShard: newShard}
+}
+
+// of returns the tenant's shard, creating it for writes.
+func (s *tenantShards[T]) of(tenant string) T {
+	s.Lock()
+	defer s.Unlock()
+	shard, ok := s.m[tenant]
+	if !ok {
+		shard = s.newShard()
+		s.m[tenant] = shard
+	}
+	return shard
+}
+
+// get returns the tenant's shard, or an empty one that is not kept, so
+// reads for unknown tenants do not grow the index.
+func (s *tenantShards[T]) get(tenant string) T {
+	s.Lock()
+	defer s.Unlock()
+	if shard, ok := s.m[tenant]; ok {
+		return shard
+	}
+	return s.newShard()
+}
+
+func (s *tenantShards[T]) all() []T {
+	s.Lock()
+	defer s.Unlock()
+	out := make([]T, 0, len(s.m))
+	for _, shard := range s.m {
+		out = append(out, shard)
+	}
+	return out
+}
+
+func (s *tenantShards[T]) reset() {
+	s.Lock()
+	defer s.Unlock()
+	s.m = map[string]T{}
+}
+
+// byTenant groups items by the tenant that stored them.
+func byTenant(items []store.StoredString) map[string][]store.StoredString {
+	out := map[string][]store.StoredString{}
+	for _, item := range items {
+		t := storedTenant(item)
+		out[t] = append(out[t], item)
+	}
+	return out
+}
+
+// tenantRecords includes soft-deleted records, which are still held for the
+// tenant until purged.
+func tenantRecords(tenant string) ([]store.StoredString, error) {
+	return backend().Filter(func(item store.StoredString) bool { return storedTenant(item) == tenant })
+}
+
+// purgeTenantJobs cancels the tenant's unfinished jobs and forgets all of
+// its jobs along with their persisted payloads.
+func purgeTenantJobs(tenant string) int {
+	jobs.Lock()
+	defer jobs.Unlock()
+	n := 0
+	for id, job := range jobs.m {
+		if job.tenant() != tenant {
+			continue
+		}
+		job.Status = "cancelled"
+		job.Values = nil
+		delete(jobs.m, id)
+		if jobs.dir != "" {
+			_ = os.Remove(filepath.Join(jobs.dir, id+".json"))
+		}
+		n++
+	}
+	return n
+}
+
+// scrubSnapshots removes ids from the corpus snapshots kept for diffing.
+func scrubSnapshots(ids map[string]bool) {
+	snapshots.Lock()
+	defer snapshots.Unlock()
+	for _, snap := range snapshots.list {
+		for id := range ids {
+			delete(snap.values, id)
+		}
+		snap.Count = len(snap.values)
+	}
+}
+
+// forgetTenantSettings drops the tenant's collection configurations and
+// precomputed queries.
+func forgetTenantSettings(tenant string) error {
+	collectionConfigs.Lock()
+	defer collectionConfigs.Unlock()
+	for key := range collectionConfigs.m {
+		if key.tenant == tenant {
+			if err := forgetCollection(key); err != nil {
+				return err
+			}
+		}
+	}
+	precomputed.Lock()
+	for key := range precomputed.entries {
+		if key.tenant == tenant {
+			delete(precomputed.entries, key)
+		}
+	}
+	precomputed.Unlock()
+	return nil
+}
+
+// purgeTenant erases a tenant: its jobs first, so none can write again,
+// then its records, which also drops them from the indexes and caches, and
+// finally every copy of their contents in the event stream, outbox, corpus
+// snapshots and audit log, its settings and then the on-disk store logs.
+// Delete events keep only record IDs, so downstream consumers still learn of
+// the erasure.
+func purgeTenant(tenant string) (map[string]interface{}, error) {
+	jobCount := purgeTenantJobs(tenant)
+	items, err := tenantRecords(tenant)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(items))
+	for _, item := range items {
+		if _, err := db.Delete(item.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return nil, err
+		}
+		ids[item.ID] = true
+	}
+	removed, err := purgeEvents(ids)
+	if err != nil {
+		return nil, err
+	}
+	if pg, ok := db.(*indexedStorage).Storage.(*store.Postgres); ok {
+		if err := pg.PurgeOutbox(ids); err != nil {
+			return nil, err
+		}
+	}
+	scrubSnapshots(ids)
+	auditRemoved, err := purgeAudit(tenant)
+	if err != nil {
+		return nil, err
+	}
+	if err := forgetTenantSettings(tenant); err != nil {
+		return nil, err
+	}
+	if err := store.CompactLogs(); err != nil {
+		return nil, err
+	}
+	requestUsage.Lock()
+	delete(requestUsage.m, tenant)
+	requestUsage.Unlock()
+	return map[string]interface{}{
+		"tenant":          tenant,
+		"strings_deleted": len(ids),
+		"events_removed":  removed,
+		"jobs_removed":    jobCount,
+		"audit_removed":   auditRemoved,
+	}, nil
+}
+
+type tenantSummary struct {
+	Tenant   string     `json:"tenant"`
+	Storage  quotaState `json:"storage"`
+	Requests quotaState `json:"requests"`
+	Jobs     int        `json:"jobs"`
+}
+
+// tenantSummaries reports every tenant that stores strings, has made
+// requests in the current quota window or has jobs, by id.
+func tenantSummaries(now time.Time) []tenantSummary {
+	seen := map[string]bool{}
+	tenantUsage.Lock()
+	for t := range tenantUsage.m {
+		seen[t] = true
+	}
+	tenantUsage.Unlock()
+	requestUsage.Lock()
+	for t, rw := range requestUsage.m {
+		if now.Sub(rw.start) < quotas.window {
+			seen[t] = true
+		}
+	}
+	requestUsage.Unlock()
+	jobCounts := map[string]int{}
+	jobs.Lock()
+	for _, job := range jobs.m {
+		jobCounts[job.tenant()]++
+		seen[job.tenant()] = true
+	}
+	jobs.Unlock()
+	out := make([]tenantSummary, 0, len(seen))
+	for t := range seen {
+		used, resets := requestsUsed(t, now)
+		requests := newQuotaState(used, quotas.requests)
+		ts := store.NewTimestamp(resets)
+		requests.ResetsAt = &ts
+		out = append(out, tenantSummary{
+			Tenant:   t,
+			Storage:  newQuotaState(tenantStored(t), quotas.storage),
+			Requests: requests,
+			Jobs:     jobCounts[t],
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tenant < out[j].Tenant })
+	return out
+}
+
+// tenantsHandler lists the tenants with their usage. Tenants need no
+// registration: one exists once a request names it in X-Tenant-ID.
+func tenantsHandler(w http.ResponseWriter, r *http.Request) {
+	data := tenantSummaries(time.Now())
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data, "count": len(data)})
+}
+
+// pathTenant reads the tenant named by the request path, answering 400
+// when it is not a valid tenant id.
+func pathTenant(w http.ResponseWriter, r *http.Request) (string, bool) {
+	tenant := r.PathValue("tenant")
+	if !tenantIDPattern.MatchString(tenant) {
+		writeError(w, http.StatusBadRequest, "INVALID_TENANT", "invalid tenant id")
+		return "", false
+	}
+	return tenant, true
+}
+
+func tenantHandler(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := pathTenant(w, r)
+	if !ok {
+		return
+	}
+	for _, s := range tenantSummaries(time.Now()) {
+		if s.Tenant == tenant {
+			writeJSON(w, http.StatusOK, s)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "TENANT_NOT_FOUND", "tenant not found")
+}
+
+func exportTenantHandler(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := pathTenant(w, r)
+	if !ok {
+		return
+	}
+	version := storeVersion.Load()
+	items, err := tenantRecords(tenant)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	sortStrings(items, defaultSort)
+	writeRecordListing(w, http.StatusOK, items, nil, version, map[string]interface{}{
+		"tenant":      tenant,
+		"exported_at": store.Now(),
+		"count":       len(items),
+	})
+}
+
+func purgeTenantHandler(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := pathTenant(w, r)
+	if !ok {
+		return
+	}
+	report, err := purgeTenant(tenant)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// newServer returns a server for handler at addr with the configured
+// timeouts and header limit, so slow clients cannot hold connections and
+// their goroutines open indefinitely.
+func newServer(addr string, handler http.Handler) *http.Server {
+	c := settings()
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(c.ReadHeaderTimeoutSeconds) * time.Second,
+		ReadTimeout:       time.Duration(c.ReadTimeoutSeconds) * time.Second,
+		WriteTimeout:      time.Duration(c.WriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(c.IdleTimeoutSeconds) * time.Second,
+		MaxHeaderBytes:    c.MaxHeaderBytes,
+	}
+}
+
+// withRequestTimeout gives every request a deadline of the
+// RequestTimeoutSeconds in effect. Storage operations bound to the request
+// context through storeFor give up at it, and the request fails with 503.
+func withRequestTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n := settings().RequestTimeoutSeconds; n > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), time.Duration(n)*time.Second)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"crypto/tls"
+	"sync/atomic"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// tlsCert is the certificate loaded from TLSCertFile and TLSKeyFile. It is
+// re-read on SIGHUP, so a renewed certificate is served without a restart.
+var tlsCert atomic.Pointer[tls.Certificate]
+
+func loadTLSCertificate(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	tlsCert.Store(&cert)
+	return nil
+}
+
+// serverTLSConfig returns the TLS configuration the server listens with,
+// or nil to serve plain HTTP. With AutocertHosts, certificates for those
+// hosts are obtained from Let's Encrypt and renewed as they near expiry.
+func serverTLSConfig(cfg Config) (*tls.Config, error) {
+	if hosts := splitList(cfg.AutocertHosts); len(hosts) > 0 {
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(hosts...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+			Email:      cfg.AutocertEmail,
+		}
+		startACMEHTTPServer(cfg.ACMEHTTPAddr, m)
+		tc := m.TLSConfig()
+		tc.MinVersion = tls.VersionTLS12
+		return tc, nil
+	}
+	if cfg.TLSCertFile == "" {
+		return nil, nil
+	}
+	if err := loadTLSCertificate(cfg.TLSCertFile, cfg.TLSKeyFile); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return tlsCert.Load(), nil
+		},
+	}, nil
+}
+
+// startACMEHTTPServer answers Let's Encrypt HTTP-01 challenges at addr and
+// redirects every other request to HTTPS. Certificates can also be obtained
+// through TLS-ALPN-01 on the TLS port alone, when addr is empty.
+func startACMEHTTPServer(addr string, m *autocert.Manager) {
+	if addr == "" {
+		return
+	}
+	go func() {
+		logger.Info("ACME challenges listening", "addr", addr)
+		if err := newServer(addr, m.HTTPHandler(nil)).ListenAndServe(); err != nil {
+			logger.Error("ACME challenge server error", "error", err)
+		}
+	}()
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultServiceName = "string-analyzer"
+
+// tracer is a no-op until configureTracing installs an exporting provider,
+// which it then delegates to.
+var tracer = otel.Tracer("github.com/samueltuoyo15/HNG-Stage-1")
+
+var tracerProvider *sdktrace.TracerProvider
+
+// configureTracing exports spans over OTLP/HTTP once an OTLP endpoint is
+// configured. The exporter, sampler and resource read the standard OTEL_*
+// variables themselves.
+func configureTracing() error {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return nil
+	}
+	ctx := context.Background()
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(defaultServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK())
+	if err != nil {
+		return err
+	}
+	tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return nil
+}
+
+// shutdownTracing flushes the spans still batched.
+func shutdownTracing(ctx context.Context) {
+	if tracerProvider == nil {
+		return
+	}
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		logger.Error("trace export failed", "error", err)
+	}
+}
+
+type tracedResponse struct {
+	http.ResponseWriter
+	code int
+}
+
+func (t *tracedResponse) WriteHeader(code int) {
+	if t.code == 0 {
+		t.code = code
+	}
+	t.ResponseWriter.WriteHeader(code)
+}
+
+func (t *tracedResponse) Write(p []byte) (int, error) {
+	if t.code == 0 {
+		t.code = http.StatusOK
+	}
+	return t.ResponseWriter.Write(p)
+}
+
+// withTracing opens a server span per request, continuing the trace named
+// by an incoming traceparent header, and names its trace in the request
+// log so log lines and traces can be matched up.
+func withTracing(next http.Handler) http.Handler {
+	if tracerProvider == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := router.Handler(r)
+		name, route, ok := strings.Cut(pattern, " ")
+		if !ok {
+			name, route = r.Method, pattern
+		}
+		if route != "" {
+			name += " " + route
+		}
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+				semconv.ClientAddress(clientIP(r)),
+				attribute.String("request.id", r.Header.Get(requestIDHeader)),
+			))
+		defer span.End()
+		l := requestLogger(r).With("trace_id", span.SpanContext().TraceID().String())
+		r = r.WithContext(context.WithValue(ctx, requestLoggerKey{}, l))
+		tw := &tracedResponse{ResponseWriter: w}
+		next.ServeHTTP(tw, r)
+		if tw.code == 0 {
+			tw.code = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(tw.code), attribute.String("tenant.id", tenantOf(r)))
+		if tw.code >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(tw.code))
+		}
+	})
+}
+
+// traceStore opens a span for a store operation made on behalf of r and
+// returns the function that ends it. The Storage interface takes no
+// context, so operations are traced where a request makes them.
+func traceStore(r *http.Request, op string, attrs ...attribute.KeyValue) func(error) {
+	_, span := tracer.Start(r.Context(), "storage."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, attribute.String("db.system.name", storageName))...))
+	return func(err error) {
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultTypeaheadLimit = 10
+	maxTypeaheadLimit     = 100
+)
+
+// radixNode is a node of a compressed prefix tree keyed by the lowercased
+// value. Terminal nodes hold the original values sharing that key.
+type radixNode struct {
+	prefix   string
+	children []*radixNode
+	values   map[string]struct{}
+}
+
+type radixTree struct {
+	sync.RWMutex
+	root *radixNode
+	size int
+}
+
+var typeaheadIndex = newTenantShards(newRadixTree)
+
+func newRadixTree() *radixTree {
+	return &radixTree{root: &radixNode{}}
+}
+
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+func (n *radixNode) childFor(b byte) (int, *radixNode) {
+	i := sort.Search(len(n.children), func(i int) bool { return n.children[i].prefix[0] >= b })
+	if i < len(n.children) && n.children[i].prefix[0] == b {
+		return i, n.children[i]
+	}
+	return i, nil
+}
+
+func (n *radixNode) insertChild(i int, c *radixNode) {
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = c
+}
+
+func (t *radixTree) insert(value string) {
+	t.Lock()
+	defer t.Unlock()
+	key := strings.ToLower(value)
+	n := t.root
+	for key != "" {
+		i, c := n.childFor(key[0])
+		if c == nil {
+			c = &radixNode{prefix: key}
+			n.insertChild(i, c)
+			n = c
+			break
+		}
+		common := commonPrefixLen(c.prefix, key)
+		if common < len(c.prefix) {
+			split := &radixNode{prefix: c.prefix[:common], children: []*radixNode{c}}
+			c.prefix = c.prefix[common:]
+			n.children[i] = split
+			c = split
+		}
+		n = c
+		key = key[common:]
+	}
+	if n.values == nil {
+		n.values = map[string]struct{}{}
+	}
+	if _, ok := n.values[value]; !ok {
+		n.values[value] = struct{}{}
+		t.size++
+	}
+}
+
+func (t *radixTree) remove(value string) {
+	t.Lock()
+	defer t.Unlock()
+	if t.root.remove(strings.ToLower(value), value) {
+		t.size--
+	}
+}
+
+// remove deletes value below n and compacts the path: empty leaves are
+// dropped and non-terminal nodes with a single child are merged into it.
+func (n *radixNode) remove(key, value string) bool {
+	if key == "" {
+		if _, ok := n.values[value]; !ok {
+			return false
+		}
+		delete(n.values, value)
+		return true
+	}
+	i, c := n.childFor(key[0])
+	if c == nil || !strings.HasPrefix(key, c.prefix) {
+		return false
+	}
+	if !c.remove(key[len(c.prefix):], value) {
+		return false
+	}
+	switch {
+	case len(c.values) == 0 && len(c.children) == 0:
+		n.children = append(n.children[:i], n.children[i+1:]...)
+	case len(c.values) == 0 && len(c.children) == 1:
+		only := c.children[0]
+		only.prefix = c.prefix + only.prefix
+		n.children[i] = only
+	}
+	return true
+}
+
+func (t *radixTree) reset() {
+	t.Lock()
+	defer t.Unlock()
+	t.root = &radixNode{}
+	t.size = 0
+}
+
+func (n *radixNode) collect(out []string, limit int) []string {
+	if len(n.values) > 0 {
+		vals := make([]string, 0, len(n.values))
+		for v := range n.values {
+			vals = append(vals, v)
+		}
+		sort.Strings(vals)
+		for _, v := range vals {
+			if len(out) >= limit {
+				return out
+			}
+			out = append(out, v)
+		}
+	}
+	for _, c := range n.children {
+		if len(out) >= limit {
+			return out
+		}
+		out = c.collect(out, limit)
+	}
+	return out
+}
+
+func (t *radixTree) withPrefix(prefix string, limit int) []string {
+	t.RLock()
+	defer t.RUnlock()
+	key := strings.ToLower(prefix)
+	n := t.root
+	for key != "" {
+		_, c := n.childFor(key[0])
+		if c == nil {
+			return []string{}
+		}
+		if strings.HasPrefix(c.prefix, key) {
+			n = c
+			break
+		}
+		if !strings.HasPrefix(key, c.prefix) {
+			return []string{}
+		}
+		key = key[len(c.prefix):]
+		n = c
+	}
+	return n.collect([]string{}, limit)
+}
+
+var typeaheadParams = paramSchema{
+	requiredParam("prefix"),
+	intParam("limit", 1, maxTypeaheadLimit, defaultTypeaheadLimit),
+}
+
+func typeaheadHandler(w http.ResponseWriter, r *http.Request) {
+	if !indexEnabled("typeahead") {
+		writeIndexDisabled(w, "typeahead")
+		return
+	}
+	var errs paramErrors
+	p := typeaheadParams.parse(r.URL.Query(), &errs)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	prefix, limit := p.str("prefix"), p.int("limit")
+	results := typeaheadIndex.get(tenantOf(r)).withPrefix(prefix, limit)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":   results,
+		"count":  len(results),
+		"prefix": prefix,
+	})
+}
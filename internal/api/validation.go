@@ -0,0 +1,58 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// valueRuleError is a created value the API rejects: a missing or non-string
+// value, or one that breaks one of the configurable value rules. code
+// identifies the rule so clients can react without parsing the message.
+type valueRuleError struct {
+	code string
+	msg  string
+}
+
+func (e *valueRuleError) Error() string { return e.msg }
+
+var errValueInvalidUTF8 = &valueRuleError{"VALUE_INVALID_UTF8", `"value" must be valid UTF-8`}
+
+// checkValueRules checks v against the value rules in effect. A zero length
+// bound is no bound.
+func checkValueRules(v string) error {
+	rules := settings()
+	if rules.RejectInvalidUTF8 && !utf8.ValidString(v) {
+		return errValueInvalidUTF8
+	}
+	if strings.ContainsAny(v, recordKeySeparators) {
+		return &valueRuleError{"VALUE_CONTROL_CHARACTER", `"value" must not contain U+0000 or U+0001`}
+	}
+	if rules.RejectBlankValues && strings.TrimFunc(v, unicode.IsSpace) == "" {
+		return &valueRuleError{"VALUE_BLANK", `"value" must not be empty or only whitespace`}
+	}
+	n := utf8.RuneCountInString(v)
+	if rules.MinValueLength > 0 && n < rules.MinValueLength {
+		return &valueRuleError{"VALUE_TOO_SHORT", fmt.Sprintf(`"value" must be at least %d characters`, rules.MinValueLength)}
+	}
+	if rules.MaxValueLength > 0 && n > rules.MaxValueLength {
+		return &valueRuleError{"VALUE_TOO_LONG", fmt.Sprintf(`"value" must be at most %d characters`, rules.MaxValueLength)}
+	}
+	if route := reservedValue(v); route != "" {
+		return &valueRuleError{"VALUE_RESERVED", fmt.Sprintf(`"value" %q is reserved: it would be served by %s instead of the string`, v, route)}
+	}
+	return nil
+}
+
+// writeValidationError writes err with its rule code when it has one.
+func writeValidationError(w http.ResponseWriter, status int, err error) {
+	var re *valueRuleError
+	if errors.As(err, &re) {
+		writeError(w, status, re.code, re.msg)
+		return
+	}
+	writeError(w, status, "INVALID_VALUE", err.Error())
+}
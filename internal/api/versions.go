@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const maxUpdateAttempts = 5
+
+var (
+	errVersionConflict    = errors.New("record was updated concurrently")
+	errPreconditionFailed = errors.New("If-Match does not match the record version")
+)
+
+// versionPrecondition is a parsed If-Match header, listing the record
+// versions a write may apply to. A nil precondition matches any record.
+type versionPrecondition struct {
+	any      bool
+	versions []int64
+}
+
+// parseIfMatch reads If-Match as record versions, quoted like entity tags
+// (`"3"`) or bare, or `*` for any existing record.
+func parseIfMatch(r *http.Request) (*versionPrecondition, error) {
+	h := r.Header.Get("If-Match")
+	if h == "" {
+		return nil, nil
+	}
+	p := &versionPrecondition{}
+	for _, tag := range strings.Split(h, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			p.any = true
+			continue
+		}
+		n, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+		if err != nil || n < 0 {
+			return nil, errors.New(`invalid If-Match header, expected record versions such as "3" or *`)
+		}
+		p.versions = append(p.versions, n)
+	}
+	return p, nil
+}
+
+func (p *versionPrecondition) check(item store.StoredString) error {
+	if p == nil || p.any {
+		return nil
+	}
+	for _, v := range p.versions {
+		if v == item.Version {
+			return nil
+		}
+	}
+	return errPreconditionFailed
+}
+
+// updateRecord applies change to the current version of the record id and
+// stores it as the next version, retrying when another writer got there
+// first so neither write is lost. pre is checked against the version read,
+// and an error from change abandons the update.
+func updateRecord(id string, pre *versionPrecondition, change func(*store.StoredString) error) (store.StoredString, error) {
+	for attempt := 1; ; attempt++ {
+		item, err := db.Get(id)
+		if err != nil {
+			return store.StoredString{}, err
+		}
+		if err := pre.check(item); err != nil {
+			return store.StoredString{}, err
+		}
+		if err := change(&item); err != nil {
+			return store.StoredString{}, err
+		}
+		item.Version++
+		err = db.Update(item)
+		if errors.Is(err, errVersionConflict) && attempt < maxUpdateAttempts {
+			continue
+		}
+		if err != nil {
+			return store.StoredString{}, err
+		}
+		return item, nil
+	}
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/store"
+)
+
+const (
+	webhookBatchSize    = 100
+	webhookPollInterval = 2 * time.Second
+	webhookMaxBackoff   = time.Minute
+	webhookEventHeader  = "X-Event-ID"
+	webhookSigHeader    = "X-Webhook-Signature"
+)
+
+// eventOutbox is where undelivered events wait. Dispatch passes up to batch
+// of them to deliver in order, stopping at the first failure, and forgets only the
+// events that were delivered.
+type eventOutbox interface {
+	Dispatch(batch int, deliver func(store.Event) error) (int, error)
+}
+
+var webhooks = struct {
+	sync.Mutex
+	url       string
+	secret    []byte
+	client    *http.Client
+	wake      chan struct{}
+	delivered int
+	failures  int
+	lastError string
+	lastEvent int64
+}{client: &http.Client{Timeout: 10 * time.Second}, wake: make(chan struct{}, 1)}
+
+// notifyWebhooks wakes the dispatcher after a write instead of waiting for
+// the next poll.
+func notifyWebhooks() {
+	select {
+	case webhooks.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliverWebhook posts one event. Receivers should treat X-Event-ID as an
+// idempotency key: an event is redelivered if the process stops after the
+// POST but before the outbox records it as delivered.
+func deliverWebhook(e store.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, webhooks.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, strconv.FormatInt(e.Cursor, 10))
+	if len(webhooks.secret) > 0 {
+		mac := hmac.New(sha256.New, webhooks.secret)
+		mac.Write(body)
+		req.Header.Set(webhookSigHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := webhooks.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	webhooks.Lock()
+	webhooks.delivered++
+	webhooks.lastEvent = e.Cursor
+	webhooks.Unlock()
+	return nil
+}
+
+// startWebhooks delivers events to WEBHOOK_URL when it is set. The
+// PostgreSQL backend keeps its own transactional outbox; other backends
+// deliver the in-process event stream.
+func startWebhooks(eventsPath string) error {
+	url := os.Getenv("WEBHOOK_URL")
+	if url == "" {
+		return nil
+	}
+	var outbox eventOutbox
+	if pg, ok := db.(*indexedStorage).Storage.(*store.Postgres); ok {
+		outbox = pg
+	} else {
+		so, err := newStreamOutbox(eventsPath)
+		if err != nil {
+			return err
+		}
+		events.Lock()
+		events.held = so.delivered
+		events.Unlock()
+		outbox = so
+	}
+	webhooks.url, webhooks.secret = url, []byte(os.Getenv("WEBHOOK_SECRET"))
+	goBackground(func(ctx context.Context) { runWebhookDispatcher(ctx, outbox) })
+	return nil
+}
+
+// runWebhookDispatcher retries failed deliveries with exponential backoff.
+// Events stay in the outbox until delivered.
+func runWebhookDispatcher(ctx context.Context, outbox eventOutbox) {
+	var backoff time.Duration
+	var retryAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-webhooks.wake:
+		case <-time.After(webhookPollInterval):
+		}
+		if time.Now().Before(retryAt) {
+			continue
+		}
+		for {
+			n, err := outbox.Dispatch(webhookBatchSize, deliverWebhook)
+			if err != nil {
+				webhooks.Lock()
+				webhooks.failures++
+				webhooks.lastError = err.Error()
+				webhooks.Unlock()
+				backoff = min(max(2*backoff, time.Second), webhookMaxBackoff)
+				retryAt = time.Now().Add(backoff)
+				break
+			}
+			backoff = 0
+			if n < webhookBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+}
+
+// streamOutbox delivers the in-process event stream. The cursor of the last
+// delivered event is kept next to EVENTS_PATH, so with a persisted stream
+// nothing is lost or resent wholesale across restarts.
+type streamOutbox struct {
+	sync.Mutex
+	path      string
+	delivered int64
+}
+
+func newStreamOutbox(eventsPath string) (*streamOutbox, error) {
+	o := &streamOutbox{}
+	if eventsPath == "" {
+		return o, nil
+	}
+	o.path = eventsPath + ".delivered"
+	data, err := os.ReadFile(o.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if o.delivered, err = strconv.ParseInt(string(bytes.TrimSpace(data)), 10, 64); err != nil {
+			return nil, fmt.Errorf("%s: %w", o.path, err)
+		}
+	}
+	return o, nil
+}
+
+func (o *streamOutbox) Dispatch(batch int, deliver func(store.Event) error) (int, error) {
+	o.Lock()
+	defer o.Unlock()
+	events.Lock()
+	start := sort.Search(len(events.list), func(i int) bool { return events.list[i].Cursor > o.delivered })
+	pending := append([]store.Event(nil), events.list[start:min(start+batch, len(events.list))]...)
+	events.Unlock()
+	sent := 0
+	var err error
+	for _, e := range pending {
+		if err = deliver(e); err != nil {
+			break
+		}
+		o.delivered = e.Cursor
+		sent++
+	}
+	events.Lock()
+	events.held = o.delivered
+	events.Unlock()
+	if sent > 0 && o.path != "" {
+		tmp := o.path + ".tmp"
+		if werr := os.WriteFile(tmp, []byte(strconv.FormatInt(o.delivered, 10)), 0o644); werr != nil {
+			return sent, werr
+		}
+		if werr := os.Rename(tmp, o.path); werr != nil {
+			return sent, werr
+		}
+	}
+	return sent, err
+}
+
+func webhookStatusHandler(w http.ResponseWriter, r *http.Request) {
+	webhooks.Lock()
+	defer webhooks.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"enabled":       webhooks.url != "",
+		"delivered":     webhooks.delivered,
+		"failures":      webhooks.failures,
+		"last_error":    webhooks.lastError,
+		"last_event_id": webhooks.lastEvent,
+	})
+}
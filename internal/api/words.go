@@ -0,0 +1,117 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+)
+
+const (
+	defaultTopWords = 50
+	maxTopWords     = 1000
+)
+
+type wordCounter struct {
+	sync.Mutex
+	counts map[string]int
+	total  int
+}
+
+var corpusWords = newTenantShards(func() *wordCounter { return &wordCounter{counts: map[string]int{}} })
+
+func (c *wordCounter) add(s string) {
+	c.Lock()
+	defer c.Unlock()
+	for _, w := range analysis.NormalizedWords(s) {
+		c.counts[w]++
+		c.total++
+	}
+}
+
+func (c *wordCounter) remove(s string) {
+	c.Lock()
+	defer c.Unlock()
+	for _, w := range analysis.NormalizedWords(s) {
+		c.counts[w]--
+		c.total--
+		if c.counts[w] <= 0 {
+			delete(c.counts, w)
+		}
+	}
+}
+
+func (c *wordCounter) reset() {
+	c.Lock()
+	defer c.Unlock()
+	c.counts = map[string]int{}
+	c.total = 0
+}
+
+type wordFrequency struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+var wordStatsParams = paramSchema{
+	intParam("top", 1, maxTopWords, defaultTopWords),
+	intParam("min_length", 0, math.MaxInt, 0),
+	boolParam("exclude_stopwords", false),
+	{name: "stopword_language", def: []string{"en"}, parse: func(v string) (interface{}, error) {
+		langs := strings.Split(v, ",")
+		return langs, analysis.ValidateStopwordLanguages(langs)
+	}},
+	stringParam("exclude", ""),
+}
+
+func wordStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if !indexEnabled("words") {
+		writeIndexDisabled(w, "words")
+		return
+	}
+	var errs paramErrors
+	p := wordStatsParams.parse(r.URL.Query(), &errs)
+	if len(errs) > 0 {
+		writeParamErrors(w, errs)
+		return
+	}
+	top, minLength, excludeStopwords, langs := p.int("top"), p.int("min_length"), p.bool("exclude_stopwords"), p.strings("stopword_language")
+	exclude := map[string]bool{}
+	for _, v := range strings.Split(p.str("exclude"), ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			exclude[v] = true
+		}
+	}
+
+	corpus := corpusWords.get(tenantOf(r))
+	corpus.Lock()
+	words := make([]wordFrequency, 0, len(corpus.counts))
+	for word, n := range corpus.counts {
+		if utf8.RuneCountInString(word) < minLength || exclude[word] || (excludeStopwords && analysis.IsStopword(word, langs, nil)) {
+			continue
+		}
+		words = append(words, wordFrequency{Word: word, Count: n})
+	}
+	total, distinct := corpus.total, len(corpus.counts)
+	corpus.Unlock()
+
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+		return words[i].Word < words[j].Word
+	})
+	if len(words) > top {
+		words = words[:top]
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":           words,
+		"count":          len(words),
+		"total_words":    total,
+		"distinct_words": distinct,
+	})
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"io"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// blockStyle clears the flow and quoting styles a JSON document parses
+// with, so the encoder writes block YAML and quotes only where needed. Keys
+// holding control characters, such as a "\n" in character_frequency_map,
+// stay double-quoted rather than becoming complex keys.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for i, c := range n.Content {
+		blockStyle(c)
+		if n.Kind == yaml.MappingNode && i%2 == 0 && strings.IndexFunc(c.Value, unicode.IsControl) >= 0 {
+			c.Style = yaml.DoubleQuotedStyle
+		}
+	}
+}
+
+// jsonToYAML converts a JSON document to YAML. JSON is valid YAML, so it is
+// parsed as a node tree, which keeps the order of object keys.
+func jsonToYAML(data []byte, w io.Writer) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
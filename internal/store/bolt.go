@@ -0,0 +1,180 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultBoltPath is the database file used unless BOLT_PATH is set.
+const DefaultBoltPath = "strings.db"
+
+var (
+	boltBucket            = []byte("strings")
+	boltCollectionsBucket = []byte("collections")
+)
+
+// Bolt persists records to a local bbolt file, one JSON value per
+// SHA-256 ID in a single bucket. Collection configurations live in a second
+// bucket keyed by tenant and name.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens the database file at path, creating it if needed.
+func OpenBolt(path string) (*Bolt, error) {
+	if path == "" {
+		path = DefaultBoltPath
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(boltBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(boltCollectionsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Bolt{db: db}, nil
+}
+
+func (s *Bolt) Get(id string) (StoredString, error) {
+	var item StoredString
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &item)
+	})
+	return item, err
+}
+
+func (s *Bolt) Put(item StoredString) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltBucket)
+		if b.Get([]byte(item.ID)) != nil {
+			return ErrExists
+		}
+		if MaxRecords > 0 && b.Stats().KeyN >= MaxRecords {
+			return ErrFull
+		}
+		return b.Put([]byte(item.ID), data)
+	})
+}
+
+func (s *Bolt) Update(item StoredString) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltBucket)
+		if b.Get([]byte(item.ID)) == nil {
+			return ErrNotFound
+		}
+		return b.Put([]byte(item.ID), data)
+	})
+}
+
+func (s *Bolt) Delete(id string) (StoredString, error) {
+	var item StoredString
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltBucket)
+		data := b.Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(data, &item); err != nil {
+			return err
+		}
+		return b.Delete([]byte(id))
+	})
+	return item, err
+}
+
+func (s *Bolt) List() ([]StoredString, error) {
+	return s.Filter(func(StoredString) bool { return true })
+}
+
+func (s *Bolt) Filter(match func(StoredString) bool) ([]StoredString, error) {
+	out := []StoredString{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).ForEach(func(_, data []byte) error {
+			var item StoredString
+			if err := json.Unmarshal(data, &item); err != nil {
+				return err
+			}
+			if match(item) {
+				out = append(out, item)
+			}
+			return nil
+		})
+	})
+	return out, err
+}
+
+func (s *Bolt) Reset() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(boltBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(boltBucket)
+		return err
+	})
+}
+
+func boltCollectionKey(tenant, name string) []byte {
+	return []byte(tenant + "/" + name)
+}
+
+func (s *Bolt) PutCollection(c Collection) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCollectionsBucket).Put(boltCollectionKey(c.Tenant, c.Name), data)
+	})
+}
+
+func (s *Bolt) DeleteCollection(tenant, name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCollectionsBucket).Delete(boltCollectionKey(tenant, name))
+	})
+}
+
+func (s *Bolt) Collections() ([]Collection, error) {
+	out := []Collection{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCollectionsBucket).ForEach(func(_, data []byte) error {
+			var c Collection
+			if err := json.Unmarshal(data, &c); err != nil {
+				return err
+			}
+			out = append(out, c)
+			return nil
+		})
+	})
+	return sortCollections(out), err
+}
+
+// Ping checks the database file is still open.
+func (s *Bolt) Ping(context.Context) error {
+	return s.db.View(func(*bolt.Tx) error { return nil })
+}
+
+func (s *Bolt) Close() error {
+	return s.db.Close()
+}
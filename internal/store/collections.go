@@ -0,0 +1,63 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+)
+
+// Collection is the analyzer configuration of one tenant's collection.
+type Collection struct {
+	Tenant string           `json:"tenant"`
+	Name   string           `json:"name"`
+	Config analysis.Options `json:"config"`
+}
+
+// CollectionStore is implemented by backends that keep collection
+// configurations beside the records, so they survive restarts. Reset
+// leaves them in place. DeleteCollection of a missing collection is not an
+// error.
+type CollectionStore interface {
+	PutCollection(c Collection) error
+	DeleteCollection(tenant, name string) error
+	Collections() ([]Collection, error)
+}
+
+type collectionKey struct {
+	tenant, name string
+}
+
+// sortCollections orders cs by tenant and name.
+func sortCollections(cs []Collection) []Collection {
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Tenant != cs[j].Tenant {
+			return cs[i].Tenant < cs[j].Tenant
+		}
+		return cs[i].Name < cs[j].Name
+	})
+	return cs
+}
+
+func (s *Memory) PutCollection(c Collection) error {
+	s.Lock()
+	defer s.Unlock()
+	s.collections[collectionKey{c.Tenant, c.Name}] = c
+	return nil
+}
+
+func (s *Memory) DeleteCollection(tenant, name string) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.collections, collectionKey{tenant, name})
+	return nil
+}
+
+func (s *Memory) Collections() ([]Collection, error) {
+	s.RLock()
+	defer s.RUnlock()
+	out := make([]Collection, 0, len(s.collections))
+	for _, c := range s.collections {
+		out = append(out, c)
+	}
+	return sortCollections(out), nil
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultSnapshotInterval is how often the server saves the memory store
+// snapshot unless configured otherwise.
+const DefaultSnapshotInterval = time.Minute
+
+type storeSnapshotFile struct {
+	SavedAt     Timestamp      `json:"saved_at"`
+	Records     []StoredString `json:"records"`
+	Collections []Collection   `json:"collections,omitempty"`
+}
+
+// storeSnapshots periodically writes the in-memory store to path so the
+// default backend survives restarts.
+var storeSnapshots = struct {
+	sync.Mutex
+	path string
+	mem  *Memory
+	wal  *walStorage
+}{}
+
+// OpenMemory returns the in-memory backend, loaded from the snapshot
+// at path when that file exists and then from the walPath log of writes made
+// since.
+func OpenMemory(path, walPath string) (Storage, error) {
+	mem := NewMemory()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			var file storeSnapshotFile
+			if err := json.Unmarshal(data, &file); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			for _, item := range file.Records {
+				mem.m[item.ID] = item
+			}
+			for _, c := range file.Collections {
+				mem.collections[collectionKey{c.Tenant, c.Name}] = c
+			}
+		}
+		storeSnapshots.path, storeSnapshots.mem = path, mem
+	}
+	if walPath == "" {
+		return mem, nil
+	}
+	wal, err := openWAL(walPath, mem)
+	if err != nil {
+		return nil, err
+	}
+	storeSnapshots.wal = wal
+	return wal, nil
+}
+
+// SaveSnapshot writes the store to a temporary file and renames it into
+// place, so a crash mid-write never leaves a truncated snapshot. The
+// write-ahead log is then compacted; writes are held off meanwhile so none
+// can land between the snapshot and the truncation.
+func SaveSnapshot() error {
+	storeSnapshots.Lock()
+	defer storeSnapshots.Unlock()
+	if storeSnapshots.mem == nil {
+		return nil
+	}
+	if wal := storeSnapshots.wal; wal != nil {
+		wal.mu.Lock()
+		defer wal.mu.Unlock()
+	}
+	records, _ := storeSnapshots.mem.List()
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	collections, _ := storeSnapshots.mem.Collections()
+	data, err := json.Marshal(storeSnapshotFile{SavedAt: Now(), Records: records, Collections: collections})
+	if err != nil {
+		return err
+	}
+	tmp := storeSnapshots.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, storeSnapshots.path); err != nil {
+		return err
+	}
+	if storeSnapshots.wal != nil {
+		return storeSnapshots.wal.compact()
+	}
+	return nil
+}
+
+// CompactLogs rewrites the on-disk copies of the memory store so they
+// hold only current records.
+func CompactLogs() error {
+	if storeSnapshots.mem != nil {
+		return SaveSnapshot()
+	}
+	if storeSnapshots.wal != nil {
+		return storeSnapshots.wal.rewrite()
+	}
+	return nil
+}
+
+// Persistent reports whether the memory store opened by OpenMemory keeps a
+// snapshot or write-ahead log on disk.
+func Persistent() bool {
+	return storeSnapshots.mem != nil || storeSnapshots.wal != nil
+}
+
+// FileBytes is the combined size of the store snapshot and the write-ahead
+// log.
+func FileBytes() int64 {
+	var paths []string
+	if storeSnapshots.mem != nil {
+		paths = append(paths, storeSnapshots.path)
+	}
+	if wal := storeSnapshots.wal; wal != nil {
+		paths = append(paths, wal.path)
+	}
+	var n int64
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil {
+			n += fi.Size()
+		}
+	}
+	return n
+}
+
+// LogStats counts the entries of the write-ahead log, how many are
+// tombstones, and how many a later write has superseded. It reports zeros
+// when there is no log.
+func LogStats() (entries, tombstones, stale int, err error) {
+	if storeSnapshots.wal == nil {
+		return 0, 0, 0, nil
+	}
+	return storeSnapshots.wal.stats()
+}
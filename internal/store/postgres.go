@@ -0,0 +1,464 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const postgresTimeout = 10 * time.Second
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS strings (
+	id            text PRIMARY KEY,
+	collection    text NOT NULL DEFAULT '',
+	length        integer NOT NULL,
+	word_count    integer NOT NULL,
+	is_palindrome boolean NOT NULL,
+	record        jsonb NOT NULL
+);
+CREATE INDEX IF NOT EXISTS strings_length_idx ON strings (length);
+CREATE INDEX IF NOT EXISTS strings_word_count_idx ON strings (word_count);
+CREATE INDEX IF NOT EXISTS strings_chars_idx ON strings USING gin ((record->'properties'->'character_frequency_map'));
+CREATE TABLE IF NOT EXISTS outbox (
+	cursor    bigserial PRIMARY KEY,
+	type      text NOT NULL,
+	record_id text NOT NULL DEFAULT '',
+	record    jsonb,
+	at        timestamptz NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS collections (
+	tenant text NOT NULL,
+	name   text NOT NULL,
+	config jsonb NOT NULL,
+	PRIMARY KEY (tenant, name)
+);
+`
+
+// postgresOutboxLock is the advisory lock id that elects a single outbox
+// dispatcher among instances sharing the database, keeping delivery ordered.
+const postgresOutboxLock = 0x68_6e_67_6f_75_74
+
+// postgresFilters translates listing filters into SQL. Each function returns
+// a condition using the placeholder it is given for the filter value.
+var postgresFilters = map[string]func(ph string) string{
+	"is_palindrome": func(ph string) string { return "is_palindrome = " + ph },
+	"min_length":    func(ph string) string { return "length >= " + ph },
+	"max_length":    func(ph string) string { return "length <= " + ph },
+	"word_count":    func(ph string) string { return "word_count = " + ph },
+	"collection":    func(ph string) string { return "collection = " + ph },
+	"tag":           func(ph string) string { return "coalesce(record->'tags', '[]') ?& " + ph + "::text[]" },
+	"contains_character": func(ph string) string {
+		return "CASE WHEN coalesce((record->'properties'->>'frequency_map_case_folded')::boolean, false)" +
+			" THEN record->'properties'->'character_frequency_map' ? lower(" + ph + "::text)" +
+			" ELSE record->'properties'->'character_frequency_map' ? " + ph + "::text END"
+	},
+}
+
+// Postgres writes an outbox row in the same transaction as every change when
+// Outbox is set, so an event exists exactly when its change was committed.
+// OnCommit, if set, is called after each such transaction.
+type Postgres struct {
+	Outbox   bool
+	OnCommit func()
+	pool     *pgxpool.Pool
+	ctx      context.Context
+}
+
+// WithContext returns a view of s whose operations are bounded by ctx.
+func (s *Postgres) WithContext(ctx context.Context) Storage {
+	bound := *s
+	bound.ctx = ctx
+	return &bound
+}
+
+type pgQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// write runs fn in a transaction, followed by the outbox row it returns.
+func (s *Postgres) write(ctx context.Context, fn func(q pgQuerier) (Event, error)) error {
+	if !s.Outbox {
+		_, err := fn(s.pool)
+		return err
+	}
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		e, err := fn(tx)
+		if err != nil {
+			return err
+		}
+		var record []byte
+		if e.Record != nil {
+			if record, err = json.Marshal(e.Record); err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(ctx, "INSERT INTO outbox (type, record_id, record) VALUES ($1, $2, $3)", e.Type, e.ID, record)
+		return err
+	})
+	if err == nil && s.OnCommit != nil {
+		s.OnCommit()
+	}
+	return err
+}
+
+// OpenPostgres connects to the database at url and creates the tables it
+// needs.
+func OpenPostgres(url string) (*Postgres, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := pool.Exec(ctx, postgresSchema); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return &Postgres{pool: pool}, nil
+}
+
+func scanRecords(rows pgx.Rows) ([]StoredString, error) {
+	defer rows.Close()
+	out := []StoredString{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var item StoredString
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+func (s *Postgres) Get(id string) (StoredString, error) {
+	ctx, cancel := operationContext(s.ctx, postgresTimeout)
+	defer cancel()
+	rows, err := s.pool.Query(ctx, "SELECT record FROM strings WHERE id = $1", id)
+	if err != nil {
+		return StoredString{}, err
+	}
+	items, err := scanRecords(rows)
+	if err != nil {
+		return StoredString{}, err
+	}
+	if len(items) == 0 {
+		return StoredString{}, ErrNotFound
+	}
+	return items[0], nil
+}
+
+func (s *Postgres) Put(item StoredString) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := operationContext(s.ctx, postgresTimeout)
+	defer cancel()
+	return s.write(ctx, func(q pgQuerier) (Event, error) {
+		if MaxRecords > 0 {
+			var n int
+			if err := q.QueryRow(ctx, "SELECT count(*) FROM strings").Scan(&n); err != nil {
+				return Event{}, err
+			}
+			if n >= MaxRecords {
+				return Event{}, ErrFull
+			}
+		}
+		tag, err := q.Exec(ctx, `INSERT INTO strings (id, collection, length, word_count, is_palindrome, record)
+			VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (id) DO NOTHING`,
+			item.ID, item.Collection, item.Properties.Length, item.Properties.WordCount, item.Properties.IsPalindrome, data)
+		if err != nil {
+			return Event{}, err
+		}
+		if tag.RowsAffected() == 0 {
+			return Event{}, ErrExists
+		}
+		return Event{Type: "create", ID: item.ID, Record: &item}, nil
+	})
+}
+
+func (s *Postgres) Update(item StoredString) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := operationContext(s.ctx, postgresTimeout)
+	defer cancel()
+	return s.write(ctx, func(q pgQuerier) (Event, error) {
+		tag, err := q.Exec(ctx, `UPDATE strings SET collection = $2, length = $3, word_count = $4, is_palindrome = $5, record = $6
+			WHERE id = $1`,
+			item.ID, item.Collection, item.Properties.Length, item.Properties.WordCount, item.Properties.IsPalindrome, data)
+		if err != nil {
+			return Event{}, err
+		}
+		if tag.RowsAffected() == 0 {
+			return Event{}, ErrNotFound
+		}
+		return Event{Type: "update", ID: item.ID, Record: &item}, nil
+	})
+}
+
+func (s *Postgres) Delete(id string) (StoredString, error) {
+	ctx, cancel := operationContext(s.ctx, postgresTimeout)
+	defer cancel()
+	var item StoredString
+	err := s.write(ctx, func(q pgQuerier) (Event, error) {
+		rows, err := q.Query(ctx, "DELETE FROM strings WHERE id = $1 RETURNING record", id)
+		if err != nil {
+			return Event{}, err
+		}
+		items, err := scanRecords(rows)
+		if err != nil {
+			return Event{}, err
+		}
+		if len(items) == 0 {
+			return Event{}, ErrNotFound
+		}
+		item = items[0]
+		return Event{Type: "delete", ID: id}, nil
+	})
+	if err != nil {
+		return StoredString{}, err
+	}
+	return item, nil
+}
+
+func (s *Postgres) List() ([]StoredString, error) {
+	return s.query("", nil)
+}
+
+func (s *Postgres) Filter(match func(StoredString) bool) ([]StoredString, error) {
+	items, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	out := items[:0]
+	for _, item := range items {
+		if match(item) {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+// FilterQuery pushes every condition with a SQL translation into the WHERE
+// clause, including OR groups whose conditions all translate, and applies
+// the rest to the returned rows.
+func (s *Postgres) FilterQuery(q Query) ([]StoredString, error) {
+	var clauses []string
+	var args []interface{}
+	translate := func(c Cond) (string, bool) {
+		sql, ok := postgresFilters[c.Name]
+		if !ok {
+			return "", false
+		}
+		args = append(args, c.Value)
+		return sql(fmt.Sprintf("$%d", len(args))), true
+	}
+	residual := Query{}
+	if q.Tenant != "" {
+		stored := q.Tenant
+		if stored == DefaultTenant {
+			stored = ""
+		}
+		args = append(args, stored)
+		clauses = append(clauses, fmt.Sprintf("coalesce(record->>'tenant', '') = $%d", len(args)))
+	}
+	for _, c := range q.And {
+		if clause, ok := translate(c); ok {
+			clauses = append(clauses, clause)
+		} else {
+			residual.And = append(residual.And, c)
+		}
+	}
+	for _, group := range q.Or {
+		mark := len(args)
+		parts := make([]string, 0, len(group))
+		for _, c := range group {
+			clause, ok := translate(c)
+			if !ok {
+				break
+			}
+			parts = append(parts, clause)
+		}
+		if len(parts) == len(group) {
+			clauses = append(clauses, "("+strings.Join(parts, " OR ")+")")
+		} else {
+			args = args[:mark]
+			residual.Or = append(residual.Or, group)
+		}
+	}
+	where := ""
+	if len(clauses) > 0 {
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+	items, err := s.query(where, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(residual.And) == 0 && len(residual.Or) == 0 {
+		return items, nil
+	}
+	out := items[:0]
+	for _, item := range items {
+		if residual.Matches(item) {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (s *Postgres) query(where string, args []interface{}) ([]StoredString, error) {
+	ctx, cancel := operationContext(s.ctx, postgresTimeout)
+	defer cancel()
+	rows, err := s.pool.Query(ctx, "SELECT record FROM strings"+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanRecords(rows)
+}
+
+func (s *Postgres) Reset() error {
+	ctx, cancel := operationContext(s.ctx, postgresTimeout)
+	defer cancel()
+	return s.write(ctx, func(q pgQuerier) (Event, error) {
+		_, err := q.Exec(ctx, "TRUNCATE strings")
+		return Event{Type: "reset"}, err
+	})
+}
+
+func (s *Postgres) PutCollection(c Collection) error {
+	data, err := json.Marshal(c.Config)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := operationContext(s.ctx, postgresTimeout)
+	defer cancel()
+	_, err = s.pool.Exec(ctx, `INSERT INTO collections (tenant, name, config) VALUES ($1, $2, $3)
+		ON CONFLICT (tenant, name) DO UPDATE SET config = excluded.config`, c.Tenant, c.Name, data)
+	return err
+}
+
+func (s *Postgres) DeleteCollection(tenant, name string) error {
+	ctx, cancel := operationContext(s.ctx, postgresTimeout)
+	defer cancel()
+	_, err := s.pool.Exec(ctx, "DELETE FROM collections WHERE tenant = $1 AND name = $2", tenant, name)
+	return err
+}
+
+func (s *Postgres) Collections() ([]Collection, error) {
+	ctx, cancel := operationContext(s.ctx, postgresTimeout)
+	defer cancel()
+	rows, err := s.pool.Query(ctx, "SELECT tenant, name, config FROM collections ORDER BY tenant, name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Collection{}
+	for rows.Next() {
+		var c Collection
+		var data []byte
+		if err := rows.Scan(&c.Tenant, &c.Name, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &c.Config); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func (s *Postgres) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+func (s *Postgres) Close() error {
+	s.pool.Close()
+	return nil
+}
+
+// Dispatch delivers up to batch outbox rows in order while holding a transaction-scoped
+// advisory lock, and deletes them in the same transaction. If another
+// instance holds the lock, nothing is dispatched this round.
+func (s *Postgres) Dispatch(batch int, deliver func(Event) error) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	sent := 0
+	var deliverErr error
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var locked bool
+		if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", postgresOutboxLock).Scan(&locked); err != nil || !locked {
+			return err
+		}
+		rows, err := tx.Query(ctx, "SELECT cursor, type, record_id, record, at FROM outbox ORDER BY cursor LIMIT $1", batch)
+		if err != nil {
+			return err
+		}
+		pending := []Event{}
+		for rows.Next() {
+			var e Event
+			var record []byte
+			var at time.Time
+			if err := rows.Scan(&e.Cursor, &e.Type, &e.ID, &record, &at); err != nil {
+				rows.Close()
+				return err
+			}
+			if record != nil {
+				e.Record = &StoredString{}
+				if err := json.Unmarshal(record, e.Record); err != nil {
+					rows.Close()
+					return err
+				}
+			}
+			e.At = NewTimestamp(at)
+			pending = append(pending, e)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		var last int64
+		for _, e := range pending {
+			if deliverErr = deliver(e); deliverErr != nil {
+				break
+			}
+			last = e.Cursor
+			sent++
+		}
+		if sent == 0 {
+			return nil
+		}
+		_, err = tx.Exec(ctx, "DELETE FROM outbox WHERE cursor <= $1", last)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return sent, deliverErr
+}
+
+// PurgeOutbox drops undelivered outbox rows carrying the contents of ids.
+func (s *Postgres) PurgeOutbox(ids map[string]bool) error {
+	list := make([]string, 0, len(ids))
+	for id := range ids {
+		list = append(list, id)
+	}
+	ctx, cancel := operationContext(s.ctx, postgresTimeout)
+	defer cancel()
+	_, err := s.pool.Exec(ctx, "DELETE FROM outbox WHERE record_id = ANY($1) AND record IS NOT NULL", list)
+	return err
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+)
+
+// DefaultTenant owns the records stored without a tenant.
+const DefaultTenant = "default"
+
+// StoredString is one analyzed value with the metadata kept alongside it.
+type StoredString struct {
+	ID         string              `json:"id"`
+	Value      string              `json:"value"`
+	Properties analysis.Properties `json:"properties"`
+	CreatedAt  Timestamp           `json:"created_at"`
+	UpdatedAt  *Timestamp          `json:"updated_at,omitempty"`
+	DeletedAt  *Timestamp          `json:"deleted_at,omitempty"`
+	ExpiresAt  *Timestamp          `json:"expires_at,omitempty"`
+	Version    int64               `json:"version"`
+	Collection string              `json:"collection,omitempty"`
+	Tags       []string            `json:"tags,omitempty"`
+	Tenant     string              `json:"tenant,omitempty"`
+	Enrichment *Enrichment         `json:"enrichment,omitempty"`
+}
+
+// Expired reports whether the record's expires_at has passed by now.
+func (item StoredString) Expired(now time.Time) bool {
+	return item.ExpiresAt != nil && !now.Before(item.ExpiresAt.Time)
+}
+
+// HasTag reports whether the record carries tag. Tags are kept sorted.
+func (item StoredString) HasTag(tag string) bool {
+	_, found := slices.BinarySearch(item.Tags, tag)
+	return found
+}
+
+// Enrichment records the external enrichment of a record: its status, how
+// many deliveries were tried, and the extra properties the service returned.
+type Enrichment struct {
+	Status     string                     `json:"status"`
+	Attempts   int                        `json:"attempts"`
+	LastError  string                     `json:"last_error,omitempty"`
+	Properties map[string]json.RawMessage `json:"properties,omitempty"`
+	UpdatedAt  Timestamp                  `json:"updated_at"`
+}
+
+// Event is one change to the store. Cursors increase by one per event, so a
+// consumer resumes by passing the last cursor it processed as since.
+type Event struct {
+	Cursor int64         `json:"cursor"`
+	Type   string        `json:"type"`
+	ID     string        `json:"id,omitempty"`
+	Tenant string        `json:"tenant,omitempty"`
+	Record *StoredString `json:"record,omitempty"`
+	At     Timestamp     `json:"at"`
+}
@@ -0,0 +1,261 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisTimeout   = 5 * time.Second
+	redisMGetChunk = 500
+	// DefaultRedisPrefix is the key prefix used unless REDIS_KEY_PREFIX is set.
+	DefaultRedisPrefix = "hng:strings:"
+)
+
+// Redis keeps each record as JSON under prefix+"record:"+id, the
+// set of IDs under prefix+"ids" and collection configurations in the hash
+// prefix+"collections". With a TTL, records expire on their own and
+// their IDs are pruned from the set the next time they are listed.
+type Redis struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+	ctx    context.Context
+}
+
+// WithContext returns a view of s whose operations are bounded by ctx.
+func (s *Redis) WithContext(ctx context.Context) Storage {
+	bound := *s
+	bound.ctx = ctx
+	return &bound
+}
+
+// RedisOptions configures OpenRedis. A zero PoolSize keeps the client's
+// default and a zero TTL keeps records until deleted.
+type RedisOptions struct {
+	URL      string
+	Prefix   string
+	PoolSize int
+	TTL      time.Duration
+}
+
+// OpenRedis connects to the server at opts.URL and checks it answers.
+func OpenRedis(o RedisOptions) (*Redis, error) {
+	opts, err := redis.ParseURL(o.URL)
+	if err != nil {
+		return nil, err
+	}
+	if o.PoolSize > 0 {
+		opts.PoolSize = o.PoolSize
+	}
+	// Honor the deadlines of redisTimeout and readiness probes rather than
+	// only the socket timeouts.
+	opts.ContextTimeoutEnabled = true
+	s := &Redis{client: redis.NewClient(opts), prefix: o.Prefix, ttl: o.TTL}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Redis) recordKey(id string) string { return s.prefix + "record:" + id }
+func (s *Redis) idsKey() string             { return s.prefix + "ids" }
+func (s *Redis) collectionsKey() string     { return s.prefix + "collections" }
+
+func decodeRecord(data string) (StoredString, error) {
+	var item StoredString
+	err := json.Unmarshal([]byte(data), &item)
+	return item, err
+}
+
+func (s *Redis) Get(id string) (StoredString, error) {
+	ctx, cancel := operationContext(s.ctx, redisTimeout)
+	defer cancel()
+	data, err := s.client.Get(ctx, s.recordKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return StoredString{}, ErrNotFound
+	}
+	if err != nil {
+		return StoredString{}, err
+	}
+	return decodeRecord(data)
+}
+
+func (s *Redis) Put(item StoredString) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := operationContext(s.ctx, redisTimeout)
+	defer cancel()
+	if MaxRecords > 0 {
+		n, err := s.client.SCard(ctx, s.idsKey()).Result()
+		if err != nil {
+			return err
+		}
+		if n >= int64(MaxRecords) {
+			return ErrFull
+		}
+	}
+	ok, err := s.client.SetNX(ctx, s.recordKey(item.ID), data, s.ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrExists
+	}
+	return s.client.SAdd(ctx, s.idsKey(), item.ID).Err()
+}
+
+func (s *Redis) Update(item StoredString) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := operationContext(s.ctx, redisTimeout)
+	defer cancel()
+	res, err := s.client.SetArgs(ctx, s.recordKey(item.ID), data, redis.SetArgs{Mode: "XX", KeepTTL: true}).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if res != "OK" {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Redis) Delete(id string) (StoredString, error) {
+	ctx, cancel := operationContext(s.ctx, redisTimeout)
+	defer cancel()
+	data, err := s.client.GetDel(ctx, s.recordKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return StoredString{}, ErrNotFound
+	}
+	if err != nil {
+		return StoredString{}, err
+	}
+	if err := s.client.SRem(ctx, s.idsKey(), id).Err(); err != nil {
+		return StoredString{}, err
+	}
+	return decodeRecord(data)
+}
+
+func (s *Redis) List() ([]StoredString, error) {
+	ctx, cancel := operationContext(s.ctx, redisTimeout)
+	defer cancel()
+	ids, err := s.client.SMembers(ctx, s.idsKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]StoredString, 0, len(ids))
+	var expired []interface{}
+	for start := 0; start < len(ids); start += redisMGetChunk {
+		end := start + redisMGetChunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		keys := make([]string, 0, end-start)
+		for _, id := range ids[start:end] {
+			keys = append(keys, s.recordKey(id))
+		}
+		vals, err := s.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range vals {
+			data, ok := v.(string)
+			if !ok {
+				expired = append(expired, ids[start+i])
+				continue
+			}
+			item, err := decodeRecord(data)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, item)
+		}
+	}
+	if len(expired) > 0 {
+		_ = s.client.SRem(ctx, s.idsKey(), expired...).Err()
+	}
+	return out, nil
+}
+
+func (s *Redis) Filter(match func(StoredString) bool) ([]StoredString, error) {
+	items, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	out := items[:0]
+	for _, item := range items {
+		if match(item) {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (s *Redis) Reset() error {
+	ctx, cancel := operationContext(s.ctx, redisTimeout)
+	defer cancel()
+	ids, err := s.client.SMembers(ctx, s.idsKey()).Result()
+	if err != nil {
+		return err
+	}
+	keys := []string{s.idsKey()}
+	for _, id := range ids {
+		keys = append(keys, s.recordKey(id))
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func (s *Redis) PutCollection(c Collection) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := operationContext(s.ctx, redisTimeout)
+	defer cancel()
+	return s.client.HSet(ctx, s.collectionsKey(), c.Tenant+"/"+c.Name, data).Err()
+}
+
+func (s *Redis) DeleteCollection(tenant, name string) error {
+	ctx, cancel := operationContext(s.ctx, redisTimeout)
+	defer cancel()
+	return s.client.HDel(ctx, s.collectionsKey(), tenant+"/"+name).Err()
+}
+
+func (s *Redis) Collections() ([]Collection, error) {
+	ctx, cancel := operationContext(s.ctx, redisTimeout)
+	defer cancel()
+	all, err := s.client.HVals(ctx, s.collectionsKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Collection, 0, len(all))
+	for _, data := range all {
+		var c Collection
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return sortCollections(out), nil
+}
+
+func (s *Redis) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+func (s *Redis) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,197 @@
+// Package store holds the records behind the API and the backends that
+// persist them: an in-memory map, optionally snapshotted and logged to disk,
+// a bbolt file, Redis or PostgreSQL.
+package store
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("string does not exist in the system")
+	ErrExists   = errors.New("string already exists in the system")
+	ErrFull     = errors.New("store is full")
+)
+
+// MaxRecords, when positive, caps how many records a backend accepts; Put
+// fails with ErrFull beyond it.
+var MaxRecords int
+
+// Logger receives the warnings of the backends and of ReadLogLines.
+var Logger = slog.Default()
+
+// Storage is the persistence layer behind the handlers. Put only inserts new
+// records and fails with ErrExists or ErrFull; Update replaces an existing
+// record. Get, Update and Delete fail with ErrNotFound. Implementations must
+// be safe for concurrent use.
+type Storage interface {
+	Get(id string) (StoredString, error)
+	Put(item StoredString) error
+	Update(item StoredString) error
+	Delete(id string) (StoredString, error)
+	List() ([]StoredString, error)
+	Filter(match func(StoredString) bool) ([]StoredString, error)
+	Reset() error
+}
+
+// ContextStorage is a backend that can bind its operations to a context,
+// so calls made for a request give up once it times out or its client goes
+// away.
+type ContextStorage interface {
+	WithContext(ctx context.Context) Storage
+}
+
+// operationContext bounds a backend operation by timeout and by ctx, the
+// context the backend was bound to, if any.
+func operationContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Cond is one listing filter: the name and parsed value of the query
+// parameter, which backends that understand it translate, and Match, which
+// evaluates it against a record.
+type Cond struct {
+	Name  string
+	Value interface{}
+	Match func(StoredString) bool
+}
+
+// Query is a listing query: every And condition must hold, and at least one
+// condition of each Or group. Tenant, when set, limits it to that tenant's
+// records.
+type Query struct {
+	And    []Cond
+	Or     [][]Cond
+	Tenant string
+}
+
+// Matches reports whether item satisfies the conditions of q. The tenant is
+// left to the caller.
+func (q Query) Matches(item StoredString) bool {
+	for _, c := range q.And {
+		if !c.Match(item) {
+			return false
+		}
+	}
+	for _, group := range q.Or {
+		ok := false
+		for _, c := range group {
+			if c.Match(item) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Pushdown is implemented by backends that can evaluate listing queries
+// themselves instead of scanning every record.
+type Pushdown interface {
+	FilterQuery(q Query) ([]StoredString, error)
+}
+
+// Memory keeps records and collection configurations in maps. It is the
+// default backend.
+type Memory struct {
+	sync.RWMutex
+	m           map[string]StoredString
+	collections map[collectionKey]Collection
+}
+
+// NewMemory returns an empty in-memory backend.
+func NewMemory() *Memory {
+	return &Memory{m: map[string]StoredString{}, collections: map[collectionKey]Collection{}}
+}
+
+func (s *Memory) Get(id string) (StoredString, error) {
+	s.RLock()
+	defer s.RUnlock()
+	item, ok := s.m[id]
+	if !ok {
+		return StoredString{}, ErrNotFound
+	}
+	return item, nil
+}
+
+func (s *Memory) Put(item StoredString) error {
+	s.Lock()
+	defer s.Unlock()
+	if _, exists := s.m[item.ID]; exists {
+		return ErrExists
+	}
+	if MaxRecords > 0 && len(s.m) >= MaxRecords {
+		return ErrFull
+	}
+	s.m[item.ID] = item
+	return nil
+}
+
+func (s *Memory) Update(item StoredString) error {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.m[item.ID]; !ok {
+		return ErrNotFound
+	}
+	s.m[item.ID] = item
+	return nil
+}
+
+func (s *Memory) Delete(id string) (StoredString, error) {
+	s.Lock()
+	defer s.Unlock()
+	item, ok := s.m[id]
+	if !ok {
+		return StoredString{}, ErrNotFound
+	}
+	delete(s.m, id)
+	return item, nil
+}
+
+func (s *Memory) List() ([]StoredString, error) {
+	return s.Filter(func(StoredString) bool { return true })
+}
+
+func (s *Memory) Filter(match func(StoredString) bool) ([]StoredString, error) {
+	s.RLock()
+	defer s.RUnlock()
+	out := make([]StoredString, 0, len(s.m))
+	for _, item := range s.m {
+		if match(item) {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (s *Memory) Reset() error {
+	s.Lock()
+	s.m = map[string]StoredString{}
+	s.Unlock()
+	return nil
+}
+
+// SyncLog flushes an append-only log to disk and closes it; later appends
+// only reach memory.
+func SyncLog(f **os.File) error {
+	if *f == nil {
+		return nil
+	}
+	err := (*f).Sync()
+	if cerr := (*f).Close(); err == nil {
+		err = cerr
+	}
+	*f = nil
+	return err
+}
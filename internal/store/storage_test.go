@@ -0,0 +1,252 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/samueltuoyo15/HNG-Stage-1/internal/analysis"
+)
+
+// backends open an empty instance of each backend. The ones that need a
+// server are skipped unless its TEST_ variable names one the tests may wipe.
+var backends = []struct {
+	name string
+	open func(t *testing.T) Storage
+}{
+	{"memory", func(t *testing.T) Storage { return NewMemory() }},
+	{"wal", func(t *testing.T) Storage {
+		wal, err := openWAL(filepath.Join(t.TempDir(), "store.wal"), NewMemory())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wal
+	}},
+	{"bolt", func(t *testing.T) Storage {
+		b, err := OpenBolt(filepath.Join(t.TempDir(), "strings.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}},
+	{"postgres", func(t *testing.T) Storage {
+		url := os.Getenv("TEST_DATABASE_URL")
+		if url == "" {
+			t.Skip("TEST_DATABASE_URL is not set")
+		}
+		s, err := OpenPostgres(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Reset(); err != nil {
+			s.Close()
+			t.Fatal(err)
+		}
+		return s
+	}},
+	{"redis", func(t *testing.T) Storage {
+		url := os.Getenv("TEST_REDIS_URL")
+		if url == "" {
+			t.Skip("TEST_REDIS_URL is not set")
+		}
+		s, err := OpenRedis(RedisOptions{URL: url, Prefix: "hng:test:"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Reset(); err != nil {
+			s.Close()
+			t.Fatal(err)
+		}
+		return s
+	}},
+}
+
+func testRecord(val string) StoredString {
+	return StoredString{ID: analysis.Hash(val), Value: val, Properties: analysis.Analyze(val, analysis.DefaultOptions()), CreatedAt: Now(), Version: 1}
+}
+
+// setMaxRecords caps the backends at n records for the test.
+func setMaxRecords(t *testing.T, n int) {
+	prev := MaxRecords
+	MaxRecords = n
+	t.Cleanup(func() { MaxRecords = prev })
+}
+
+// values returns the values of items, sorted.
+func values(items []StoredString) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, item.Value)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// TestStorageConformance holds every backend to the Storage contract.
+func TestStorageConformance(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			open := func(t *testing.T) Storage {
+				s := b.open(t)
+				if c, ok := s.(io.Closer); ok {
+					t.Cleanup(func() { c.Close() })
+				}
+				return s
+			}
+			t.Run("put and get", func(t *testing.T) {
+				s := open(t)
+				item := testRecord("racecar")
+				if err := s.Put(item); err != nil {
+					t.Fatal(err)
+				}
+				got, err := s.Get(item.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Value != item.Value || got.Properties.IsPalindrome != item.Properties.IsPalindrome || got.Version != 1 {
+					t.Fatalf("got %+v, want %+v", got, item)
+				}
+			})
+			t.Run("put existing", func(t *testing.T) {
+				s := open(t)
+				item := testRecord("racecar")
+				if err := s.Put(item); err != nil {
+					t.Fatal(err)
+				}
+				changed := item
+				changed.Version = 7
+				if err := s.Put(changed); !errors.Is(err, ErrExists) {
+					t.Fatalf("second put: %v, want ErrExists", err)
+				}
+				if got, _ := s.Get(item.ID); got.Version != 1 {
+					t.Fatalf("rejected put replaced the record: version %d", got.Version)
+				}
+			})
+			t.Run("put beyond MaxRecords", func(t *testing.T) {
+				setMaxRecords(t, 2)
+				s := open(t)
+				for _, v := range []string{"a", "b"} {
+					if err := s.Put(testRecord(v)); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := s.Put(testRecord("c")); !errors.Is(err, ErrFull) {
+					t.Fatalf("put beyond the cap: %v, want ErrFull", err)
+				}
+				if err := s.Put(testRecord("a")); !errors.Is(err, ErrExists) {
+					t.Fatalf("put existing at the cap: %v, want ErrExists", err)
+				}
+				if _, err := s.Get(testRecord("c").ID); !errors.Is(err, ErrNotFound) {
+					t.Fatalf("rejected record stored: %v", err)
+				}
+				if _, err := s.Delete(testRecord("a").ID); err != nil {
+					t.Fatal(err)
+				}
+				if err := s.Put(testRecord("c")); err != nil {
+					t.Fatalf("put after freeing room: %v", err)
+				}
+			})
+			t.Run("update", func(t *testing.T) {
+				s := open(t)
+				item := testRecord("level")
+				if err := s.Update(item); !errors.Is(err, ErrNotFound) {
+					t.Fatalf("update of a missing record: %v, want ErrNotFound", err)
+				}
+				if err := s.Put(item); err != nil {
+					t.Fatal(err)
+				}
+				item.Version, item.Tags = 2, []string{"x"}
+				if err := s.Update(item); err != nil {
+					t.Fatal(err)
+				}
+				if got, _ := s.Get(item.ID); got.Version != 2 || !got.HasTag("x") {
+					t.Fatalf("update not applied: %+v", got)
+				}
+			})
+			t.Run("delete", func(t *testing.T) {
+				s := open(t)
+				item := testRecord("noon")
+				if _, err := s.Delete(item.ID); !errors.Is(err, ErrNotFound) {
+					t.Fatalf("delete of a missing record: %v, want ErrNotFound", err)
+				}
+				if err := s.Put(item); err != nil {
+					t.Fatal(err)
+				}
+				deleted, err := s.Delete(item.ID)
+				if err != nil || deleted.Value != item.Value {
+					t.Fatalf("delete returned %+v, %v", deleted, err)
+				}
+				if _, err := s.Get(item.ID); !errors.Is(err, ErrNotFound) {
+					t.Fatalf("get after delete: %v, want ErrNotFound", err)
+				}
+			})
+			t.Run("list, filter and reset", func(t *testing.T) {
+				s := open(t)
+				for _, v := range []string{"abba", "hello", "kayak"} {
+					if err := s.Put(testRecord(v)); err != nil {
+						t.Fatal(err)
+					}
+				}
+				all, err := s.List()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := fmt.Sprint(values(all)); got != "[abba hello kayak]" {
+					t.Fatalf("list = %s", got)
+				}
+				palindromes, err := s.Filter(func(item StoredString) bool { return item.Properties.IsPalindrome })
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := fmt.Sprint(values(palindromes)); got != "[abba kayak]" {
+					t.Fatalf("filter = %s", got)
+				}
+				if err := s.Reset(); err != nil {
+					t.Fatal(err)
+				}
+				if all, _ := s.List(); len(all) != 0 {
+					t.Fatalf("%d records left after reset", len(all))
+				}
+			})
+			t.Run("collections", func(t *testing.T) {
+				s := open(t)
+				cs, ok := s.(CollectionStore)
+				if !ok {
+					t.Fatal("backend keeps no collection configurations")
+				}
+				folded := analysis.DefaultOptions()
+				folded.CaseInsensitive = true
+				for _, c := range []Collection{
+					{Tenant: "default", Name: "b", Config: analysis.DefaultOptions()},
+					{Tenant: "default", Name: "a", Config: analysis.DefaultOptions()},
+					{Tenant: "acme", Name: "a", Config: analysis.DefaultOptions()},
+					{Tenant: "default", Name: "b", Config: folded},
+				} {
+					if err := cs.PutCollection(c); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := cs.DeleteCollection("default", "a"); err != nil {
+					t.Fatal(err)
+				}
+				if err := cs.DeleteCollection("default", "missing"); err != nil {
+					t.Fatalf("delete of a missing collection: %v", err)
+				}
+				if err := s.Reset(); err != nil {
+					t.Fatal(err)
+				}
+				got, err := cs.Collections()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) != 2 || got[0].Tenant != "acme" || got[1].Name != "b" || !got[1].Config.CaseInsensitive {
+					t.Fatalf("collections = %+v", got)
+				}
+			})
+		})
+	}
+}
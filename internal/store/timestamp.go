@@ -0,0 +1,57 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+)
+
+var outputLocation = time.UTC
+
+// Timestamp marshals as RFC 3339 with nanosecond precision in the configured
+// output timezone.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp wraps t.
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{t}
+}
+
+// Now is the current time as a Timestamp.
+func Now() Timestamp {
+	return Timestamp{time.Now()}
+}
+
+// FormatTime renders t as a Timestamp marshals it, without the quotes.
+func FormatTime(t time.Time) string {
+	return t.In(outputLocation).Format(time.RFC3339Nano)
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(FormatTime(t.Time))
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// SetOutputTimezone renders every Timestamp in the named IANA timezone
+// instead of UTC.
+func SetOutputTimezone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	outputLocation = loc
+	return nil
+}
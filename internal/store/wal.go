@@ -0,0 +1,303 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// walEntry is one line of the write-ahead log. Collection entries set or,
+// with op "drop-collection", remove a collection configuration.
+type walEntry struct {
+	Op         string        `json:"op"`
+	ID         string        `json:"id,omitempty"`
+	Record     *StoredString `json:"record,omitempty"`
+	Collection *Collection   `json:"collection,omitempty"`
+}
+
+// walStorage appends every mutation of the in-memory store to an append-only
+// log of JSON lines, so writes made since the last store snapshot survive a
+// restart. The log is truncated each time a snapshot is saved.
+type walStorage struct {
+	*Memory
+	mu   sync.Mutex
+	path string
+	f    *os.File // nil once closed
+}
+
+// errLogClosed is returned for writes that arrive after Close.
+var errLogClosed = errors.New("write-ahead log is closed")
+
+// ReadLogLines calls apply for every line of the JSON-lines log in f. A torn
+// final line, left by a crash mid-append, is dropped and truncated away.
+func ReadLogLines(f *os.File, apply func(n int, line []byte) error) error {
+	r := bufio.NewReader(f)
+	var offset int64
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				Logger.Warn("dropping incomplete log entry", "file", f.Name(), "line", n)
+				return f.Truncate(offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		offset += int64(len(line))
+		if err := apply(n, line); err != nil {
+			return fmt.Errorf("%s: entry %d: %w", f.Name(), n, err)
+		}
+	}
+}
+
+// replayWAL applies the log at path to mem.
+func replayWAL(path string, mem *Memory) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return ReadLogLines(f, func(n int, line []byte) error {
+		var e walEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return err
+		}
+		switch e.Op {
+		case "create", "update":
+			if e.Record == nil {
+				return errors.New("missing record")
+			}
+			mem.m[e.Record.ID] = *e.Record
+		case "delete":
+			delete(mem.m, e.ID)
+		case "reset":
+			mem.m = map[string]StoredString{}
+		case "collection", "drop-collection":
+			if e.Collection == nil {
+				return errors.New("missing collection")
+			}
+			key := collectionKey{e.Collection.Tenant, e.Collection.Name}
+			if e.Op == "collection" {
+				mem.collections[key] = *e.Collection
+			} else {
+				delete(mem.collections, key)
+			}
+		default:
+			return fmt.Errorf("unknown op %q", e.Op)
+		}
+		return nil
+	})
+}
+
+func openWAL(path string, mem *Memory) (*walStorage, error) {
+	if err := replayWAL(path, mem); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &walStorage{Memory: mem, path: path, f: f}, nil
+}
+
+func (s *walStorage) append(e walEntry) error {
+	if s.f == nil {
+		return errLogClosed
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = s.f.Write(append(line, '\n'))
+	return err
+}
+
+// Each mutation is applied to memory first, so rejected writes are never
+// logged, and undone if the log append fails.
+
+func (s *walStorage) Put(item StoredString) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.Memory.Put(item); err != nil {
+		return err
+	}
+	if err := s.append(walEntry{Op: "create", Record: &item}); err != nil {
+		_, _ = s.Memory.Delete(item.ID)
+		return err
+	}
+	return nil
+}
+
+func (s *walStorage) Update(item StoredString) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, err := s.Memory.Get(item.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.Memory.Update(item); err != nil {
+		return err
+	}
+	if err := s.append(walEntry{Op: "update", Record: &item}); err != nil {
+		_ = s.Memory.Update(prev)
+		return err
+	}
+	return nil
+}
+
+func (s *walStorage) Delete(id string) (StoredString, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, err := s.Memory.Delete(id)
+	if err != nil {
+		return StoredString{}, err
+	}
+	if err := s.append(walEntry{Op: "delete", ID: id}); err != nil {
+		s.Memory.Lock()
+		s.Memory.m[id] = item
+		s.Memory.Unlock()
+		return StoredString{}, err
+	}
+	return item, nil
+}
+
+func (s *walStorage) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.append(walEntry{Op: "reset"}); err != nil {
+		return err
+	}
+	return s.Memory.Reset()
+}
+
+func (s *walStorage) PutCollection(c Collection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.append(walEntry{Op: "collection", Collection: &c}); err != nil {
+		return err
+	}
+	return s.Memory.PutCollection(c)
+}
+
+func (s *walStorage) DeleteCollection(tenant, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.append(walEntry{Op: "drop-collection", Collection: &Collection{Tenant: tenant, Name: name}}); err != nil {
+		return err
+	}
+	return s.Memory.DeleteCollection(tenant, name)
+}
+
+// compact empties the log. The caller must hold s.mu and have just saved a
+// snapshot containing every logged write.
+func (s *walStorage) compact() error {
+	if s.f == nil {
+		return errLogClosed
+	}
+	return s.f.Truncate(0)
+}
+
+// Close flushes the log to disk on shutdown.
+func (s *walStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SyncLog(&s.f)
+}
+
+// rewrite replaces the log with one create entry per current record and a
+// collection entry per configuration, for when deleted contents must not
+// linger in it and no snapshot is kept.
+func (s *walStorage) rewrite() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return errLogClosed
+	}
+	records, _ := s.Memory.List()
+	path := s.path
+	tmp := path + ".tmp"
+	var buf []byte
+	for i := range records {
+		line, err := json.Marshal(walEntry{Op: "create", Record: &records[i]})
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	collections, _ := s.Memory.Collections()
+	for i := range collections {
+		line, err := json.Marshal(walEntry{Op: "collection", Collection: &collections[i]})
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	if err := os.WriteFile(tmp, buf, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	s.f.Close()
+	s.f = f
+	return nil
+}
+
+// stats counts the entries of the log, how many are delete or reset
+// tombstones, and how many create or update entries a later write has
+// superseded.
+func (s *walStorage) stats() (entries, tombstones, stale int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(s.path)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer f.Close()
+	live := map[string]bool{}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return entries, tombstones, stale, nil
+		}
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		var e walEntry
+		if json.Unmarshal(line, &e) != nil {
+			continue
+		}
+		entries++
+		switch e.Op {
+		case "create", "update":
+			if e.Record != nil {
+				if live[e.Record.ID] {
+					stale++
+				}
+				live[e.Record.ID] = true
+			}
+		case "delete":
+			tombstones++
+			if live[e.ID] {
+				stale++
+			}
+			delete(live, e.ID)
+		case "reset":
+			tombstones++
+			stale += len(live)
+			live = map[string]bool{}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// reopenWAL closes wal and replays its log into a fresh memory store.
+func reopenWAL(t *testing.T, wal *walStorage) *walStorage {
+	t.Helper()
+	if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	again, err := openWAL(wal.path, NewMemory())
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	t.Cleanup(func() { again.Close() })
+	return again
+}
+
+func newTestWAL(t *testing.T) *walStorage {
+	t.Helper()
+	wal, err := openWAL(filepath.Join(t.TempDir(), "store.wal"), NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wal
+}
+
+func TestWALReplay(t *testing.T) {
+	wal := newTestWAL(t)
+	for _, v := range []string{"abba", "hello", "kayak", "noon"} {
+		if err := wal.Put(testRecord(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updated := testRecord("hello")
+	updated.Version = 2
+	if err := wal.Update(updated); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wal.Delete(testRecord("kayak").ID); err != nil {
+		t.Fatal(err)
+	}
+	replayed := reopenWAL(t, wal)
+	all, _ := replayed.List()
+	if got := fmt.Sprint(values(all)); got != "[abba hello noon]" {
+		t.Fatalf("replayed records = %s", got)
+	}
+	if got, _ := replayed.Get(updated.ID); got.Version != 2 {
+		t.Fatalf("replayed update: version %d, want 2", got.Version)
+	}
+	if err := replayed.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if err := replayed.Put(testRecord("racecar")); err != nil {
+		t.Fatal(err)
+	}
+	all, _ = reopenWAL(t, replayed).List()
+	if got := fmt.Sprint(values(all)); got != "[racecar]" {
+		t.Fatalf("records replayed after a reset = %s", got)
+	}
+}
+
+// TestWALReplayTornLine replays a log whose last append was cut short by a
+// crash: the torn line is dropped and truncated away, and appends resume
+// after the last complete entry.
+func TestWALReplayTornLine(t *testing.T) {
+	wal := newTestWAL(t)
+	for _, v := range []string{"abba", "hello"} {
+		if err := wal.Put(testRecord(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	complete, err := os.ReadFile(wal.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	torn := `{"op":"create","record":{"id":"`
+	if err := os.WriteFile(wal.path, append(complete, torn...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := openWAL(wal.path, NewMemory())
+	if err != nil {
+		t.Fatalf("replay of a torn log: %v", err)
+	}
+	all, _ := replayed.List()
+	if got := fmt.Sprint(values(all)); got != "[abba hello]" {
+		t.Fatalf("replayed records = %s", got)
+	}
+	data, _ := os.ReadFile(wal.path)
+	if string(data) != string(complete) {
+		t.Fatalf("torn line not truncated away:\n%s", data)
+	}
+	if err := replayed.Put(testRecord("kayak")); err != nil {
+		t.Fatal(err)
+	}
+	all, _ = reopenWAL(t, replayed).List()
+	if got := fmt.Sprint(values(all)); got != "[abba hello kayak]" {
+		t.Fatalf("records after appending to a repaired log = %s", got)
+	}
+}
+
+func TestWALReplayCorruptEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.wal")
+	lines := "{\"op\":\"reset\"}\nnot json\n{\"op\":\"reset\"}\n"
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := openWAL(path, NewMemory())
+	if err == nil || !strings.Contains(err.Error(), "entry 2") {
+		t.Fatalf("replay of a corrupt entry: %v, want an error naming entry 2", err)
+	}
+}
+
+// TestWALClosed checks that writes after Close fail without changing the
+// store, and that the log can still be inspected.
+func TestWALClosed(t *testing.T) {
+	wal := newTestWAL(t)
+	item := testRecord("abba")
+	if err := wal.Put(item); err != nil {
+		t.Fatal(err)
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wal.Put(testRecord("hello")); !errors.Is(err, errLogClosed) {
+		t.Fatalf("put after close: %v, want errLogClosed", err)
+	}
+	if _, err := wal.Get(testRecord("hello").ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("rejected put kept in memory: %v", err)
+	}
+	if _, err := wal.Delete(item.ID); !errors.Is(err, errLogClosed) {
+		t.Fatalf("delete after close: %v, want errLogClosed", err)
+	}
+	if _, err := wal.Get(item.ID); err != nil {
+		t.Fatalf("rejected delete removed the record: %v", err)
+	}
+	if err := wal.rewrite(); !errors.Is(err, errLogClosed) {
+		t.Fatalf("rewrite after close: %v, want errLogClosed", err)
+	}
+	if entries, _, _, err := wal.stats(); err != nil || entries != 1 {
+		t.Fatalf("stats after close = %d entries, %v", entries, err)
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+}
+
+func TestWALReplayCollections(t *testing.T) {
+	wal := newTestWAL(t)
+	for _, name := range []string{"a", "b"} {
+		if err := wal.PutCollection(Collection{Tenant: "acme", Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wal.DeleteCollection("acme", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wal.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	replayed := reopenWAL(t, wal)
+	if got, _ := replayed.Collections(); len(got) != 1 || got[0].Name != "b" {
+		t.Fatalf("replayed collections = %+v", got)
+	}
+	if err := replayed.rewrite(); err != nil {
+		t.Fatal(err)
+	}
+	rewritten := reopenWAL(t, replayed)
+	if got, _ := rewritten.Collections(); len(got) != 1 || got[0].Name != "b" {
+		t.Fatalf("collections after rewrite = %+v", got)
+	}
+}
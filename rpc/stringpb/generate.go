@@ -0,0 +1,5 @@
+// Package stringpb is the protobuf and gRPC definition of StringService,
+// generated from strings.proto.
+package stringpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative strings.proto